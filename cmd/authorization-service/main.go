@@ -1,21 +1,67 @@
 package main
 
 import (
-	"authorization-service/internal/app"
-	"authorization-service/internal/config"
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/app"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/config"
 )
 
+// shutdownTimeout bounds graceful shutdown after SIGINT or SIGTERM.
+const shutdownTimeout = 30 * time.Second
+
 func main() {
-	// 1. Init cfg
+	// 1. Run "migrate" subcommand instead of the server when asked.
+	// It needs only the database config.
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		cfg := config.MustLoadMigrate()
+		logger := setupLogger(cfg.Env)
+		if logger == nil {
+			logger = slog.Default()
+		}
+		if err := runMigrate(logger, cfg.Database, os.Args[2:]); err != nil {
+			logger.Error("migrate failed", slog.Any("err", err))
+			os.Exit(1)
+		}
+		return
+	}
+
+	// 2. Init cfg
 	cfg := config.MustLoad()
 
-	// 2. Init logger
+	// 3. Init logger
 	logger := setupLogger(cfg.Env)
+	if logger == nil {
+		logger = slog.Default()
+	}
 
-	// 3. Build application: wire config, logger, gRPC app, services, etc.
+	// 4. Build application: wire config, logger, gRPC app, services, etc.
 	application := app.New(logger, cfg)
 
-	// 4. Run gRPC server (panic if cant start).
-	application.GRPC.MustRun()
+	// 5. Run HTTP server (JWKS) in background when enabled.
+	if application.HTTP != nil {
+		go application.HTTP.MustRun()
+	}
+
+	// 6. Run gRPC server in background (panic if cant start).
+	go application.GRPC.MustRun()
+
+	// 7. Wait for a termination signal and shut down gracefully:
+	// in-flight requests finish and queued mail is delivered.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+
+	logger.Info("shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	application.Stop(shutdownCtx)
 
+	logger.Info("application stopped")
 }
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/config"
+	pgstorage "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/storage/postgres"
+)
+
+const migrateUsage = "usage: authorization-service migrate up|down|status|redo"
+
+// runMigrate runs a migrate subcommand against the configured database.
+func runMigrate(log *slog.Logger, cfg config.DatabaseConfig, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("%s", migrateUsage)
+	}
+
+	ctx := context.Background()
+	pool, err := pgstorage.New(ctx, log, cfg)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	migrator, err := pgstorage.NewMigrator(log, pool)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	switch args[0] {
+	case "up":
+		return migrator.Up(ctx)
+	case "down":
+		return migrator.Down(ctx)
+	case "redo":
+		return migrator.Redo(ctx)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		printMigrationStatus(statuses)
+		return nil
+	default:
+		return fmt.Errorf("unknown migrate command %q; %s", args[0], migrateUsage)
+	}
+}
+
+func printMigrationStatus(statuses []pgstorage.MigrationStatus) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tAPPLIED AT\tMIGRATION")
+
+	for _, s := range statuses {
+		appliedAt := "pending"
+		if s.Applied {
+			appliedAt = s.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", s.Version, appliedAt, s.Name)
+	}
+
+	_ = w.Flush()
+}
@@ -1,7 +1,7 @@
 package main
 
 import (
-	"authorization-service/internal/lib/logger/handlers/slogpretty"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/lib/logger/handlers/slogpretty"
 	"log/slog"
 	"os"
 )
@@ -1,18 +1,37 @@
 package app
 
 import (
-	pgstorage "authorization-service/internal/storage/postgres"
 	"context"
 	"log/slog"
 
-	grpcapp "authorization-service/internal/app/grpc"
-	"authorization-service/internal/config"
+	"github.com/jackc/pgx/v5/pgxpool"
+	goredis "github.com/redis/go-redis/v9"
+
+	grpcapp "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/app/grpc"
+	httpapp "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/app/http"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/config"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/lib/secretbox"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/mailer"
+	outboxrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/outbox"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/service/keys"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/service/outbox"
+	pgstorage "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/storage/postgres"
+	redisstorage "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/storage/redis"
 )
 
 // App is a top-level application container.
 // It wires configuration, logger and sub-apps
 type App struct {
 	GRPC *grpcapp.App
+	// HTTP is nil when the HTTP server is disabled in config.
+	HTTP *httpapp.App
+
+	log  *slog.Logger
+	mail *mailer.Mailer
+	pg   *pgxpool.Pool
+	rdb  *goredis.Client
+	// stopBackground cancels the key rotation and outbox relay loops.
+	stopBackground context.CancelFunc
 }
 
 // New builds the whole application graph.
@@ -26,12 +45,104 @@ func New(log *slog.Logger, cfg *config.Config) *App {
 	// Here we assume cfg.GRPC has Port field of type int.
 	grpcPort := cfg.GRPC.Port
 
-	ctx := context.Background()
+	ctx, stopBackground := context.WithCancel(context.Background())
 	pg := pgstorage.MustNew(ctx, log, cfg.Database)
+	// Apply pending migrations before anything reads the schema
+	if cfg.Database.AutoMigrate {
+		pgstorage.MustMigrate(ctx, log, pg)
+	}
+	rdb := redisstorage.MustNew(ctx, log, cfg.Redis)
+
+	mail := mailer.MustNew(log, cfg.Mail)
 
-	grpcApp := grpcapp.New(log, grpcPort, pg)
+	// Signing keys are shared by token issuer and JWKS endpoints;
+	// generated keys are encrypted at rest
+	var keySecrets *secretbox.Box
+	if len(cfg.Keys.Files) == 0 {
+		keySecrets = secretbox.MustNewFromBase64(cfg.Keys.EncryptionKey)
+	}
+	keyManager := keys.NewManager(log, pgstorage.NewSigningKeyRepository(log, pg, keySecrets), cfg.Keys, cfg.Token.AccessTTL)
+	keyManager.MustLoad(ctx)
+	go keyManager.Run(ctx)
+
+	// Relay user events written to the outbox by the service
+	if cfg.Outbox.Enabled {
+		relay := outbox.NewRelay(log,
+			pgstorage.NewTxManager(log, pg),
+			pgstorage.NewOutboxRepository(log, pg),
+			mustNewEventSink(log, rdb, cfg.Outbox),
+			cfg.Outbox,
+		)
+		go relay.Run(ctx)
+	}
+
+	grpcApp := grpcapp.New(log, grpcPort, pg, rdb, mail, keyManager, cfg)
+
+	var httpApp *httpapp.App
+	if cfg.HTTP.Enabled {
+		httpApp = httpapp.New(log, cfg.HTTP.Port, keyManager, cfg.Keys.RefreshInterval)
+	}
 
 	return &App{
-		GRPC: grpcApp,
+		GRPC:           grpcApp,
+		HTTP:           httpApp,
+		log:            log,
+		mail:           mail,
+		pg:             pg,
+		rdb:            rdb,
+		stopBackground: stopBackground,
+	}
+}
+
+// Stop shuts the application down: servers stop accepting requests,
+// queued mail is delivered and connections are closed.
+// ctx bounds the whole shutdown.
+func (a *App) Stop(ctx context.Context) {
+	// 1. Stop servers first, so no request queues new work
+	if a.HTTP != nil {
+		a.HTTP.Stop(ctx)
+	}
+	stopGRPC(ctx, a.GRPC)
+
+	// 2. Stop background loops
+	a.stopBackground()
+
+	// 3. Deliver queued mail; whatever is left when ctx expires is dropped
+	if err := a.mail.Close(ctx); err != nil {
+		a.log.Error("failed to deliver queued mail", slog.Any("err", err))
+	}
+
+	// 4. Close connections
+	if err := a.rdb.Close(); err != nil {
+		a.log.Error("failed to close redis client", slog.Any("err", err))
+	}
+	a.pg.Close()
+}
+
+// stopGRPC stops the gRPC server gracefully and forces it to stop
+// when in-flight calls outlive ctx.
+func stopGRPC(ctx context.Context, grpcApp *grpcapp.App) {
+	done := make(chan struct{})
+	go func() {
+		grpcApp.Stop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		grpcApp.ForceStop()
+		<-done
+	}
+}
+
+// mustNewEventSink creates the sink outbox events are published to.
+// Kafka or NATS sinks are added here as outbox.Sink implementations.
+func mustNewEventSink(log *slog.Logger, rdb *goredis.Client, cfg config.OutboxConfig) outboxrepo.Sink {
+	switch cfg.Sink {
+	case "redis-stream":
+		return redisstorage.NewEventStreamSink(log, rdb, cfg.RedisStream.Stream, cfg.RedisStream.MaxLen)
+	default:
+		panic("unknown outbox sink: " + cfg.Sink)
 	}
 }
@@ -1,18 +1,29 @@
 package grpc
 
 import (
-	grpcauthentication "authorization-service/internal/grpc/authentication"
-	serviceauthentication "authorization-service/internal/service/authentication"
 	"fmt"
 	"log/slog"
 	"net"
 
-	pgstorage "authorization-service/internal/storage/postgres"
-
 	authorizationservicev1 "github.com/GrishanyaaShustov/CloudStorage-Protos-Service/gen/go/authorization-service"
+	"github.com/go-webauthn/webauthn/webauthn"
 	"github.com/jackc/pgx/v5/pgxpool"
+	goredis "github.com/redis/go-redis/v9"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/config"
+	grpcauthentication "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/grpc/authentication"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/lib/clientip"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/lib/jwt"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/lib/password"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/lib/secretbox"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/mailer"
+	serviceauthentication "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/service/authentication"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/service/keys"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/social"
+	pgstorage "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/storage/postgres"
+	redisstorage "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/storage/redis"
 )
 
 // App holds gRPC server instance and its configuration.
@@ -24,17 +35,98 @@ type App struct {
 
 // New creates a new gRPC server app but does NOT start it.
 // The caller is responsible for running and stopping the server.
-func New(log *slog.Logger, gRPCPort int, pg *pgxpool.Pool) *App {
+func New(
+	log *slog.Logger,
+	gRPCPort int,
+	pg *pgxpool.Pool,
+	rdb *goredis.Client,
+	mail *mailer.Mailer,
+	keyManager *keys.Manager,
+	cfg *config.Config,
+) *App {
 	gRPCServer := grpc.NewServer()
 
 	// Enable reflection for grpcurl / Postman
 	reflection.Register(gRPCServer)
 
-	// Wire repository
+	// Wire repositories
 	userRepo := pgstorage.NewUserRepository(log, pg)
+	refreshTokenRepo := redisstorage.NewRefreshTokenRepository(log, rdb, cfg.Token.RefreshTTL)
+	verificationRepo := redisstorage.NewVerificationRepository(log, rdb)
+	ticketRepo := redisstorage.NewTicketRepository(log, rdb)
+	revocationRepo := redisstorage.NewRevocationRepository(log, rdb, cfg.Token.AccessTTL)
+	introspectionCache := redisstorage.NewIntrospectionCache(log, rdb)
+	mfaRepo := pgstorage.NewMFARepository(log, pg)
+	passkeyRepo := pgstorage.NewPasskeyRepository(log, pg)
+	rateLimitRepo := redisstorage.NewRateLimitRepository(log, rdb)
+	idempotencyRepo := redisstorage.NewIdempotencyRepository(log, rdb)
+	outboxRepo := pgstorage.NewOutboxRepository(log, pg)
+	txManager := pgstorage.NewTxManager(log, pg)
+
+	// MFA secrets are encrypted at rest
+	secrets := secretbox.MustNewFromBase64(cfg.MFA.EncryptionKey)
+
+	// Wire password policy and hasher
+	passwordPolicy := password.MustNewPolicy(cfg.Password)
+	passwordHasher := password.MustNewHasher(cfg.PasswordHash)
+
+	// Wire WebAuthn relying party
+	relyingParty, err := webauthn.New(&webauthn.Config{
+		RPID:          cfg.WebAuthn.RPID,
+		RPDisplayName: cfg.WebAuthn.RPDisplayName,
+		RPOrigins:     cfg.WebAuthn.RPOrigins,
+	})
+	if err != nil {
+		panic("failed to configure webauthn: " + err.Error())
+	}
+
+	// Wire social login providers
+	providers := social.New(cfg.Social)
+
+	// Forwarding metadata is only believed from trusted proxies
+	clientIPs := clientip.MustNewResolver(cfg.GRPC.TrustedProxies)
+
+	// Wire access token issuer
+	tokenIssuer := jwt.NewIssuer(cfg.Token, keyManager)
 
 	// Wire authentication service: business layer + transport layer.
-	authenticationService := serviceauthentication.NewAuthService(log, userRepo)
+	authenticationService := serviceauthentication.NewAuthService(
+		log,
+		serviceauthentication.Repositories{
+			Users:         userRepo,
+			RefreshTokens: refreshTokenRepo,
+			Verifications: verificationRepo,
+			Tickets:       ticketRepo,
+			Revocations:   revocationRepo,
+			Introspection: introspectionCache,
+			MFA:           mfaRepo,
+			Passkeys:      passkeyRepo,
+			RateLimits:    rateLimitRepo,
+			Idempotency:   idempotencyRepo,
+			Outbox:        outboxRepo,
+			Transactions:  txManager,
+		},
+		tokenIssuer,
+		keyManager,
+		secrets,
+		passwordPolicy,
+		passwordHasher,
+		relyingParty,
+		providers,
+		clientIPs,
+		mail,
+		serviceauthentication.Config{
+			Verification:  cfg.Verification,
+			PasswordReset: cfg.PasswordReset,
+			LoginCode:     cfg.LoginCode,
+			Introspection: cfg.Introspection,
+			MFA:           cfg.MFA,
+			WebAuthn:      cfg.WebAuthn,
+			Social:        cfg.Social,
+			RateLimit:     cfg.RateLimit,
+			Idempotency:   cfg.Idempotency,
+		},
+	)
 	authenticationServer := grpcauthentication.NewServer(log, authenticationService)
 
 	// Register gRPC handler for AuthenticationService.
@@ -60,7 +152,7 @@ func (a *App) Run() error {
 
 	l, err := net.Listen("tcp4", fmt.Sprintf("0.0.0.0:%d", a.gRPCPort))
 	if err != nil {
-		return fmt.Errorf("%d:%w", op, err)
+		return fmt.Errorf("%s: %w", op, err)
 	}
 
 	a.log.Info("gRPC server started",
@@ -81,3 +173,12 @@ func (a *App) Stop() {
 
 	a.gRPCServer.GracefulStop()
 }
+
+// ForceStop stops gRPC server immediately, cancelling in-flight calls.
+func (a *App) ForceStop() {
+	const op = "grpcApp.ForceStop"
+	a.log.With(slog.String("op", op)).
+		Warn("forcing gRPC server to stop", slog.Int("port", a.gRPCPort))
+
+	a.gRPCServer.Stop()
+}
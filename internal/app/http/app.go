@@ -0,0 +1,71 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/http/jwks"
+)
+
+// App holds HTTP server publishing public endpoints (JWKS).
+type App struct {
+	log      *slog.Logger
+	server   *http.Server
+	httpPort int
+}
+
+// New creates a new HTTP server app but does NOT start it.
+func New(log *slog.Logger, httpPort int, keys jwks.KeySet, keysMaxAge time.Duration) *App {
+	mux := http.NewServeMux()
+	mux.Handle(jwks.Path, jwks.NewHandler(log, keys, keysMaxAge))
+
+	return &App{
+		log: log,
+		server: &http.Server{
+			Handler:           mux,
+			ReadHeaderTimeout: 5 * time.Second,
+		},
+		httpPort: httpPort,
+	}
+}
+
+// MustRun starts HTTP server and panics if any error occurs.
+func (a *App) MustRun() {
+	if err := a.Run(); err != nil {
+		panic(err)
+	}
+}
+
+func (a *App) Run() error {
+	const op = "httpApp.Run"
+
+	l, err := net.Listen("tcp4", fmt.Sprintf("0.0.0.0:%d", a.httpPort))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	a.log.Info("HTTP server started",
+		slog.String("addr", l.Addr().String()))
+
+	if err := a.server.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Stop gracefully stops HTTP server.
+func (a *App) Stop(ctx context.Context) {
+	const op = "httpApp.Stop"
+	a.log.With(slog.String("op", op)).
+		Info("stopping HTTP server", slog.Int("port", a.httpPort))
+
+	if err := a.server.Shutdown(ctx); err != nil {
+		a.log.Error("failed to stop HTTP server", slog.Any("err", err))
+	}
+}
@@ -1,17 +1,111 @@
 package config
 
 import (
+	"errors"
+	"net/url"
+
 	"github.com/spf13/viper"
 )
 
 type Config struct {
+	Env           string               `mapstructure:"env"`
+	Database      DatabaseConfig       `mapstructure:"database"`
+	Redis         RedisConfig          `mapstructure:"redis"`
+	GRPC          GRPCConfig           `mapstructure:"grpc"`
+	HTTP          HTTPConfig           `mapstructure:"http"`
+	Token         TokenConfig          `mapstructure:"token"`
+	Keys          KeysConfig           `mapstructure:"keys"`
+	Verification  VerificationConfig   `mapstructure:"verification"`
+	Mail          MailConfig           `mapstructure:"mail"`
+	PasswordReset PasswordResetConfig  `mapstructure:"password-reset"`
+	LoginCode     LoginCodeConfig      `mapstructure:"login-code"`
+	Introspection IntrospectionConfig  `mapstructure:"introspection"`
+	MFA           MFAConfig            `mapstructure:"mfa"`
+	WebAuthn      WebAuthnConfig       `mapstructure:"webauthn"`
+	Social        SocialConfig         `mapstructure:"social"`
+	RateLimit     RateLimitConfig      `mapstructure:"rate-limit"`
+	Idempotency   IdempotencyConfig    `mapstructure:"idempotency"`
+	Outbox        OutboxConfig         `mapstructure:"outbox"`
+	Password      PasswordPolicyConfig `mapstructure:"password"`
+	PasswordHash  PasswordHashConfig   `mapstructure:"password-hash"`
+}
+
+// minFingerprintKeyLength is the shortest HMAC key of idempotency fingerprints.
+const minFingerprintKeyLength = 32
+
+// MigrateConfig is the part of Config the migrate command needs.
+type MigrateConfig struct {
 	Env      string         `mapstructure:"env"`
 	Database DatabaseConfig `mapstructure:"database"`
-	Redis    RedisConfig    `mapstructure:"redis"`
-	GRPC     GRPCConfig     `mapstructure:"grpc"`
 }
 
 func MustLoad() *Config {
+	var cfg Config
+	mustRead(&cfg)
+
+	// Подтягиваем креды из ENV
+	mustLoadDatabaseCredentials(&cfg.Database)
+	cfg.Redis.Password = viper.GetString("REDIS_PASSWORD")
+	cfg.Mail.SMTP.Password = viper.GetString("SMTP_PASSWORD")
+	cfg.MFA.EncryptionKey = viper.GetString("MFA_ENCRYPTION_KEY")
+	cfg.Keys.EncryptionKey = viper.GetString("SIGNING_KEY_ENCRYPTION_KEY")
+	cfg.Idempotency.FingerprintKey = viper.GetString("IDEMPOTENCY_FINGERPRINT_KEY")
+	cfg.PasswordHash.Pepper = viper.GetString("PASSWORD_PEPPER")
+	cfg.PasswordHash.OldPeppers = viper.GetString("PASSWORD_OLD_PEPPERS")
+	cfg.Social.GitHub.ClientSecret = viper.GetString("GITHUB_CLIENT_SECRET")
+	cfg.Social.Google.ClientSecret = viper.GetString("GOOGLE_CLIENT_SECRET")
+
+	if cfg.Redis.Password == "" {
+		panic("Redis credentials are missing password")
+	}
+
+	if cfg.Mail.Driver == "smtp" && cfg.Mail.SMTP.Username != "" && cfg.Mail.SMTP.Password == "" {
+		panic("SMTP credentials are missing password (SMTP_PASSWORD not set)")
+	}
+
+	if cfg.MFA.EncryptionKey == "" {
+		panic("MFA encryption key is missing (MFA_ENCRYPTION_KEY not set)")
+	}
+
+	if len(cfg.Idempotency.FingerprintKey) < minFingerprintKeyLength {
+		panic("idempotency fingerprint key is missing or shorter than 32 bytes (IDEMPOTENCY_FINGERPRINT_KEY)")
+	}
+
+	if len(cfg.Keys.Files) == 0 && cfg.Keys.EncryptionKey == "" {
+		panic("signing key encryption key is missing (SIGNING_KEY_ENCRYPTION_KEY not set)")
+	}
+
+	if cfg.Social.GitHub.Enabled && cfg.Social.GitHub.ClientSecret == "" {
+		panic("GitHub credentials are missing client secret (GITHUB_CLIENT_SECRET not set)")
+	}
+
+	if cfg.Social.Google.Enabled && cfg.Social.Google.ClientSecret == "" {
+		panic("Google credentials are missing client secret (GOOGLE_CLIENT_SECRET not set)")
+	}
+
+	// Ссылки в письмах собираются из этих URL -> проверяем сразу
+	if err := checkLinkURL(cfg.PasswordReset.URL); err != nil {
+		panic("password-reset url is invalid: " + err.Error())
+	}
+	if err := checkLinkURL(cfg.LoginCode.URL); err != nil {
+		panic("login-code url is invalid: " + err.Error())
+	}
+
+	return &cfg
+}
+
+// MustLoadMigrate loads only the database config, so migrations run
+// without secrets of the other components.
+func MustLoadMigrate() *MigrateConfig {
+	var cfg MigrateConfig
+	mustRead(&cfg)
+	mustLoadDatabaseCredentials(&cfg.Database)
+
+	return &cfg
+}
+
+// mustRead reads config/prod.yaml into cfg.
+func mustRead(cfg any) {
 	viper.SetConfigName("prod")
 	viper.SetConfigType("yaml")
 	viper.AddConfigPath("config")
@@ -25,25 +119,32 @@ func MustLoad() *Config {
 		panic("failed to read config: " + err.Error())
 	}
 
-	var cfg Config
-
 	// Ошибка при маршалинге -> PANIC
-	if err := viper.Unmarshal(&cfg); err != nil {
+	if err := viper.Unmarshal(cfg); err != nil {
 		panic("failed to unmarshal config: " + err.Error())
 	}
+}
 
-	// Подтягиваем креды из ENV
-	cfg.Database.Password = viper.GetString("DB_PASSWORD")
-	cfg.Database.User = viper.GetString("DB_USER")
-	cfg.Redis.Password = viper.GetString("REDIS_PASSWORD")
+func mustLoadDatabaseCredentials(cfg *DatabaseConfig) {
+	cfg.Password = viper.GetString("DB_PASSWORD")
+	cfg.User = viper.GetString("DB_USER")
 
-	if cfg.Database.Password == "" || cfg.Database.User == "" {
+	if cfg.Password == "" || cfg.User == "" {
 		panic("DATABASE credentials are missing (DB_USER / DB_PASSWORD not set)")
 	}
+}
 
-	if cfg.Redis.Password == "" {
-		panic("Redis credentials are missing password")
+// checkLinkURL checks a client page URL emailed links point to.
+func checkLinkURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
 	}
-
-	return &cfg
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return errors.New("scheme must be http or https")
+	}
+	if u.Host == "" {
+		return errors.New("host is missing")
+	}
+	return nil
 }
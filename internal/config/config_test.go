@@ -0,0 +1,28 @@
+package config
+
+import "testing"
+
+func TestCheckLinkURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		wantErr bool
+	}{
+		{name: "https page", url: "https://cloudstorage.local/login/magic"},
+		{name: "page with query", url: "http://localhost:3000/login?lang=en"},
+		{name: "empty", url: "", wantErr: true},
+		{name: "relative", url: "/login/magic", wantErr: true},
+		{name: "no host", url: "https:///login", wantErr: true},
+		{name: "other scheme", url: "javascript:alert(1)", wantErr: true},
+		{name: "unparsable", url: "https://cloud storage.local/%zz", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkLinkURL(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkLinkURL(%q) error = %v, wantErr %v", tt.url, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,10 +1,13 @@
 package config
 
 type DatabaseConfig struct {
-	Host     string `mapstructure:"host"`
-	Port     int    `mapstructure:"port"`
-	Name     string `mapstructure:"name"`
-	SSLMode  string `mapstructure:"ssl-mode"`
-	User     string // из ENV
-	Password string // из Env
+	Host    string `mapstructure:"host"`
+	Port    int    `mapstructure:"port"`
+	Name    string `mapstructure:"name"`
+	SSLMode string `mapstructure:"ssl-mode"`
+	// AutoMigrate applies pending migrations at startup. Replicas
+	// starting together wait for each other on an advisory lock.
+	AutoMigrate bool   `mapstructure:"auto-migrate"`
+	User        string // из ENV
+	Password    string // из Env
 }
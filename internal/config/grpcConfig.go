@@ -5,4 +5,7 @@ import "time"
 type GRPCConfig struct {
 	Port    int           `mapstructure:"port"`
 	Timeout time.Duration `mapstructure:"timeout"`
+	// TrustedProxies are CIDR ranges or addresses of reverse proxies
+	// whose x-forwarded-for / x-real-ip metadata is believed.
+	TrustedProxies []string `mapstructure:"trusted-proxies"`
 }
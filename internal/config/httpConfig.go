@@ -0,0 +1,6 @@
+package config
+
+type HTTPConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	Port    int  `mapstructure:"port"`
+}
@@ -0,0 +1,16 @@
+package config
+
+import "time"
+
+// IdempotencyConfig controls replay of calls sent with an Idempotency-Key.
+type IdempotencyConfig struct {
+	// TTL is how long responses are replayed for retried calls.
+	TTL time.Duration `mapstructure:"ttl"`
+	// LockTTL bounds how long a key is held by a call in progress,
+	// so a crashed instance does not block the key for TTL.
+	LockTTL time.Duration `mapstructure:"lock-ttl"`
+	// FingerprintKey keys the HMAC of request fingerprints, so
+	// fingerprints of secret fields like passwords kept in Redis
+	// can not be brute-forced without it.
+	FingerprintKey string // из ENV
+}
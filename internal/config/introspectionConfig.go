@@ -0,0 +1,9 @@
+package config
+
+import "time"
+
+type IntrospectionConfig struct {
+	// CacheTTL is how long introspection results are cached.
+	// A revocation may take this long to be seen by introspecting services.
+	CacheTTL time.Duration `mapstructure:"cache-ttl"`
+}
@@ -0,0 +1,25 @@
+package config
+
+import "time"
+
+type KeysConfig struct {
+	// Algorithm of generated keys: "EdDSA" or "RS256".
+	Algorithm string `mapstructure:"algorithm"`
+	RSABits   int    `mapstructure:"rsa-bits"`
+	// RotationPeriod is how long a generated key is used for signing.
+	RotationPeriod time.Duration `mapstructure:"rotation-period"`
+	// RefreshInterval is how often keys are reloaded from the database.
+	// A new key is published this long before it is used for signing.
+	RefreshInterval time.Duration `mapstructure:"refresh-interval"`
+	// Files are PEM private keys. When set, keys are not generated
+	// nor rotated; the first file is used for signing.
+	Files []KeyFileConfig `mapstructure:"files"`
+	// EncryptionKey encrypts generated private keys at rest: 32 bytes,
+	// base64. Not used with Files.
+	EncryptionKey string // from ENV
+}
+
+type KeyFileConfig struct {
+	KID  string `mapstructure:"kid"`
+	Path string `mapstructure:"path"`
+}
@@ -0,0 +1,10 @@
+package config
+
+import "time"
+
+type LoginCodeConfig struct {
+	TTL time.Duration `mapstructure:"ttl"`
+	// URL is a client page magic links point to; the flow ID and
+	// the token are appended as ?flow_id=...&code=...
+	URL string `mapstructure:"url"`
+}
@@ -0,0 +1,34 @@
+package config
+
+import "time"
+
+type MailConfig struct {
+	// Driver selects mail transport: "smtp", "file" or "log".
+	Driver        string          `mapstructure:"driver"`
+	From          string          `mapstructure:"from"`
+	DefaultLocale string          `mapstructure:"default-locale"`
+	SMTP          SMTPConfig      `mapstructure:"smtp"`
+	File          FileMailConfig  `mapstructure:"file"`
+	Queue         MailQueueConfig `mapstructure:"queue"`
+}
+
+type SMTPConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string // from ENV
+	// TLS is one of "starttls", "tls" (implicit) or "none".
+	TLS     string        `mapstructure:"tls"`
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+type FileMailConfig struct {
+	Dir string `mapstructure:"dir"`
+}
+
+type MailQueueConfig struct {
+	Size         int           `mapstructure:"size"`
+	Workers      int           `mapstructure:"workers"`
+	MaxRetries   int           `mapstructure:"max-retries"`
+	RetryBackoff time.Duration `mapstructure:"retry-backoff"`
+}
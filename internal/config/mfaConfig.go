@@ -0,0 +1,14 @@
+package config
+
+import "time"
+
+type MFAConfig struct {
+	// Issuer is shown next to the account in authenticator apps.
+	Issuer string `mapstructure:"issuer"`
+	// ChallengeTTL is how long a login waits for the second factor.
+	ChallengeTTL time.Duration `mapstructure:"challenge-ttl"`
+	// RecoveryCodes is how many recovery codes are issued on enrollment.
+	RecoveryCodes int `mapstructure:"recovery-codes"`
+	// EncryptionKey encrypts TOTP secrets at rest: 32 bytes, base64.
+	EncryptionKey string // from ENV
+}
@@ -0,0 +1,29 @@
+package config
+
+import "time"
+
+// OutboxConfig controls the relay publishing user events from the outbox.
+type OutboxConfig struct {
+	// Enabled starts the relay. Events are written to the outbox either way.
+	Enabled bool `mapstructure:"enabled"`
+	// PollInterval is how often the relay looks for new events.
+	PollInterval time.Duration `mapstructure:"poll-interval"`
+	// BatchSize is how many events are claimed in one transaction.
+	BatchSize int `mapstructure:"batch-size"`
+	// RetryBaseDelay is the delay after the first failed publication;
+	// it doubles with every attempt up to RetryMaxDelay.
+	RetryBaseDelay time.Duration `mapstructure:"retry-base-delay"`
+	RetryMaxDelay  time.Duration `mapstructure:"retry-max-delay"`
+	// Retention is how long published events are kept.
+	Retention       time.Duration `mapstructure:"retention"`
+	CleanupInterval time.Duration `mapstructure:"cleanup-interval"`
+	// Sink is where events are published: "redis-stream".
+	Sink        string                  `mapstructure:"sink"`
+	RedisStream OutboxRedisStreamConfig `mapstructure:"redis-stream"`
+}
+
+type OutboxRedisStreamConfig struct {
+	Stream string `mapstructure:"stream"`
+	// MaxLen approximately caps the stream length; 0 keeps all entries.
+	MaxLen int64 `mapstructure:"max-len"`
+}
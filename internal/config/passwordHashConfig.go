@@ -0,0 +1,24 @@
+package config
+
+type PasswordHashConfig struct {
+	Argon2 Argon2Config `mapstructure:"argon2"`
+	// PepperID is stored in hashes made with the pepper, so the pepper
+	// can be replaced without breaking verification silently.
+	PepperID string `mapstructure:"pepper-id"`
+	// Pepper is an optional HMAC key mixed into passwords: base64.
+	Pepper string // from ENV
+	// OldPeppers are replaced peppers still used by stored hashes:
+	// "id:base64,id:base64". Such hashes are upgraded on login.
+	OldPeppers string // from ENV
+}
+
+// Argon2Config holds argon2id cost parameters. Raising them upgrades
+// stored hashes on the next successful login.
+type Argon2Config struct {
+	// Memory is in KiB.
+	Memory      uint32 `mapstructure:"memory"`
+	Iterations  uint32 `mapstructure:"iterations"`
+	Parallelism uint8  `mapstructure:"parallelism"`
+	SaltLength  uint32 `mapstructure:"salt-length"`
+	KeyLength   uint32 `mapstructure:"key-length"`
+}
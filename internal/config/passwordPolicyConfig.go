@@ -0,0 +1,29 @@
+package config
+
+type PasswordPolicyConfig struct {
+	MinLength int `mapstructure:"min-length"`
+	// MaxLength is in bytes: bcrypt ignores everything after 72 bytes.
+	MaxLength int `mapstructure:"max-length"`
+	// MinClasses is how many of lower case, upper case, digits and
+	// symbols a password must contain.
+	MinClasses int `mapstructure:"min-classes"`
+	// DisallowIdentity rejects passwords containing the email or login.
+	DisallowIdentity bool `mapstructure:"disallow-identity"`
+	// Blocklist holds passwords rejected regardless of other rules,
+	// compared case-insensitively; BlocklistFile adds one per line.
+	Blocklist     []string                `mapstructure:"blocklist"`
+	BlocklistFile string                  `mapstructure:"blocklist-file"`
+	Breached      BreachedPasswordsConfig `mapstructure:"breached"`
+}
+
+// BreachedPasswordsConfig points to a local copy of a breached password
+// corpus split by SHA-1 prefix: <dir>/<first 5 hex digits>.txt files with
+// "<remaining 35 hex digits>:<count>" lines, as produced by the
+// haveibeenpwned downloader.
+type BreachedPasswordsConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Dir     string `mapstructure:"dir"`
+	// MinCount is how many times a password must have been seen in
+	// breaches to be rejected.
+	MinCount int `mapstructure:"min-count"`
+}
@@ -0,0 +1,9 @@
+package config
+
+import "time"
+
+type PasswordResetConfig struct {
+	TTL time.Duration `mapstructure:"ttl"`
+	// URL is a client page the reset token is appended to as ?token=...
+	URL string `mapstructure:"url"`
+}
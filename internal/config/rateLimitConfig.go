@@ -0,0 +1,36 @@
+package config
+
+import "time"
+
+type RateLimitConfig struct {
+	Enabled     bool          `mapstructure:"enabled"`
+	Login       ActionLimits  `mapstructure:"login"`
+	Register    ActionLimits  `mapstructure:"register"`
+	VerifyEmail ActionLimits  `mapstructure:"verify-email"`
+	LoginCode   ActionLimits  `mapstructure:"login-code"`
+	MFA         ActionLimits  `mapstructure:"mfa"`
+	Lockout     LockoutConfig `mapstructure:"lockout"`
+}
+
+// ActionLimits are sliding window limits of one RPC.
+// A limit with zero max is not enforced.
+type ActionLimits struct {
+	Email   Limit `mapstructure:"email"`
+	IP      Limit `mapstructure:"ip"`
+	EmailIP Limit `mapstructure:"email-ip"`
+}
+
+type Limit struct {
+	Max    int           `mapstructure:"max"`
+	Window time.Duration `mapstructure:"window"`
+}
+
+// LockoutConfig locks an account after repeated failed logins.
+// The n-th lock in a row lasts base-duration * 2^(n-1), up to max-duration.
+type LockoutConfig struct {
+	Threshold    int           `mapstructure:"threshold"`
+	Window       time.Duration `mapstructure:"window"`
+	BaseDuration time.Duration `mapstructure:"base-duration"`
+	MaxDuration  time.Duration `mapstructure:"max-duration"`
+	ResetAfter   time.Duration `mapstructure:"reset-after"`
+}
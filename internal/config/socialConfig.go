@@ -0,0 +1,28 @@
+package config
+
+import "time"
+
+type SocialConfig struct {
+	// StateTTL is how long a started social login waits for the callback.
+	StateTTL time.Duration `mapstructure:"state-ttl"`
+	// Timeout limits requests to provider endpoints.
+	Timeout time.Duration        `mapstructure:"timeout"`
+	GitHub  SocialProviderConfig `mapstructure:"github"`
+	Google  SocialProviderConfig `mapstructure:"google"`
+}
+
+type SocialProviderConfig struct {
+	Enabled      bool   `mapstructure:"enabled"`
+	ClientID     string `mapstructure:"client-id"`
+	ClientSecret string // from ENV
+	// RedirectURL is the callback registered at the provider.
+	RedirectURL string   `mapstructure:"redirect-url"`
+	Scopes      []string `mapstructure:"scopes"`
+	// Endpoints default to the public ones of the provider;
+	// tests point them at a local fake.
+	AuthURL     string `mapstructure:"auth-url"`
+	TokenURL    string `mapstructure:"token-url"`
+	UserInfoURL string `mapstructure:"user-info-url"`
+	// EmailsURL lists account emails; GitHub only.
+	EmailsURL string `mapstructure:"emails-url"`
+}
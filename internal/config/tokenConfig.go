@@ -0,0 +1,10 @@
+package config
+
+import "time"
+
+type TokenConfig struct {
+	Issuer     string        `mapstructure:"issuer"`
+	Audience   string        `mapstructure:"audience"`
+	AccessTTL  time.Duration `mapstructure:"access-ttl"`
+	RefreshTTL time.Duration `mapstructure:"refresh-ttl"`
+}
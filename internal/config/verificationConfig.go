@@ -0,0 +1,9 @@
+package config
+
+import "time"
+
+type VerificationConfig struct {
+	CodeLength  int           `mapstructure:"code-length"`
+	TTL         time.Duration `mapstructure:"ttl"`
+	MaxAttempts int           `mapstructure:"max-attempts"`
+}
@@ -0,0 +1,13 @@
+package config
+
+import "time"
+
+type WebAuthnConfig struct {
+	// RPID is the relying party ID: the site domain without scheme and port.
+	RPID          string `mapstructure:"rp-id"`
+	RPDisplayName string `mapstructure:"rp-display-name"`
+	// RPOrigins are origins allowed to perform ceremonies, e.g. https://cloudstorage.local.
+	RPOrigins []string `mapstructure:"rp-origins"`
+	// SessionTTL is how long a started ceremony waits to be finished.
+	SessionTTL time.Duration `mapstructure:"session-ttl"`
+}
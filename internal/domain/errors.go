@@ -0,0 +1,152 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrorCode is a stable machine-readable reason of a failed call.
+// Clients switch on codes, never on messages.
+type ErrorCode string
+
+const (
+	CodeInternal        ErrorCode = "INTERNAL"
+	CodeInvalidArgument ErrorCode = "INVALID_ARGUMENT"
+
+	// Accounts and credentials.
+	CodeEmailTaken         ErrorCode = "EMAIL_TAKEN"
+	CodeInvalidCredentials ErrorCode = "INVALID_CREDENTIALS"
+	CodeEmailNotVerified   ErrorCode = "EMAIL_NOT_VERIFIED"
+	CodeUserNotFound       ErrorCode = "USER_NOT_FOUND"
+	CodeWrongPassword      ErrorCode = "WRONG_PASSWORD"
+	CodePasswordUnchanged  ErrorCode = "PASSWORD_UNCHANGED"
+	CodePasswordPolicy     ErrorCode = "PASSWORD_POLICY_VIOLATION"
+	CodeResetTokenInvalid  ErrorCode = "RESET_TOKEN_INVALID"
+
+	// Throttling.
+	CodeRateLimited   ErrorCode = "RATE_LIMITED"
+	CodeAccountLocked ErrorCode = "ACCOUNT_LOCKED"
+
+	// Idempotent retries.
+	CodeIdempotencyKeyReused ErrorCode = "IDEMPOTENCY_KEY_REUSED"
+	CodeRequestInProgress    ErrorCode = "REQUEST_IN_PROGRESS"
+
+	// Tokens and sessions.
+	CodeAccessTokenRequired ErrorCode = "ACCESS_TOKEN_REQUIRED"
+	CodeAccessTokenInvalid  ErrorCode = "ACCESS_TOKEN_INVALID"
+	CodeAccessTokenRevoked  ErrorCode = "ACCESS_TOKEN_REVOKED"
+	CodeRefreshTokenInvalid ErrorCode = "REFRESH_TOKEN_INVALID"
+	CodeSessionNotFound     ErrorCode = "SESSION_NOT_FOUND"
+	CodePermissionDenied    ErrorCode = "PERMISSION_DENIED"
+
+	// One-time codes and verification flows.
+	CodeFlowNotFound ErrorCode = "VERIFICATION_FLOW_NOT_FOUND"
+	CodeFlowLocked   ErrorCode = "VERIFICATION_FLOW_LOCKED"
+	CodeInvalidCode  ErrorCode = "INVALID_CODE"
+
+	// Second factors.
+	CodeMFAAlreadyEnabled      ErrorCode = "MFA_ALREADY_ENABLED"
+	CodeMFANotEnrolled         ErrorCode = "MFA_NOT_ENROLLED"
+	CodePasskeyExists          ErrorCode = "PASSKEY_ALREADY_REGISTERED"
+	CodePasskeySessionNotFound ErrorCode = "PASSKEY_SESSION_NOT_FOUND"
+	CodePasskeyMalformed       ErrorCode = "PASSKEY_MALFORMED"
+	CodePasskeyRejected        ErrorCode = "PASSKEY_REJECTED"
+	CodeNoPasskeys             ErrorCode = "NO_PASSKEYS"
+
+	// Social login and linked identities.
+	CodeUnsupportedProvider      ErrorCode = "UNSUPPORTED_PROVIDER"
+	CodeSocialLoginNotFound      ErrorCode = "SOCIAL_LOGIN_NOT_FOUND"
+	CodeProviderEmailNotVerified ErrorCode = "PROVIDER_EMAIL_NOT_VERIFIED"
+	CodeProviderAuthFailed       ErrorCode = "PROVIDER_AUTH_FAILED"
+	CodeIdentityLinkRequired     ErrorCode = "IDENTITY_LINK_REQUIRED"
+	CodeIdentityAlreadyLinked    ErrorCode = "IDENTITY_ALREADY_LINKED"
+	CodeIdentityTaken            ErrorCode = "IDENTITY_TAKEN"
+	CodeIdentityNotLinked        ErrorCode = "IDENTITY_NOT_LINKED"
+	CodeLastLoginMethod          ErrorCode = "LAST_LOGIN_METHOD"
+)
+
+// FieldViolation describes a request field which is not acceptable.
+type FieldViolation struct {
+	Field       string
+	Reason      string
+	Description string
+}
+
+// Error is a failure the service layer reports to clients. Message is in
+// English and safe to show; transports add translations by Code.
+type Error struct {
+	Code    ErrorCode
+	Message string
+	// Fields lists offending request fields, if any.
+	Fields []FieldViolation
+	// RetryAfter tells throttled clients when to retry.
+	RetryAfter time.Duration
+}
+
+// NewError creates an error of the catalogue code.
+func NewError(code ErrorCode, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// Internal reports an unexpected failure; message says what failed
+// without exposing details, which are logged instead.
+func Internal(message string) *Error {
+	return NewError(CodeInternal, message)
+}
+
+func (e *Error) Error() string {
+	return string(e.Code) + ": " + e.Message
+}
+
+// Is matches errors of the same code, so callers can compare
+// against catalogue values with errors.Is.
+func (e *Error) Is(target error) bool {
+	var t *Error
+	return errors.As(target, &t) && t.Code == e.Code
+}
+
+// Catalogue of errors returned as is. They must not be modified;
+// errors carrying call-specific data are created with NewError.
+var (
+	ErrEmailTaken         = NewError(CodeEmailTaken, "email is already registered")
+	ErrInvalidCredentials = NewError(CodeInvalidCredentials, "invalid email or password")
+	ErrEmailNotVerified   = NewError(CodeEmailNotVerified, "email is not verified")
+	ErrUserNotFound       = NewError(CodeUserNotFound, "user not found")
+	ErrWrongPassword      = NewError(CodeWrongPassword, "current password is incorrect")
+	ErrPasswordUnchanged  = NewError(CodePasswordUnchanged, "new password must differ from the current one")
+	ErrResetTokenInvalid  = NewError(CodeResetTokenInvalid, "invalid or expired reset token")
+
+	ErrIdempotencyKeyReused = NewError(CodeIdempotencyKeyReused, "idempotency key was used with a different request")
+	ErrRequestInProgress    = NewError(CodeRequestInProgress, "request with this idempotency key is in progress, retry later")
+
+	ErrAccessTokenRequired    = NewError(CodeAccessTokenRequired, "access token is required")
+	ErrAccessTokenInvalid     = NewError(CodeAccessTokenInvalid, "invalid access token")
+	ErrAccessTokenRevoked     = NewError(CodeAccessTokenRevoked, "access token revoked")
+	ErrRefreshTokenInvalid    = NewError(CodeRefreshTokenInvalid, "invalid refresh token")
+	ErrSessionNotFound        = NewError(CodeSessionNotFound, "session not found")
+	ErrSessionsForbidden      = NewError(CodePermissionDenied, "not allowed to manage sessions of other users")
+	ErrIntrospectionForbidden = NewError(CodePermissionDenied, "not allowed to introspect tokens")
+
+	ErrFlowNotFound = NewError(CodeFlowNotFound, "verification flow not found or expired")
+	ErrFlowLocked   = NewError(CodeFlowLocked, "too many attempts, verification flow is locked")
+	ErrInvalidCode  = NewError(CodeInvalidCode, "invalid verification code")
+
+	ErrMFAAlreadyEnabled      = NewError(CodeMFAAlreadyEnabled, "totp is already enabled")
+	ErrMFANotEnrolled         = NewError(CodeMFANotEnrolled, "totp enrollment not started")
+	ErrPasskeyExists          = NewError(CodePasskeyExists, "passkey is already registered")
+	ErrPasskeySessionNotFound = NewError(CodePasskeySessionNotFound, "passkey session not found or expired")
+	ErrPasskeyCredential      = NewError(CodePasskeyMalformed, "invalid passkey credential")
+	ErrPasskeyMalformed       = NewError(CodePasskeyMalformed, "invalid passkey assertion")
+	ErrPasskeyRejected        = NewError(CodePasskeyRejected, "invalid passkey assertion")
+	ErrNoPasskeys             = NewError(CodeNoPasskeys, "no passkeys registered")
+
+	ErrUnsupportedProvider      = NewError(CodeUnsupportedProvider, "unsupported provider")
+	ErrSocialLoginNotFound      = NewError(CodeSocialLoginNotFound, "social login not found or expired")
+	ErrProviderEmailNotVerified = NewError(CodeProviderEmailNotVerified, "provider account has no verified email")
+	ErrProviderAuthFailed       = NewError(CodeProviderAuthFailed, "failed to sign in with provider")
+	ErrIdentityLinkRequired     = NewError(CodeIdentityLinkRequired, "email is already registered, sign in and link the provider account")
+	ErrIdentityAlreadyLinked    = NewError(CodeIdentityAlreadyLinked, "provider is already linked, unlink it first")
+	ErrIdentityTaken            = NewError(CodeIdentityTaken, "provider account is linked to another user")
+	ErrIdentityNotLinked        = NewError(CodeIdentityNotLinked, "provider is not linked")
+	ErrLastLoginMethod          = NewError(CodeLastLoginMethod, "can not unlink the last login method, set a password first")
+)
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// EventSchemaVersion is the version of event payloads. It changes only
+// on incompatible changes; adding fields keeps the version.
+const EventSchemaVersion = 1
+
+// EventType names a user lifecycle event other services subscribe to.
+type EventType string
+
+const (
+	// EventUserRegistered carries UserRegistered.
+	EventUserRegistered EventType = "user.registered"
+	// EventUserEmailVerified carries UserEmailVerified.
+	EventUserEmailVerified EventType = "user.email_verified"
+	// EventIdentityLinked and EventIdentityUnlinked carry IdentityChanged.
+	EventIdentityLinked   EventType = "user.identity_linked"
+	EventIdentityUnlinked EventType = "user.identity_unlinked"
+)
+
+// EventEnvelope is the JSON form in which events are published:
+//
+//	{
+//	  "id": "42",
+//	  "type": "user.registered",
+//	  "schema_version": 1,
+//	  "user_id": "7",
+//	  "occurred_at": "2024-05-01T12:00:00Z",
+//	  "data": {"email": "user@example.com", "login": "user"}
+//	}
+//
+// Events are delivered at least once; consumers deduplicate them by id.
+// Events of one user are delivered in the order they occurred.
+type EventEnvelope struct {
+	ID            string          `json:"id"`
+	Type          EventType       `json:"type"`
+	SchemaVersion int             `json:"schema_version"`
+	UserID        string          `json:"user_id"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// UserRegistered is the data of EventUserRegistered.
+type UserRegistered struct {
+	Email string `json:"email"`
+	Login string `json:"login,omitempty"`
+	// Provider is set when the user signed up with an identity provider.
+	Provider      string `json:"provider,omitempty"`
+	EmailVerified bool   `json:"email_verified"`
+}
+
+// UserEmailVerified is the data of EventUserEmailVerified.
+type UserEmailVerified struct {
+	Email string `json:"email"`
+}
+
+// IdentityChanged is the data of EventIdentityLinked and EventIdentityUnlinked.
+type IdentityChanged struct {
+	Provider string `json:"provider"`
+}
@@ -10,6 +10,8 @@ type User struct {
 	Login         string
 	PasswordHash  string
 	EmailVerified bool
+	// Scopes are granted to the user's access tokens, e.g. "sessions:admin".
+	Scopes []string
 
 	GithubID *string
 	GoogleID *string
@@ -0,0 +1,221 @@
+package authentication
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/domain"
+)
+
+// errorDomain is the ErrorInfo domain of errors returned by this service.
+const errorDomain = "authorization-service"
+
+// retryAfterKey is the trailer telling how many seconds
+// to wait before retrying a throttled call.
+const retryAfterKey = "retry-after"
+
+// Locales of messages returned in LocalizedMessage details.
+const (
+	localeEN = "en"
+	localeRU = "ru"
+)
+
+// errNilRequest is returned when a handler receives no request.
+var errNilRequest = domain.NewError(domain.CodeInvalidArgument, "request is nil")
+
+// grpcCodes maps domain error codes to gRPC codes.
+// Codes missing here are reported as Internal.
+var grpcCodes = map[domain.ErrorCode]codes.Code{
+	domain.CodeInvalidArgument: codes.InvalidArgument,
+
+	domain.CodeEmailTaken:         codes.AlreadyExists,
+	domain.CodeInvalidCredentials: codes.Unauthenticated,
+	domain.CodeEmailNotVerified:   codes.FailedPrecondition,
+	domain.CodeUserNotFound:       codes.NotFound,
+	domain.CodeWrongPassword:      codes.PermissionDenied,
+	domain.CodePasswordUnchanged:  codes.InvalidArgument,
+	domain.CodePasswordPolicy:     codes.InvalidArgument,
+	domain.CodeResetTokenInvalid:  codes.InvalidArgument,
+
+	domain.CodeRateLimited:   codes.ResourceExhausted,
+	domain.CodeAccountLocked: codes.ResourceExhausted,
+
+	domain.CodeIdempotencyKeyReused: codes.InvalidArgument,
+	domain.CodeRequestInProgress:    codes.Aborted,
+
+	domain.CodeAccessTokenRequired: codes.Unauthenticated,
+	domain.CodeAccessTokenInvalid:  codes.Unauthenticated,
+	domain.CodeAccessTokenRevoked:  codes.Unauthenticated,
+	domain.CodeRefreshTokenInvalid: codes.Unauthenticated,
+	domain.CodeSessionNotFound:     codes.NotFound,
+	domain.CodePermissionDenied:    codes.PermissionDenied,
+
+	domain.CodeFlowNotFound: codes.NotFound,
+	domain.CodeFlowLocked:   codes.FailedPrecondition,
+	domain.CodeInvalidCode:  codes.InvalidArgument,
+
+	domain.CodeMFAAlreadyEnabled:      codes.FailedPrecondition,
+	domain.CodeMFANotEnrolled:         codes.FailedPrecondition,
+	domain.CodePasskeyExists:          codes.AlreadyExists,
+	domain.CodePasskeySessionNotFound: codes.NotFound,
+	domain.CodePasskeyMalformed:       codes.InvalidArgument,
+	domain.CodePasskeyRejected:        codes.Unauthenticated,
+	domain.CodeNoPasskeys:             codes.FailedPrecondition,
+
+	domain.CodeUnsupportedProvider:      codes.InvalidArgument,
+	domain.CodeSocialLoginNotFound:      codes.NotFound,
+	domain.CodeProviderEmailNotVerified: codes.FailedPrecondition,
+	domain.CodeProviderAuthFailed:       codes.Unauthenticated,
+	domain.CodeIdentityLinkRequired:     codes.FailedPrecondition,
+	domain.CodeIdentityAlreadyLinked:    codes.FailedPrecondition,
+	domain.CodeIdentityTaken:            codes.AlreadyExists,
+	domain.CodeIdentityNotLinked:        codes.NotFound,
+	domain.CodeLastLoginMethod:          codes.FailedPrecondition,
+}
+
+// russianMessages translates domain errors for clients asking for "ru".
+// English messages come from the errors themselves.
+var russianMessages = map[domain.ErrorCode]string{
+	domain.CodeInternal:        "Внутренняя ошибка, попробуйте позже",
+	domain.CodeInvalidArgument: "Некорректный запрос",
+
+	domain.CodeEmailTaken:         "Этот email уже зарегистрирован",
+	domain.CodeInvalidCredentials: "Неверный email или пароль",
+	domain.CodeEmailNotVerified:   "Email не подтверждён",
+	domain.CodeUserNotFound:       "Пользователь не найден",
+	domain.CodeWrongPassword:      "Текущий пароль указан неверно",
+	domain.CodePasswordUnchanged:  "Новый пароль должен отличаться от текущего",
+	domain.CodePasswordPolicy:     "Пароль не соответствует требованиям",
+	domain.CodeResetTokenInvalid:  "Ссылка для сброса пароля недействительна или устарела",
+
+	domain.CodeRateLimited:   "Слишком много запросов, попробуйте позже",
+	domain.CodeAccountLocked: "Аккаунт временно заблокирован, попробуйте позже",
+
+	domain.CodeIdempotencyKeyReused: "Ключ идемпотентности уже использован для другого запроса",
+	domain.CodeRequestInProgress:    "Запрос с этим ключом идемпотентности ещё выполняется, повторите позже",
+
+	domain.CodeAccessTokenRequired: "Требуется токен доступа",
+	domain.CodeAccessTokenInvalid:  "Недействительный токен доступа",
+	domain.CodeAccessTokenRevoked:  "Токен доступа отозван",
+	domain.CodeRefreshTokenInvalid: "Недействительный токен обновления",
+	domain.CodeSessionNotFound:     "Сессия не найдена",
+	domain.CodePermissionDenied:    "Недостаточно прав",
+
+	domain.CodeFlowNotFound: "Код подтверждения не найден или устарел",
+	domain.CodeFlowLocked:   "Слишком много попыток, запросите новый код",
+	domain.CodeInvalidCode:  "Неверный код",
+
+	domain.CodeMFAAlreadyEnabled:      "Двухфакторная аутентификация уже включена",
+	domain.CodeMFANotEnrolled:         "Подключение двухфакторной аутентификации не начато",
+	domain.CodePasskeyExists:          "Этот ключ доступа уже зарегистрирован",
+	domain.CodePasskeySessionNotFound: "Сессия ключа доступа не найдена или устарела",
+	domain.CodePasskeyMalformed:       "Некорректные данные ключа доступа",
+	domain.CodePasskeyRejected:        "Ключ доступа отклонён",
+	domain.CodeNoPasskeys:             "Нет зарегистрированных ключей доступа",
+
+	domain.CodeUnsupportedProvider:      "Провайдер не поддерживается",
+	domain.CodeSocialLoginNotFound:      "Вход через провайдера не найден или устарел",
+	domain.CodeProviderEmailNotVerified: "У аккаунта провайдера нет подтверждённого email",
+	domain.CodeProviderAuthFailed:       "Не удалось войти через провайдера",
+	domain.CodeIdentityLinkRequired:     "Email уже зарегистрирован, войдите и привяжите аккаунт провайдера",
+	domain.CodeIdentityAlreadyLinked:    "Провайдер уже привязан, сначала отвяжите его",
+	domain.CodeIdentityTaken:            "Аккаунт провайдера привязан к другому пользователю",
+	domain.CodeIdentityNotLinked:        "Провайдер не привязан",
+	domain.CodeLastLoginMethod:          "Нельзя отвязать последний способ входа, сначала задайте пароль",
+}
+
+// statusError converts an error returned by the service into a gRPC
+// status carrying ErrorInfo with the domain code, field violations,
+// a localized message and, for throttled calls, RetryInfo.
+// Errors outside the catalogue are reported as Internal.
+func statusError(ctx context.Context, err error) error {
+	var derr *domain.Error
+	if !errors.As(err, &derr) {
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		derr = domain.Internal("internal error")
+	}
+
+	code, ok := grpcCodes[derr.Code]
+	if !ok {
+		code = codes.Internal
+	}
+
+	locale, message := localizedMessage(ctx, derr)
+	details := []protoadapt.MessageV1{
+		&errdetails.ErrorInfo{
+			Reason: string(derr.Code),
+			Domain: errorDomain,
+		},
+		&errdetails.LocalizedMessage{
+			Locale:  locale,
+			Message: message,
+		},
+	}
+
+	if len(derr.Fields) > 0 {
+		violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(derr.Fields))
+		for _, f := range derr.Fields {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{
+				Field:       f.Field,
+				Reason:      f.Reason,
+				Description: f.Description,
+			})
+		}
+		details = append(details, &errdetails.BadRequest{FieldViolations: violations})
+	}
+
+	if derr.RetryAfter > 0 {
+		seconds := int64(math.Ceil(derr.RetryAfter.Seconds()))
+		_ = grpc.SetTrailer(ctx, metadata.Pairs(retryAfterKey, strconv.FormatInt(seconds, 10)))
+
+		details = append(details, &errdetails.RetryInfo{
+			RetryDelay: durationpb.New(time.Duration(seconds) * time.Second),
+		})
+	}
+
+	st, detailsErr := status.New(code, derr.Message).WithDetails(details...)
+	if detailsErr != nil {
+		return status.Error(code, derr.Message)
+	}
+	return st.Err()
+}
+
+// localizedMessage picks the message for the first supported language
+// of the accept-language metadata, falling back to English.
+func localizedMessage(ctx context.Context, err *domain.Error) (string, string) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	for _, value := range md.Get("accept-language") {
+		for _, tag := range strings.Split(value, ",") {
+			tag, _, _ = strings.Cut(tag, ";")
+			tag = strings.ToLower(strings.TrimSpace(tag))
+			tag, _, _ = strings.Cut(strings.ReplaceAll(tag, "_", "-"), "-")
+
+			switch tag {
+			case localeEN:
+				return localeEN, err.Message
+			case localeRU:
+				if msg, ok := russianMessages[err.Code]; ok {
+					return localeRU, msg
+				}
+				return localeEN, err.Message
+			}
+		}
+	}
+
+	return localeEN, err.Message
+}
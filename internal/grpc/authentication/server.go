@@ -5,8 +5,8 @@ import (
 	"log/slog"
 
 	authorizationservicev1 "github.com/GrishanyaaShustov/CloudStorage-Protos-Service/gen/go/authorization-service"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/validation"
 )
 
 // Service describes authentication business logic.
@@ -17,6 +17,28 @@ type Service interface {
 	Login(ctx context.Context, request *authorizationservicev1.LoginRequest) (*authorizationservicev1.LoginResponse, error)
 	RefreshToken(ctx context.Context, request *authorizationservicev1.RefreshTokenRequest) (*authorizationservicev1.RefreshTokenResponse, error)
 	Logout(ctx context.Context, request *authorizationservicev1.LogoutRequest) (*authorizationservicev1.LogoutResponse, error)
+	RequestPasswordReset(ctx context.Context, request *authorizationservicev1.RequestPasswordResetRequest) (*authorizationservicev1.RequestPasswordResetResponse, error)
+	ResetPassword(ctx context.Context, request *authorizationservicev1.ResetPasswordRequest) (*authorizationservicev1.ResetPasswordResponse, error)
+	ChangePassword(ctx context.Context, request *authorizationservicev1.ChangePasswordRequest) (*authorizationservicev1.ChangePasswordResponse, error)
+	GetJWKS(ctx context.Context, request *authorizationservicev1.GetJWKSRequest) (*authorizationservicev1.GetJWKSResponse, error)
+	IntrospectToken(ctx context.Context, request *authorizationservicev1.IntrospectTokenRequest) (*authorizationservicev1.IntrospectTokenResponse, error)
+	ListSessions(ctx context.Context, request *authorizationservicev1.ListSessionsRequest) (*authorizationservicev1.ListSessionsResponse, error)
+	RevokeSession(ctx context.Context, request *authorizationservicev1.RevokeSessionRequest) (*authorizationservicev1.RevokeSessionResponse, error)
+	RevokeAllSessions(ctx context.Context, request *authorizationservicev1.RevokeAllSessionsRequest) (*authorizationservicev1.RevokeAllSessionsResponse, error)
+	EnrollTOTP(ctx context.Context, request *authorizationservicev1.EnrollTOTPRequest) (*authorizationservicev1.EnrollTOTPResponse, error)
+	ConfirmTOTP(ctx context.Context, request *authorizationservicev1.ConfirmTOTPRequest) (*authorizationservicev1.ConfirmTOTPResponse, error)
+	CompleteMFAChallenge(ctx context.Context, request *authorizationservicev1.CompleteMFAChallengeRequest) (*authorizationservicev1.CompleteMFAChallengeResponse, error)
+	BeginPasskeyRegistration(ctx context.Context, request *authorizationservicev1.BeginPasskeyRegistrationRequest) (*authorizationservicev1.BeginPasskeyRegistrationResponse, error)
+	FinishPasskeyRegistration(ctx context.Context, request *authorizationservicev1.FinishPasskeyRegistrationRequest) (*authorizationservicev1.FinishPasskeyRegistrationResponse, error)
+	BeginPasskeyLogin(ctx context.Context, request *authorizationservicev1.BeginPasskeyLoginRequest) (*authorizationservicev1.BeginPasskeyLoginResponse, error)
+	FinishPasskeyLogin(ctx context.Context, request *authorizationservicev1.FinishPasskeyLoginRequest) (*authorizationservicev1.FinishPasskeyLoginResponse, error)
+	StartSocialLogin(ctx context.Context, request *authorizationservicev1.StartSocialLoginRequest) (*authorizationservicev1.StartSocialLoginResponse, error)
+	CompleteSocialLogin(ctx context.Context, request *authorizationservicev1.CompleteSocialLoginRequest) (*authorizationservicev1.CompleteSocialLoginResponse, error)
+	LinkIdentity(ctx context.Context, request *authorizationservicev1.LinkIdentityRequest) (*authorizationservicev1.LinkIdentityResponse, error)
+	UnlinkIdentity(ctx context.Context, request *authorizationservicev1.UnlinkIdentityRequest) (*authorizationservicev1.UnlinkIdentityResponse, error)
+	ListIdentities(ctx context.Context, request *authorizationservicev1.ListIdentitiesRequest) (*authorizationservicev1.ListIdentitiesResponse, error)
+	RequestLoginCode(ctx context.Context, request *authorizationservicev1.RequestLoginCodeRequest) (*authorizationservicev1.RequestLoginCodeResponse, error)
+	CompleteLoginCode(ctx context.Context, request *authorizationservicev1.CompleteLoginCodeRequest) (*authorizationservicev1.CompleteLoginCodeResponse, error)
 }
 
 // Server is a gRPC transport for AuthenticationService.
@@ -40,21 +62,11 @@ func NewServer(log *slog.Logger, service Service) *Server {
 // to the Service implementation.
 func (s *Server) Register(ctx context.Context, request *authorizationservicev1.RegisterRequest) (*authorizationservicev1.RegisterResponse, error) {
 	if request == nil {
-		return nil, status.Error(codes.InvalidArgument, "request is nil")
+		return nil, statusError(ctx, errNilRequest)
 	}
 
-	// Basic input validation on transport layer.
-
-	if request.GetLogin() == "" {
-		return nil, status.Error(codes.InvalidArgument, "login is required")
-	}
-
-	if request.GetEmail() == "" {
-		return nil, status.Error(codes.InvalidArgument, "email is required")
-	}
-
-	if request.GetPassword() == "" {
-		return nil, status.Error(codes.InvalidArgument, "password is required")
+	if err := validation.Register(request); err != nil {
+		return nil, statusError(ctx, err)
 	}
 
 	s.log.InfoContext(ctx, "Register called",
@@ -64,13 +76,13 @@ func (s *Server) Register(ctx context.Context, request *authorizationservicev1.R
 
 	resp, err := s.service.Register(ctx, request)
 	if err != nil {
-		// The service is expected to return a gRPC-aware error (status.Error),
-		// but just in case we still log it here.
+		// The service returns domain errors; statusError maps them
+		// to gRPC statuses with error details.
 		s.log.ErrorContext(ctx, "Register failed",
 			"email", request.GetEmail(),
 			"login", request.GetLogin(),
 		)
-		return nil, err
+		return nil, statusError(ctx, err)
 	}
 	return resp, nil
 }
@@ -79,15 +91,11 @@ func (s *Server) Register(ctx context.Context, request *authorizationservicev1.R
 // the actual logic to the Service implementation.
 func (s *Server) Login(ctx context.Context, request *authorizationservicev1.LoginRequest) (*authorizationservicev1.LoginResponse, error) {
 	if request == nil {
-		return nil, status.Error(codes.InvalidArgument, "request is nil")
-	}
-
-	if request.GetEmail() == "" {
-		return nil, status.Error(codes.InvalidArgument, "email is required")
+		return nil, statusError(ctx, errNilRequest)
 	}
 
-	if request.GetPassword() == "" {
-		return nil, status.Error(codes.InvalidArgument, "password is required")
+	if err := validation.Login(request); err != nil {
+		return nil, statusError(ctx, err)
 	}
 
 	s.log.InfoContext(ctx, "Login called",
@@ -97,14 +105,14 @@ func (s *Server) Login(ctx context.Context, request *authorizationservicev1.Logi
 	resp, err := s.service.Login(ctx, request)
 
 	if err != nil {
-		// The service is expected to return a gRPC-aware error (status.Error),
-		// but just in case we still log it here.
+		// The service returns domain errors; statusError maps them
+		// to gRPC statuses with error details.
 		s.log.ErrorContext(ctx, "Login failed failed",
 			"email", request.GetEmail(),
 			"client_id", request.GetClientId(),
 		)
 
-		return nil, err
+		return nil, statusError(ctx, err)
 	}
 
 	return resp, err
@@ -115,11 +123,11 @@ func (s *Server) Login(ctx context.Context, request *authorizationservicev1.Logi
 // the actual refresh flow to the Service implementation.
 func (s *Server) RefreshToken(ctx context.Context, request *authorizationservicev1.RefreshTokenRequest) (*authorizationservicev1.RefreshTokenResponse, error) {
 	if request == nil {
-		return nil, status.Error(codes.InvalidArgument, "request is nil")
+		return nil, statusError(ctx, errNilRequest)
 	}
 
-	if request.GetRefreshToken() == "" {
-		return nil, status.Error(codes.InvalidArgument, "refresh token is required")
+	if err := validation.RefreshToken(request); err != nil {
+		return nil, statusError(ctx, err)
 	}
 
 	s.log.InfoContext(ctx, "RefreshToken called",
@@ -133,7 +141,7 @@ func (s *Server) RefreshToken(ctx context.Context, request *authorizationservice
 			"client_id", request.GetClientId(),
 		)
 
-		return nil, err
+		return nil, statusError(ctx, err)
 	}
 	return resp, nil
 }
@@ -143,11 +151,11 @@ func (s *Server) RefreshToken(ctx context.Context, request *authorizationservice
 // revocation logic to the Service implementation.
 func (s *Server) Logout(ctx context.Context, request *authorizationservicev1.LogoutRequest) (*authorizationservicev1.LogoutResponse, error) {
 	if request == nil {
-		return nil, status.Error(codes.InvalidArgument, "request is nil")
+		return nil, statusError(ctx, errNilRequest)
 	}
 
-	if request.GetRefreshToken() == "" {
-		return nil, status.Error(codes.InvalidArgument, "refresh_token is required")
+	if err := validation.Logout(request); err != nil {
+		return nil, statusError(ctx, err)
 	}
 
 	s.log.InfoContext(ctx, "Logout called",
@@ -160,7 +168,7 @@ func (s *Server) Logout(ctx context.Context, request *authorizationservicev1.Log
 		s.log.ErrorContext(ctx, "Logout failed",
 			"client_id", request.GetClientId(),
 		)
-		return nil, err
+		return nil, statusError(ctx, err)
 	}
 
 	return resp, nil
@@ -170,15 +178,11 @@ func (s *Server) Logout(ctx context.Context, request *authorizationservicev1.Log
 // The handler only checks basic input and delegates the rest to the Service.
 func (s *Server) VerifyEmail(ctx context.Context, request *authorizationservicev1.VerifyEmailRequest) (*authorizationservicev1.VerifyEmailResponse, error) {
 	if request == nil {
-		return nil, status.Error(codes.InvalidArgument, "request is nil")
-	}
-
-	if request.GetVerificationCode() == "" {
-		return nil, status.Error(codes.InvalidArgument, "verification_code is required")
+		return nil, statusError(ctx, errNilRequest)
 	}
 
-	if request.GetFlowId() == "" {
-		return nil, status.Error(codes.InvalidArgument, "flow_id is required")
+	if err := validation.VerifyEmail(request); err != nil {
+		return nil, statusError(ctx, err)
 	}
 
 	s.log.InfoContext(ctx, "VerifyEmail called")
@@ -186,7 +190,467 @@ func (s *Server) VerifyEmail(ctx context.Context, request *authorizationservicev
 	resp, err := s.service.VerifyEmail(ctx, request)
 	if err != nil {
 		s.log.ErrorContext(ctx, "VerifyEmail failed")
-		return nil, err
+		return nil, statusError(ctx, err)
+	}
+
+	return resp, nil
+}
+
+// RequestPasswordReset starts password recovery by emailing a reset link.
+// The response does not reveal whether the email is registered.
+func (s *Server) RequestPasswordReset(ctx context.Context, request *authorizationservicev1.RequestPasswordResetRequest) (*authorizationservicev1.RequestPasswordResetResponse, error) {
+	if request == nil {
+		return nil, statusError(ctx, errNilRequest)
+	}
+
+	if err := validation.RequestPasswordReset(request); err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	s.log.InfoContext(ctx, "RequestPasswordReset called")
+
+	resp, err := s.service.RequestPasswordReset(ctx, request)
+	if err != nil {
+		s.log.ErrorContext(ctx, "RequestPasswordReset failed")
+		return nil, statusError(ctx, err)
+	}
+
+	return resp, nil
+}
+
+// ResetPassword sets a new password using a token from the reset email.
+func (s *Server) ResetPassword(ctx context.Context, request *authorizationservicev1.ResetPasswordRequest) (*authorizationservicev1.ResetPasswordResponse, error) {
+	if request == nil {
+		return nil, statusError(ctx, errNilRequest)
+	}
+
+	if err := validation.ResetPassword(request); err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	s.log.InfoContext(ctx, "ResetPassword called")
+
+	resp, err := s.service.ResetPassword(ctx, request)
+	if err != nil {
+		s.log.ErrorContext(ctx, "ResetPassword failed")
+		return nil, statusError(ctx, err)
+	}
+
+	return resp, nil
+}
+
+// ChangePassword replaces the password of the caller identified by
+// the access token from metadata. Authentication is done by the Service.
+func (s *Server) ChangePassword(ctx context.Context, request *authorizationservicev1.ChangePasswordRequest) (*authorizationservicev1.ChangePasswordResponse, error) {
+	if request == nil {
+		return nil, statusError(ctx, errNilRequest)
+	}
+
+	if err := validation.ChangePassword(request); err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	s.log.InfoContext(ctx, "ChangePassword called")
+
+	resp, err := s.service.ChangePassword(ctx, request)
+	if err != nil {
+		s.log.ErrorContext(ctx, "ChangePassword failed")
+		return nil, statusError(ctx, err)
+	}
+
+	return resp, nil
+}
+
+// GetJWKS returns the public keys access tokens can be verified with.
+func (s *Server) GetJWKS(ctx context.Context, request *authorizationservicev1.GetJWKSRequest) (*authorizationservicev1.GetJWKSResponse, error) {
+	if request == nil {
+		return nil, statusError(ctx, errNilRequest)
+	}
+
+	resp, err := s.service.GetJWKS(ctx, request)
+	if err != nil {
+		s.log.ErrorContext(ctx, "GetJWKS failed")
+		return nil, statusError(ctx, err)
+	}
+
+	return resp, nil
+}
+
+// IntrospectToken reports whether an access token is active and returns its claims.
+// The caller needs an access token with the tokens:introspect scope.
+func (s *Server) IntrospectToken(ctx context.Context, request *authorizationservicev1.IntrospectTokenRequest) (*authorizationservicev1.IntrospectTokenResponse, error) {
+	if request == nil {
+		return nil, statusError(ctx, errNilRequest)
+	}
+
+	if err := validation.IntrospectToken(request); err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	resp, err := s.service.IntrospectToken(ctx, request)
+	if err != nil {
+		s.log.ErrorContext(ctx, "IntrospectToken failed")
+		return nil, statusError(ctx, err)
+	}
+
+	return resp, nil
+}
+
+// ListSessions returns active sessions of the caller or, for staff, of the requested user.
+func (s *Server) ListSessions(ctx context.Context, request *authorizationservicev1.ListSessionsRequest) (*authorizationservicev1.ListSessionsResponse, error) {
+	if request == nil {
+		return nil, statusError(ctx, errNilRequest)
+	}
+
+	if err := validation.ListSessions(request); err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	s.log.InfoContext(ctx, "ListSessions called")
+
+	resp, err := s.service.ListSessions(ctx, request)
+	if err != nil {
+		s.log.ErrorContext(ctx, "ListSessions failed")
+		return nil, statusError(ctx, err)
+	}
+
+	return resp, nil
+}
+
+// RevokeSession signs a single session out.
+func (s *Server) RevokeSession(ctx context.Context, request *authorizationservicev1.RevokeSessionRequest) (*authorizationservicev1.RevokeSessionResponse, error) {
+	if request == nil {
+		return nil, statusError(ctx, errNilRequest)
+	}
+
+	if err := validation.RevokeSession(request); err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	s.log.InfoContext(ctx, "RevokeSession called")
+
+	resp, err := s.service.RevokeSession(ctx, request)
+	if err != nil {
+		s.log.ErrorContext(ctx, "RevokeSession failed")
+		return nil, statusError(ctx, err)
+	}
+
+	return resp, nil
+}
+
+// RevokeAllSessions signs the user out of every session.
+func (s *Server) RevokeAllSessions(ctx context.Context, request *authorizationservicev1.RevokeAllSessionsRequest) (*authorizationservicev1.RevokeAllSessionsResponse, error) {
+	if request == nil {
+		return nil, statusError(ctx, errNilRequest)
+	}
+
+	if err := validation.RevokeAllSessions(request); err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	s.log.InfoContext(ctx, "RevokeAllSessions called")
+
+	resp, err := s.service.RevokeAllSessions(ctx, request)
+	if err != nil {
+		s.log.ErrorContext(ctx, "RevokeAllSessions failed")
+		return nil, statusError(ctx, err)
+	}
+
+	return resp, nil
+}
+
+// EnrollTOTP starts TOTP enrollment of the caller.
+func (s *Server) EnrollTOTP(ctx context.Context, request *authorizationservicev1.EnrollTOTPRequest) (*authorizationservicev1.EnrollTOTPResponse, error) {
+	if request == nil {
+		return nil, statusError(ctx, errNilRequest)
+	}
+
+	s.log.InfoContext(ctx, "EnrollTOTP called")
+
+	resp, err := s.service.EnrollTOTP(ctx, request)
+	if err != nil {
+		s.log.ErrorContext(ctx, "EnrollTOTP failed")
+		return nil, statusError(ctx, err)
+	}
+
+	return resp, nil
+}
+
+// ConfirmTOTP activates TOTP of the caller with the first code.
+func (s *Server) ConfirmTOTP(ctx context.Context, request *authorizationservicev1.ConfirmTOTPRequest) (*authorizationservicev1.ConfirmTOTPResponse, error) {
+	if request == nil {
+		return nil, statusError(ctx, errNilRequest)
+	}
+
+	if err := validation.ConfirmTOTP(request); err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	s.log.InfoContext(ctx, "ConfirmTOTP called")
+
+	resp, err := s.service.ConfirmTOTP(ctx, request)
+	if err != nil {
+		s.log.ErrorContext(ctx, "ConfirmTOTP failed")
+		return nil, statusError(ctx, err)
+	}
+
+	return resp, nil
+}
+
+// CompleteMFAChallenge exchanges an MFA challenge and a second factor code for tokens.
+func (s *Server) CompleteMFAChallenge(ctx context.Context, request *authorizationservicev1.CompleteMFAChallengeRequest) (*authorizationservicev1.CompleteMFAChallengeResponse, error) {
+	if request == nil {
+		return nil, statusError(ctx, errNilRequest)
+	}
+
+	if err := validation.CompleteMFAChallenge(request); err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	s.log.InfoContext(ctx, "CompleteMFAChallenge called")
+
+	resp, err := s.service.CompleteMFAChallenge(ctx, request)
+	if err != nil {
+		s.log.ErrorContext(ctx, "CompleteMFAChallenge failed")
+		return nil, statusError(ctx, err)
+	}
+
+	return resp, nil
+}
+
+// BeginPasskeyRegistration starts registration of a passkey for the caller.
+func (s *Server) BeginPasskeyRegistration(ctx context.Context, request *authorizationservicev1.BeginPasskeyRegistrationRequest) (*authorizationservicev1.BeginPasskeyRegistrationResponse, error) {
+	if request == nil {
+		return nil, statusError(ctx, errNilRequest)
+	}
+
+	s.log.InfoContext(ctx, "BeginPasskeyRegistration called")
+
+	resp, err := s.service.BeginPasskeyRegistration(ctx, request)
+	if err != nil {
+		s.log.ErrorContext(ctx, "BeginPasskeyRegistration failed")
+		return nil, statusError(ctx, err)
+	}
+
+	return resp, nil
+}
+
+// FinishPasskeyRegistration stores a passkey created by the authenticator.
+func (s *Server) FinishPasskeyRegistration(ctx context.Context, request *authorizationservicev1.FinishPasskeyRegistrationRequest) (*authorizationservicev1.FinishPasskeyRegistrationResponse, error) {
+	if request == nil {
+		return nil, statusError(ctx, errNilRequest)
+	}
+
+	if err := validation.FinishPasskeyRegistration(request); err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	s.log.InfoContext(ctx, "FinishPasskeyRegistration called")
+
+	resp, err := s.service.FinishPasskeyRegistration(ctx, request)
+	if err != nil {
+		s.log.ErrorContext(ctx, "FinishPasskeyRegistration failed")
+		return nil, statusError(ctx, err)
+	}
+
+	return resp, nil
+}
+
+// BeginPasskeyLogin starts a passwordless or second factor passkey login.
+func (s *Server) BeginPasskeyLogin(ctx context.Context, request *authorizationservicev1.BeginPasskeyLoginRequest) (*authorizationservicev1.BeginPasskeyLoginResponse, error) {
+	if request == nil {
+		return nil, statusError(ctx, errNilRequest)
+	}
+
+	if err := validation.BeginPasskeyLogin(request); err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	s.log.InfoContext(ctx, "BeginPasskeyLogin called")
+
+	resp, err := s.service.BeginPasskeyLogin(ctx, request)
+	if err != nil {
+		s.log.ErrorContext(ctx, "BeginPasskeyLogin failed")
+		return nil, statusError(ctx, err)
+	}
+
+	return resp, nil
+}
+
+// FinishPasskeyLogin verifies a passkey assertion and issues tokens.
+func (s *Server) FinishPasskeyLogin(ctx context.Context, request *authorizationservicev1.FinishPasskeyLoginRequest) (*authorizationservicev1.FinishPasskeyLoginResponse, error) {
+	if request == nil {
+		return nil, statusError(ctx, errNilRequest)
+	}
+
+	if err := validation.FinishPasskeyLogin(request); err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	s.log.InfoContext(ctx, "FinishPasskeyLogin called")
+
+	resp, err := s.service.FinishPasskeyLogin(ctx, request)
+	if err != nil {
+		s.log.ErrorContext(ctx, "FinishPasskeyLogin failed")
+		return nil, statusError(ctx, err)
+	}
+
+	return resp, nil
+}
+
+// StartSocialLogin returns the authorize URL of an identity provider.
+func (s *Server) StartSocialLogin(ctx context.Context, request *authorizationservicev1.StartSocialLoginRequest) (*authorizationservicev1.StartSocialLoginResponse, error) {
+	if request == nil {
+		return nil, statusError(ctx, errNilRequest)
+	}
+
+	if err := validation.StartSocialLogin(request); err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	s.log.InfoContext(ctx, "StartSocialLogin called",
+		"provider", request.GetProvider(),
+		"client_id", request.GetClientId(),
+	)
+
+	resp, err := s.service.StartSocialLogin(ctx, request)
+	if err != nil {
+		s.log.ErrorContext(ctx, "StartSocialLogin failed")
+		return nil, statusError(ctx, err)
+	}
+
+	return resp, nil
+}
+
+// CompleteSocialLogin handles the provider callback and issues tokens.
+func (s *Server) CompleteSocialLogin(ctx context.Context, request *authorizationservicev1.CompleteSocialLoginRequest) (*authorizationservicev1.CompleteSocialLoginResponse, error) {
+	if request == nil {
+		return nil, statusError(ctx, errNilRequest)
+	}
+
+	if err := validation.CompleteSocialLogin(request); err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	s.log.InfoContext(ctx, "CompleteSocialLogin called",
+		"provider", request.GetProvider(),
+		"client_id", request.GetClientId(),
+	)
+
+	resp, err := s.service.CompleteSocialLogin(ctx, request)
+	if err != nil {
+		s.log.ErrorContext(ctx, "CompleteSocialLogin failed")
+		return nil, statusError(ctx, err)
+	}
+
+	return resp, nil
+}
+
+// LinkIdentity attaches a provider account to the caller.
+func (s *Server) LinkIdentity(ctx context.Context, request *authorizationservicev1.LinkIdentityRequest) (*authorizationservicev1.LinkIdentityResponse, error) {
+	if request == nil {
+		return nil, statusError(ctx, errNilRequest)
+	}
+
+	if err := validation.LinkIdentity(request); err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	s.log.InfoContext(ctx, "LinkIdentity called",
+		"provider", request.GetProvider(),
+	)
+
+	resp, err := s.service.LinkIdentity(ctx, request)
+	if err != nil {
+		s.log.ErrorContext(ctx, "LinkIdentity failed")
+		return nil, statusError(ctx, err)
+	}
+
+	return resp, nil
+}
+
+// UnlinkIdentity detaches a provider account from the caller.
+func (s *Server) UnlinkIdentity(ctx context.Context, request *authorizationservicev1.UnlinkIdentityRequest) (*authorizationservicev1.UnlinkIdentityResponse, error) {
+	if request == nil {
+		return nil, statusError(ctx, errNilRequest)
+	}
+
+	if err := validation.UnlinkIdentity(request); err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	s.log.InfoContext(ctx, "UnlinkIdentity called",
+		"provider", request.GetProvider(),
+	)
+
+	resp, err := s.service.UnlinkIdentity(ctx, request)
+	if err != nil {
+		s.log.ErrorContext(ctx, "UnlinkIdentity failed")
+		return nil, statusError(ctx, err)
+	}
+
+	return resp, nil
+}
+
+// ListIdentities returns provider accounts linked to the caller.
+func (s *Server) ListIdentities(ctx context.Context, request *authorizationservicev1.ListIdentitiesRequest) (*authorizationservicev1.ListIdentitiesResponse, error) {
+	if request == nil {
+		return nil, statusError(ctx, errNilRequest)
+	}
+
+	s.log.InfoContext(ctx, "ListIdentities called")
+
+	resp, err := s.service.ListIdentities(ctx, request)
+	if err != nil {
+		s.log.ErrorContext(ctx, "ListIdentities failed")
+		return nil, statusError(ctx, err)
+	}
+
+	return resp, nil
+}
+
+// RequestLoginCode emails a one-time sign-in code or magic link.
+func (s *Server) RequestLoginCode(ctx context.Context, request *authorizationservicev1.RequestLoginCodeRequest) (*authorizationservicev1.RequestLoginCodeResponse, error) {
+	if request == nil {
+		return nil, statusError(ctx, errNilRequest)
+	}
+
+	if err := validation.RequestLoginCode(request); err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	s.log.InfoContext(ctx, "RequestLoginCode called",
+		"email", request.GetEmail(),
+		"delivery", request.GetDelivery(),
+	)
+
+	resp, err := s.service.RequestLoginCode(ctx, request)
+	if err != nil {
+		s.log.ErrorContext(ctx, "RequestLoginCode failed")
+		return nil, statusError(ctx, err)
+	}
+
+	return resp, nil
+}
+
+// CompleteLoginCode exchanges a sign-in code for tokens.
+func (s *Server) CompleteLoginCode(ctx context.Context, request *authorizationservicev1.CompleteLoginCodeRequest) (*authorizationservicev1.CompleteLoginCodeResponse, error) {
+	if request == nil {
+		return nil, statusError(ctx, errNilRequest)
+	}
+
+	if err := validation.CompleteLoginCode(request); err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	s.log.InfoContext(ctx, "CompleteLoginCode called",
+		"client_id", request.GetClientId(),
+	)
+
+	resp, err := s.service.CompleteLoginCode(ctx, request)
+	if err != nil {
+		s.log.ErrorContext(ctx, "CompleteLoginCode failed")
+		return nil, statusError(ctx, err)
 	}
 
 	return resp, nil
@@ -0,0 +1,56 @@
+package jwks
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/lib/jwt"
+)
+
+// Path is the conventional location of the key set.
+const Path = "/.well-known/jwks.json"
+
+// KeySet publishes public keys access tokens are verified with.
+type KeySet interface {
+	JWKS() jwt.JWKS
+}
+
+// Handler serves the JSON Web Key Set.
+type Handler struct {
+	log    *slog.Logger
+	keys   KeySet
+	maxAge time.Duration
+}
+
+// NewHandler constructs a JWKS handler. maxAge is announced to clients
+// in Cache-Control; it must not exceed the time a new key is published
+// before it is used for signing.
+func NewHandler(log *slog.Logger, keys KeySet, maxAge time.Duration) *Handler {
+	return &Handler{
+		log:    log,
+		keys:   keys,
+		maxAge: maxAge,
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := json.Marshal(h.keys.JWKS())
+	if err != nil {
+		h.log.ErrorContext(r.Context(), "failed to encode JWKS", slog.Any("err", err))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(h.maxAge.Seconds())))
+	_, _ = w.Write(body)
+}
@@ -0,0 +1,123 @@
+// Package clientip resolves the IP address of a caller behind
+// reverse proxies. Forwarding headers are set by whoever sends the
+// request, so they are only believed when the request comes from
+// a trusted proxy.
+package clientip
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// Resolver picks the client IP from the peer address and forwarding headers.
+type Resolver struct {
+	trusted []netip.Prefix
+}
+
+// NewResolver creates a resolver trusting proxies in the given CIDR
+// ranges or single addresses. Without proxies only the peer address is used.
+func NewResolver(trustedProxies []string) (*Resolver, error) {
+	r := &Resolver{trusted: make([]netip.Prefix, 0, len(trustedProxies))}
+
+	for _, s := range trustedProxies {
+		var (
+			prefix netip.Prefix
+			err    error
+		)
+		if strings.Contains(s, "/") {
+			prefix, err = netip.ParsePrefix(s)
+		} else {
+			var addr netip.Addr
+			addr, err = netip.ParseAddr(s)
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		if err != nil {
+			return nil, fmt.Errorf("clientip: invalid trusted proxy %q: %w", s, err)
+		}
+		r.trusted = append(r.trusted, prefix.Masked())
+	}
+
+	return r, nil
+}
+
+// MustNewResolver is like NewResolver but panics on error.
+// Used in the application's startup layer.
+func MustNewResolver(trustedProxies []string) *Resolver {
+	r, err := NewResolver(trustedProxies)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// ClientIP returns the client address. peer is the address of the
+// connection, forwardedFor the X-Forwarded-For values in order and
+// realIP the X-Real-IP value.
+//
+// When peer is a trusted proxy, X-Forwarded-For is walked from the
+// right and the first hop which is not a trusted proxy is the client:
+// hops to the left of it are written by the client and may be forged.
+// X-Real-IP is used when a trusted proxy sends no X-Forwarded-For.
+// In any other case the peer itself is the client.
+func (r *Resolver) ClientIP(peer string, forwardedFor []string, realIP string) string {
+	peerAddr, ok := parseAddr(peer)
+	if !ok {
+		return ""
+	}
+	if !r.isTrusted(peerAddr) {
+		return peerAddr.String()
+	}
+
+	var hops []string
+	for _, v := range forwardedFor {
+		hops = append(hops, strings.Split(v, ",")...)
+	}
+
+	client := peerAddr
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, ok := parseAddr(hops[i])
+		if !ok {
+			// A malformed hop can not be trusted further.
+			break
+		}
+		client = addr
+		if !r.isTrusted(addr) {
+			return client.String()
+		}
+	}
+
+	if len(hops) == 0 {
+		if addr, ok := parseAddr(realIP); ok {
+			return addr.String()
+		}
+	}
+
+	return client.String()
+}
+
+func (r *Resolver) isTrusted(addr netip.Addr) bool {
+	for _, p := range r.trusted {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseAddr parses an IP address with an optional port.
+func parseAddr(s string) (netip.Addr, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return netip.Addr{}, false
+	}
+
+	if ap, err := netip.ParseAddrPort(s); err == nil {
+		return ap.Addr().Unmap(), true
+	}
+	addr, err := netip.ParseAddr(strings.Trim(s, "[]"))
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
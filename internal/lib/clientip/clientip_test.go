@@ -0,0 +1,96 @@
+package clientip
+
+import "testing"
+
+func TestResolverClientIP(t *testing.T) {
+	r := MustNewResolver([]string{"10.0.0.0/8", "192.168.1.1"})
+
+	tests := []struct {
+		name         string
+		resolver     *Resolver
+		peer         string
+		forwardedFor []string
+		realIP       string
+		want         string
+	}{
+		{
+			name:         "no trusted proxies ignores headers",
+			resolver:     MustNewResolver(nil),
+			peer:         "1.2.3.4:5000",
+			forwardedFor: []string{"9.9.9.9"},
+			realIP:       "8.8.8.8",
+			want:         "1.2.3.4",
+		},
+		{
+			name:         "untrusted peer ignores headers",
+			resolver:     r,
+			peer:         "1.2.3.4:5000",
+			forwardedFor: []string{"9.9.9.9"},
+			want:         "1.2.3.4",
+		},
+		{
+			name:         "right-most untrusted hop",
+			resolver:     r,
+			peer:         "10.1.1.1:5000",
+			forwardedFor: []string{"6.6.6.6, 9.9.9.9, 10.2.2.2"},
+			want:         "9.9.9.9",
+		},
+		{
+			name:         "several header values",
+			resolver:     r,
+			peer:         "10.1.1.1:5000",
+			forwardedFor: []string{"6.6.6.6", "9.9.9.9,192.168.1.1"},
+			want:         "9.9.9.9",
+		},
+		{
+			name:     "real ip without forwarded for",
+			resolver: r,
+			peer:     "10.1.1.1:5000",
+			realIP:   "7.7.7.7",
+			want:     "7.7.7.7",
+		},
+		{
+			name:         "malformed hop stops the walk",
+			resolver:     r,
+			peer:         "10.1.1.1:5000",
+			forwardedFor: []string{"garbage, 10.3.3.3"},
+			want:         "10.3.3.3",
+		},
+		{
+			name:         "ipv4-mapped peer and ipv6 client",
+			resolver:     r,
+			peer:         "[::ffff:10.1.1.1]:5000",
+			forwardedFor: []string{"2001:db8::1"},
+			want:         "2001:db8::1",
+		},
+		{
+			name:     "peer without port",
+			resolver: r,
+			peer:     "1.2.3.4",
+			want:     "1.2.3.4",
+		},
+		{
+			name:     "no peer",
+			resolver: r,
+			want:     "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.resolver.ClientIP(tt.peer, tt.forwardedFor, tt.realIP)
+			if got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewResolverInvalidProxy(t *testing.T) {
+	if _, err := NewResolver([]string{"10.0.0.0/33"}); err == nil {
+		t.Error("NewResolver() accepted an invalid prefix")
+	}
+	if _, err := NewResolver([]string{"proxy.local"}); err == nil {
+		t.Error("NewResolver() accepted a host name")
+	}
+}
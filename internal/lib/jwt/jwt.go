@@ -0,0 +1,147 @@
+package jwt
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/config"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+)
+
+// ErrInvalidToken is returned when a token cannot be parsed or fails validation.
+var ErrInvalidToken = errors.New("invalid token")
+
+// Claims is a set of claims carried by access tokens.
+// Subject holds the user ID, ClientID identifies the client the token was issued to
+// and SessionID is the refresh token family the token was obtained from.
+// Scope is a space-separated list of granted scopes.
+type Claims struct {
+	gojwt.RegisteredClaims
+	ClientID  string `json:"client_id,omitempty"`
+	SessionID string `json:"sid,omitempty"`
+	Scope     string `json:"scope,omitempty"`
+}
+
+// UserID returns the numeric user ID stored in the "sub" claim.
+func (c *Claims) UserID() (int64, error) {
+	return strconv.ParseInt(c.Subject, 10, 64)
+}
+
+// KeyStore provides keys for signing and verifying tokens.
+type KeyStore interface {
+	// SigningKey returns the key new tokens are signed with.
+	SigningKey() (*Key, error)
+
+	// VerificationKey returns a published key by its ID.
+	VerificationKey(kid string) (*Key, error)
+}
+
+// Issuer signs and verifies access tokens.
+type Issuer struct {
+	keys     KeyStore
+	issuer   string
+	audience string
+	ttl      time.Duration
+}
+
+// NewIssuer creates a token issuer based on application config.
+func NewIssuer(cfg config.TokenConfig, keys KeyStore) *Issuer {
+	return &Issuer{
+		keys:     keys,
+		issuer:   cfg.Issuer,
+		audience: cfg.Audience,
+		ttl:      cfg.AccessTTL,
+	}
+}
+
+// TTL returns lifetime of issued access tokens.
+func (i *Issuer) TTL() time.Duration {
+	return i.ttl
+}
+
+// NewAccessToken issues a signed access token for the given user, client and session.
+// scope is a space-separated list of granted scopes.
+func (i *Issuer) NewAccessToken(userID int64, clientID, sessionID, scope string) (string, time.Time, error) {
+	const op = "jwt.NewAccessToken"
+
+	key, err := i.keys.SigningKey()
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	jti, err := newTokenID()
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(i.ttl)
+
+	claims := Claims{
+		RegisteredClaims: gojwt.RegisteredClaims{
+			ID:        jti,
+			Issuer:    i.issuer,
+			Subject:   strconv.FormatInt(userID, 10),
+			Audience:  gojwt.ClaimStrings{i.audience},
+			IssuedAt:  gojwt.NewNumericDate(now),
+			NotBefore: gojwt.NewNumericDate(now),
+			ExpiresAt: gojwt.NewNumericDate(expiresAt),
+		},
+		ClientID:  clientID,
+		SessionID: sessionID,
+		Scope:     scope,
+	}
+
+	token := gojwt.NewWithClaims(key.method(), claims)
+	token.Header["kid"] = key.ID
+
+	signed, err := token.SignedString(key.Private)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return signed, expiresAt, nil
+}
+
+// Parse verifies token signature, issuer, audience and expiry and returns its claims.
+func (i *Issuer) Parse(token string) (*Claims, error) {
+	var claims Claims
+
+	_, err := gojwt.ParseWithClaims(token, &claims,
+		func(t *gojwt.Token) (any, error) {
+			kid, _ := t.Header["kid"].(string)
+
+			key, err := i.keys.VerificationKey(kid)
+			if err != nil {
+				return nil, err
+			}
+			if t.Method.Alg() != key.Algorithm {
+				return nil, fmt.Errorf("key %s does not match algorithm %s", kid, t.Method.Alg())
+			}
+
+			return key.Public, nil
+		},
+		gojwt.WithValidMethods(supportedAlgorithms),
+		gojwt.WithIssuer(i.issuer),
+		gojwt.WithAudience(i.audience),
+		gojwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	return &claims, nil
+}
+
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
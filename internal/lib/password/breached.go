@@ -0,0 +1,71 @@
+package password
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Breached looks passwords up in a local breached password corpus split
+// into files by the first 5 hex digits of the SHA-1 hash, so a lookup
+// reads one small file and works without network access.
+type Breached struct {
+	dir      string
+	minCount int
+}
+
+// NewBreached checks that dir exists and returns a lookup over it.
+func NewBreached(dir string, minCount int) (*Breached, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached passwords: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("breached passwords: %s is not a directory", dir)
+	}
+
+	return &Breached{dir: dir, minCount: max(minCount, 1)}, nil
+}
+
+// Contains reports whether the password has been seen in breaches
+// at least minCount times.
+func (b *Breached) Contains(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	f, err := os.Open(filepath.Join(b.dir, prefix+".txt"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to open breached passwords: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lineSuffix, count, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !ok || !strings.EqualFold(lineSuffix, suffix) {
+			continue
+		}
+
+		n, err := strconv.Atoi(count)
+		if err != nil {
+			return false, fmt.Errorf("malformed breached passwords file %s.txt: %w", prefix, err)
+		}
+		return n >= b.minCount, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return false, fmt.Errorf("failed to read breached passwords: %w", err)
+	}
+
+	return false, nil
+}
@@ -0,0 +1,307 @@
+package password
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/config"
+)
+
+var (
+	// ErrMalformedHash is returned for stored hashes of unknown format.
+	ErrMalformedHash = errors.New("malformed password hash")
+
+	// ErrUnknownPepper is returned for hashes made with a pepper which is
+	// neither current nor one of the old peppers. The password can not
+	// be checked; the user has to reset it.
+	ErrUnknownPepper = errors.New("password hash uses an unknown pepper, password reset needed")
+)
+
+// dummyPassword is hashed once to have something to compare against
+// when the user does not exist or has no password.
+const dummyPassword = "dummy-password-for-timing-equalization"
+
+// Hasher hashes new passwords with argon2id and verifies both argon2id
+// and legacy bcrypt hashes. The scheme is detected from the stored hash.
+//
+// Argon2id hashes use the PHC string format. Peppered hashes carry the
+// pepper ID as an extra "k" parameter:
+//
+//	$argon2id$v=19$m=65536,t=3,p=2,k=1$<salt>$<key>
+type Hasher struct {
+	params   config.Argon2Config
+	pepper   []byte
+	pepperID string
+	// oldPeppers verify hashes made before the pepper was replaced.
+	oldPeppers map[string][]byte
+
+	dummy       string
+	dummyBcrypt []byte
+}
+
+// NewHasher creates a hasher; the pepper is optional.
+func NewHasher(cfg config.PasswordHashConfig) (*Hasher, error) {
+	h := &Hasher{
+		params:   cfg.Argon2,
+		pepperID: cfg.PepperID,
+	}
+
+	if h.params.Memory == 0 || h.params.Iterations == 0 || h.params.Parallelism == 0 ||
+		h.params.SaltLength == 0 || h.params.KeyLength == 0 {
+		return nil, errors.New("argon2 parameters must be positive")
+	}
+
+	if cfg.Pepper != "" {
+		pepper, err := base64.StdEncoding.DecodeString(cfg.Pepper)
+		if err != nil {
+			return nil, fmt.Errorf("invalid password pepper: %w", err)
+		}
+		if len(pepper) < 16 {
+			return nil, errors.New("password pepper must be at least 16 bytes")
+		}
+		if h.pepperID == "" || strings.ContainsAny(h.pepperID, ",$=") {
+			return nil, errors.New("password pepper id must be set and must not contain ',', '$' or '='")
+		}
+		h.pepper = pepper
+	}
+
+	oldPeppers, err := parseOldPeppers(cfg.OldPeppers)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := oldPeppers[h.pepperID]; ok && h.pepper != nil {
+		return nil, fmt.Errorf("old password pepper reuses the current pepper id %q", h.pepperID)
+	}
+	h.oldPeppers = oldPeppers
+
+	dummy, err := h.Hash(dummyPassword)
+	if err != nil {
+		return nil, err
+	}
+	h.dummy = dummy
+
+	h.dummyBcrypt, err = bcrypt.GenerateFromPassword([]byte(dummyPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+// parseOldPeppers parses "id:base64,id:base64".
+func parseOldPeppers(value string) (map[string][]byte, error) {
+	peppers := make(map[string][]byte)
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		id, encoded, found := strings.Cut(entry, ":")
+		if !found || id == "" || strings.ContainsAny(id, ",$=") {
+			return nil, errors.New(`old password peppers must be "id:base64" pairs separated by ','`)
+		}
+
+		pepper, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid old password pepper %q: %w", id, err)
+		}
+		peppers[id] = pepper
+	}
+
+	return peppers, nil
+}
+
+// MustNewHasher is like NewHasher but panics on error.
+func MustNewHasher(cfg config.PasswordHashConfig) *Hasher {
+	h, err := NewHasher(cfg)
+	if err != nil {
+		panic(err)
+	}
+	return h
+}
+
+// Hash hashes the password with argon2id and current parameters.
+func (h *Hasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	pepperID := h.currentPepperID()
+
+	key := argon2.IDKey(peppered(h.pepper, password), salt,
+		h.params.Iterations, h.params.Memory, h.params.Parallelism, h.params.KeyLength)
+
+	params := fmt.Sprintf("m=%d,t=%d,p=%d", h.params.Memory, h.params.Iterations, h.params.Parallelism)
+	if pepperID != "" {
+		params += ",k=" + pepperID
+	}
+
+	return fmt.Sprintf("$argon2id$v=%d$%s$%s$%s",
+		argon2.Version,
+		params,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify reports whether password matches hash and whether the hash
+// should be replaced with a fresh one: it is bcrypt, uses weaker argon2
+// parameters than configured or lacks the current pepper.
+//
+// Every call does the work of one argon2id and one bcrypt check, so
+// unknown users, argon2id users and legacy bcrypt users all take the
+// same time: the scheme the hash does not use is checked against
+// a dummy hash. An empty hash checks both dummies.
+func (h *Hasher) Verify(hash, password string) (ok, rehash bool, err error) {
+	switch {
+	case hash == "":
+		h.burnArgon2(password)
+		h.burnBcrypt(password)
+		return false, false, nil
+	case strings.HasPrefix(hash, "$argon2id$"):
+		defer h.burnBcrypt(password)
+		return h.verifyArgon2(hash, password)
+	case strings.HasPrefix(hash, "$2a$"), strings.HasPrefix(hash, "$2b$"), strings.HasPrefix(hash, "$2y$"):
+		defer h.burnArgon2(password)
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, false, nil
+		}
+		if err != nil {
+			return false, false, err
+		}
+		return true, true, nil
+	default:
+		h.burnArgon2(password)
+		h.burnBcrypt(password)
+		return false, false, ErrMalformedHash
+	}
+}
+
+// burnArgon2 and burnBcrypt check the password against dummy hashes
+// only to spend the time a real check takes.
+func (h *Hasher) burnArgon2(password string) {
+	_, _, _ = h.verifyArgon2(h.dummy, password)
+}
+
+func (h *Hasher) burnBcrypt(password string) {
+	_ = bcrypt.CompareHashAndPassword(h.dummyBcrypt, []byte(password))
+}
+
+func (h *Hasher) verifyArgon2(hash, password string) (bool, bool, error) {
+	// "", "argon2id", "v=19", params, salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return false, false, ErrMalformedHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, false, ErrMalformedHash
+	}
+
+	var (
+		memory, iterations uint32
+		parallelism        uint8
+		pepperID           string
+	)
+	for _, param := range strings.Split(parts[3], ",") {
+		name, value, _ := strings.Cut(param, "=")
+		var err error
+		switch name {
+		case "m":
+			_, err = fmt.Sscan(value, &memory)
+		case "t":
+			_, err = fmt.Sscan(value, &iterations)
+		case "p":
+			_, err = fmt.Sscan(value, &parallelism)
+		case "k":
+			pepperID = value
+		default:
+			err = ErrMalformedHash
+		}
+		if err != nil {
+			return false, false, ErrMalformedHash
+		}
+	}
+	if memory == 0 || iterations == 0 || parallelism == 0 {
+		return false, false, ErrMalformedHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, false, ErrMalformedHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return false, false, ErrMalformedHash
+	}
+
+	pepper, err := h.pepperByID(pepperID)
+	if err != nil {
+		// Spend the time of a check anyway, see Verify.
+		_ = argon2.IDKey([]byte(password), salt, iterations, memory, parallelism, uint32(len(key)))
+		return false, false, err
+	}
+
+	actual := argon2.IDKey(peppered(pepper, password), salt, iterations, memory, parallelism, uint32(len(key)))
+	if subtle.ConstantTimeCompare(actual, key) != 1 {
+		return false, false, nil
+	}
+
+	rehash := memory < h.params.Memory ||
+		iterations < h.params.Iterations ||
+		parallelism < h.params.Parallelism ||
+		uint32(len(salt)) < h.params.SaltLength ||
+		uint32(len(key)) < h.params.KeyLength ||
+		pepperID != h.currentPepperID()
+
+	return true, rehash, nil
+}
+
+// currentPepperID is the pepper ID new hashes carry, empty without a pepper.
+func (h *Hasher) currentPepperID() string {
+	if h.pepper == nil {
+		return ""
+	}
+	return h.pepperID
+}
+
+// pepperByID returns the pepper a hash was made with: none, the current
+// one or an old one.
+func (h *Hasher) pepperByID(pepperID string) ([]byte, error) {
+	switch {
+	case pepperID == "":
+		return nil, nil
+	case h.pepper != nil && pepperID == h.pepperID:
+		return h.pepper, nil
+	}
+
+	if pepper, ok := h.oldPeppers[pepperID]; ok {
+		return pepper, nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownPepper, pepperID)
+}
+
+// peppered mixes the pepper into the password; a nil pepper leaves it as is.
+func peppered(pepper []byte, password string) []byte {
+	if pepper == nil {
+		return []byte(password)
+	}
+
+	mac := hmac.New(sha256.New, pepper)
+	mac.Write([]byte(password))
+	return mac.Sum(nil)
+}
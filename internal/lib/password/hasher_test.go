@@ -0,0 +1,211 @@
+package password
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/config"
+)
+
+var testArgon2 = config.Argon2Config{
+	Memory:      1024,
+	Iterations:  1,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+func testPepper(b byte) string {
+	return base64.StdEncoding.EncodeToString([]byte(strings.Repeat(string(b), 32)))
+}
+
+func newTestHasher(t *testing.T, cfg config.PasswordHashConfig) *Hasher {
+	t.Helper()
+
+	if cfg.Argon2 == (config.Argon2Config{}) {
+		cfg.Argon2 = testArgon2
+	}
+	h, err := NewHasher(cfg)
+	if err != nil {
+		t.Fatalf("NewHasher() error = %v", err)
+	}
+	return h
+}
+
+func mustHash(t *testing.T, h *Hasher, password string) string {
+	t.Helper()
+
+	hash, err := h.Hash(password)
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	return hash
+}
+
+func TestHasherVerify(t *testing.T) {
+	plain := newTestHasher(t, config.PasswordHashConfig{})
+	peppered := newTestHasher(t, config.PasswordHashConfig{PepperID: "2", Pepper: testPepper('b')})
+	oldPeppered := newTestHasher(t, config.PasswordHashConfig{PepperID: "1", Pepper: testPepper('a')})
+	rotated := newTestHasher(t, config.PasswordHashConfig{
+		PepperID:   "2",
+		Pepper:     testPepper('b'),
+		OldPeppers: "1:" + testPepper('a'),
+	})
+	stronger := newTestHasher(t, config.PasswordHashConfig{
+		Argon2: config.Argon2Config{Memory: 2048, Iterations: 2, Parallelism: 1, SaltLength: 16, KeyLength: 32},
+	})
+
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		hasher     *Hasher
+		hash       string
+		password   string
+		wantOK     bool
+		wantRehash bool
+		wantErr    error
+	}{
+		{
+			name:     "argon2 match",
+			hasher:   plain,
+			hash:     mustHash(t, plain, "secret"),
+			password: "secret",
+			wantOK:   true,
+		},
+		{
+			name:     "argon2 mismatch",
+			hasher:   plain,
+			hash:     mustHash(t, plain, "secret"),
+			password: "wrong",
+		},
+		{
+			name:       "weaker parameters are rehashed",
+			hasher:     stronger,
+			hash:       mustHash(t, plain, "secret"),
+			password:   "secret",
+			wantOK:     true,
+			wantRehash: true,
+		},
+		{
+			name:       "legacy bcrypt is rehashed",
+			hasher:     plain,
+			hash:       string(bcryptHash),
+			password:   "secret",
+			wantOK:     true,
+			wantRehash: true,
+		},
+		{
+			name:     "legacy bcrypt mismatch",
+			hasher:   plain,
+			hash:     string(bcryptHash),
+			password: "wrong",
+		},
+		{
+			name:     "current pepper",
+			hasher:   peppered,
+			hash:     mustHash(t, peppered, "secret"),
+			password: "secret",
+			wantOK:   true,
+		},
+		{
+			name:       "unpeppered hash gets the pepper",
+			hasher:     peppered,
+			hash:       mustHash(t, plain, "secret"),
+			password:   "secret",
+			wantOK:     true,
+			wantRehash: true,
+		},
+		{
+			name:       "old pepper falls back and is rehashed",
+			hasher:     rotated,
+			hash:       mustHash(t, oldPeppered, "secret"),
+			password:   "secret",
+			wantOK:     true,
+			wantRehash: true,
+		},
+		{
+			name:     "unknown pepper",
+			hasher:   peppered,
+			hash:     mustHash(t, oldPeppered, "secret"),
+			password: "secret",
+			wantErr:  ErrUnknownPepper,
+		},
+		{
+			name:     "empty hash never matches",
+			hasher:   plain,
+			hash:     "",
+			password: "",
+		},
+		{
+			name:     "malformed hash",
+			hasher:   plain,
+			hash:     "$md5$abc",
+			password: "secret",
+			wantErr:  ErrMalformedHash,
+		},
+		{
+			name:     "truncated argon2 hash",
+			hasher:   plain,
+			hash:     "$argon2id$v=19$m=1024,t=1,p=1$c2FsdA",
+			password: "secret",
+			wantErr:  ErrMalformedHash,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, rehash, err := tt.hasher.Verify(tt.hash, tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+			if ok != tt.wantOK || rehash != tt.wantRehash {
+				t.Errorf("Verify() = (%v, %v), want (%v, %v)", ok, rehash, tt.wantOK, tt.wantRehash)
+			}
+		})
+	}
+}
+
+func TestHasherHashFormat(t *testing.T) {
+	h := newTestHasher(t, config.PasswordHashConfig{PepperID: "7", Pepper: testPepper('c')})
+
+	hash := mustHash(t, h, "secret")
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1,k=7$") {
+		t.Errorf("Hash() = %q, want argon2id PHC string with pepper id 7", hash)
+	}
+	if other := mustHash(t, h, "secret"); other == hash {
+		t.Error("Hash() returned the same hash twice, salt is not random")
+	}
+}
+
+func TestNewHasherConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.PasswordHashConfig
+	}{
+		{name: "short pepper", cfg: config.PasswordHashConfig{PepperID: "1", Pepper: base64.StdEncoding.EncodeToString([]byte("short"))}},
+		{name: "pepper without id", cfg: config.PasswordHashConfig{Pepper: testPepper('a')}},
+		{name: "malformed old peppers", cfg: config.PasswordHashConfig{OldPeppers: "no-separator"}},
+		{name: "old pepper reuses current id", cfg: config.PasswordHashConfig{PepperID: "1", Pepper: testPepper('a'), OldPeppers: "1:" + testPepper('b')}},
+		{name: "zero argon2 parameters", cfg: config.PasswordHashConfig{Argon2: config.Argon2Config{Memory: 1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			if cfg.Argon2 == (config.Argon2Config{}) {
+				cfg.Argon2 = testArgon2
+			}
+			if _, err := NewHasher(cfg); err == nil {
+				t.Error("NewHasher() accepted an invalid config")
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package password
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/config"
+)
+
+// Rules a password is checked against.
+const (
+	RuleMinLength  = "min_length"
+	RuleMaxLength  = "max_length"
+	RuleMinClasses = "min_classes"
+	RuleIdentity   = "identity"
+	RuleBlocklist  = "blocklist"
+	RuleBreached   = "breached"
+)
+
+// Violation is a rule the password does not satisfy.
+type Violation struct {
+	Rule    string
+	Message string
+}
+
+// Owner is who the password belongs to; it must not be part of the password.
+type Owner struct {
+	Email string
+	Login string
+}
+
+// Policy checks new passwords.
+type Policy struct {
+	cfg       config.PasswordPolicyConfig
+	blocklist map[string]struct{}
+	breached  *Breached
+}
+
+// NewPolicy creates a policy and loads the blocklist file if configured.
+func NewPolicy(cfg config.PasswordPolicyConfig) (*Policy, error) {
+	p := &Policy{
+		cfg:       cfg,
+		blocklist: make(map[string]struct{}, len(cfg.Blocklist)),
+	}
+
+	for _, pw := range cfg.Blocklist {
+		p.blocklist[strings.ToLower(pw)] = struct{}{}
+	}
+
+	if cfg.BlocklistFile != "" {
+		if err := p.loadBlocklist(cfg.BlocklistFile); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.Breached.Enabled {
+		breached, err := NewBreached(cfg.Breached.Dir, cfg.Breached.MinCount)
+		if err != nil {
+			return nil, err
+		}
+		p.breached = breached
+	}
+
+	return p, nil
+}
+
+// MustNewPolicy is like NewPolicy but panics on error.
+func MustNewPolicy(cfg config.PasswordPolicyConfig) *Policy {
+	p, err := NewPolicy(cfg)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// Check returns every rule the password violates. The error is only
+// returned when the breached password corpus can not be read.
+func (p *Policy) Check(password string, owner Owner) ([]Violation, error) {
+	var violations []Violation
+
+	if n := utf8.RuneCountInString(password); n < p.cfg.MinLength {
+		violations = append(violations, Violation{
+			Rule:    RuleMinLength,
+			Message: fmt.Sprintf("password must be at least %d characters long", p.cfg.MinLength),
+		})
+	}
+
+	if p.cfg.MaxLength > 0 && len(password) > p.cfg.MaxLength {
+		violations = append(violations, Violation{
+			Rule:    RuleMaxLength,
+			Message: fmt.Sprintf("password must be at most %d bytes long", p.cfg.MaxLength),
+		})
+	}
+
+	if classes := characterClasses(password); classes < p.cfg.MinClasses {
+		violations = append(violations, Violation{
+			Rule: RuleMinClasses,
+			Message: fmt.Sprintf("password must contain at least %d of: lower case letters, "+
+				"upper case letters, digits, symbols", p.cfg.MinClasses),
+		})
+	}
+
+	if p.cfg.DisallowIdentity && containsIdentity(password, owner) {
+		violations = append(violations, Violation{
+			Rule:    RuleIdentity,
+			Message: "password must not contain the email or login",
+		})
+	}
+
+	if _, ok := p.blocklist[strings.ToLower(password)]; ok {
+		violations = append(violations, Violation{
+			Rule:    RuleBlocklist,
+			Message: "password is too common",
+		})
+	}
+
+	if p.breached != nil {
+		breached, err := p.breached.Contains(password)
+		if err != nil {
+			return nil, err
+		}
+		if breached {
+			violations = append(violations, Violation{
+				Rule:    RuleBreached,
+				Message: "password has appeared in a data breach",
+			})
+		}
+	}
+
+	return violations, nil
+}
+
+func (p *Policy) loadBlocklist(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open password blocklist: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			p.blocklist[strings.ToLower(line)] = struct{}{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read password blocklist: %w", err)
+	}
+
+	return nil
+}
+
+// characterClasses counts which of lower case, upper case, digits
+// and other characters occur in the password.
+func characterClasses(password string) int {
+	var lower, upper, digit, other bool
+
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			other = true
+		}
+	}
+
+	n := 0
+	for _, ok := range []bool{lower, upper, digit, other} {
+		if ok {
+			n++
+		}
+	}
+	return n
+}
+
+// containsIdentity reports whether the password contains the login,
+// the email or its local part. Parts shorter than 3 characters are
+// ignored, as they would reject too many passwords.
+func containsIdentity(password string, owner Owner) bool {
+	password = strings.ToLower(password)
+
+	local, _, _ := strings.Cut(owner.Email, "@")
+	for _, part := range []string{owner.Email, local, owner.Login} {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if utf8.RuneCountInString(part) >= 3 && strings.Contains(password, part) {
+			return true
+		}
+	}
+
+	return false
+}
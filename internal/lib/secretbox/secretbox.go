@@ -0,0 +1,89 @@
+// Package secretbox encrypts small secrets stored in the database
+// with AES-256-GCM.
+package secretbox
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// KeySize is a required key length in bytes.
+const KeySize = 32
+
+// ErrDecrypt is returned when a ciphertext is malformed or has been tampered with.
+var ErrDecrypt = errors.New("secretbox: decryption failed")
+
+// Box seals and opens secrets with a single key.
+type Box struct {
+	aead cipher.AEAD
+}
+
+// New creates a Box from a 32-byte key.
+func New(key []byte) (*Box, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("secretbox: key must be %d bytes, got %d", KeySize, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Box{aead: aead}, nil
+}
+
+// NewFromBase64 creates a Box from a standard base64 encoded key,
+// as it is passed through environment variables.
+func NewFromBase64(key string) (*Box, error) {
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("secretbox: decode key: %w", err)
+	}
+	return New(raw)
+}
+
+// MustNewFromBase64 is like NewFromBase64 but panics on error.
+// Used in the application's startup layer.
+func MustNewFromBase64(key string) *Box {
+	box, err := NewFromBase64(key)
+	if err != nil {
+		panic(err)
+	}
+	return box
+}
+
+// Seal encrypts plaintext. additionalData binds the ciphertext to its
+// owner (e.g. a user ID), so it can not be moved to another record.
+// The result is nonce || ciphertext.
+func (b *Box) Seal(plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, b.aead.NonceSize(), b.aead.NonceSize()+len(plaintext)+b.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return b.aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// Open decrypts a value produced by Seal with the same additionalData.
+func (b *Box) Open(sealed, additionalData []byte) ([]byte, error) {
+	n := b.aead.NonceSize()
+	if len(sealed) < n {
+		return nil, ErrDecrypt
+	}
+
+	plaintext, err := b.aead.Open(nil, sealed[:n], sealed[n:], additionalData)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+
+	return plaintext, nil
+}
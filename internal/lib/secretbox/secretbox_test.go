@@ -0,0 +1,90 @@
+package secretbox
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func newTestBox(t *testing.T, b byte) *Box {
+	t.Helper()
+
+	box, err := New(bytes.Repeat([]byte{b}, KeySize))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return box
+}
+
+func TestBoxOpen(t *testing.T) {
+	box := newTestBox(t, 1)
+
+	sealed, err := box.Seal([]byte("secret"), []byte("user:1"))
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+
+	tampered := bytes.Clone(sealed)
+	tampered[len(tampered)-1] ^= 1
+
+	tests := []struct {
+		name    string
+		box     *Box
+		sealed  []byte
+		ad      string
+		wantErr bool
+	}{
+		{name: "same key and owner", box: box, sealed: sealed, ad: "user:1"},
+		{name: "other owner", box: box, sealed: sealed, ad: "user:2", wantErr: true},
+		{name: "other key", box: newTestBox(t, 2), sealed: sealed, ad: "user:1", wantErr: true},
+		{name: "tampered", box: box, sealed: tampered, ad: "user:1", wantErr: true},
+		{name: "shorter than nonce", box: box, sealed: sealed[:4], ad: "user:1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plaintext, err := tt.box.Open(tt.sealed, []byte(tt.ad))
+			if tt.wantErr {
+				if !errors.Is(err, ErrDecrypt) {
+					t.Fatalf("Open() error = %v, want ErrDecrypt", err)
+				}
+				return
+			}
+			if err != nil || string(plaintext) != "secret" {
+				t.Errorf("Open() = (%q, %v), want (\"secret\", nil)", plaintext, err)
+			}
+		})
+	}
+}
+
+func TestBoxSealNonce(t *testing.T) {
+	box := newTestBox(t, 1)
+
+	a, _ := box.Seal([]byte("secret"), nil)
+	b, _ := box.Seal([]byte("secret"), nil)
+	if bytes.Equal(a, b) {
+		t.Error("Seal() returned the same ciphertext twice, nonce is not random")
+	}
+}
+
+func TestNewFromBase64(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{name: "32 bytes", key: base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, KeySize))},
+		{name: "16 bytes", key: base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 16)), wantErr: true},
+		{name: "not base64", key: "not base64!", wantErr: true},
+		{name: "empty", key: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewFromBase64(tt.key); (err != nil) != tt.wantErr {
+				t.Errorf("NewFromBase64() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
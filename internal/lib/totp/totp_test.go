@@ -0,0 +1,108 @@
+package totp
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 secret of the RFC 6238 test vectors.
+var rfcSecret = []byte("12345678901234567890")
+
+func TestCode(t *testing.T) {
+	// RFC 6238 appendix B; codes are the last Digits digits of the
+	// 8 digit values there.
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+		{unix: 20000000000, want: "353130"},
+	}
+
+	for _, tt := range tests {
+		got := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+		if got != tt.want {
+			t.Errorf("Code(T=%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	step := Step(now)
+
+	tests := []struct {
+		name     string
+		code     string
+		wantStep int64
+		wantOK   bool
+	}{
+		{name: "current step", code: Code(rfcSecret, step), wantStep: step, wantOK: true},
+		{name: "previous step", code: Code(rfcSecret, step-1), wantStep: step - 1, wantOK: true},
+		{name: "next step", code: Code(rfcSecret, step+1), wantStep: step + 1, wantOK: true},
+		{name: "outside skew", code: Code(rfcSecret, step-2)},
+		{name: "wrong code", code: "000000"},
+		{name: "short code", code: Code(rfcSecret, step)[1:]},
+		{name: "empty code", code: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStep, ok := Validate(rfcSecret, tt.code, now)
+			if ok != tt.wantOK || gotStep != tt.wantStep {
+				t.Errorf("Validate(%q) = (%d, %v), want (%d, %v)", tt.code, gotStep, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+// TestValidateReplay checks the step returned for replay detection:
+// a code accepted in one step matches the same step in the next one.
+func TestValidateReplay(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	code := Code(rfcSecret, Step(now))
+
+	first, ok := Validate(rfcSecret, code, now)
+	if !ok {
+		t.Fatal("Validate() rejected the current code")
+	}
+	again, ok := Validate(rfcSecret, code, now.Add(Period))
+	if !ok || again != first {
+		t.Errorf("Validate() one period later = (%d, %v), want (%d, true)", again, ok, first)
+	}
+}
+
+func TestSecretRoundTrip(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(secret) != secretSize {
+		t.Fatalf("GenerateSecret() length = %d, want %d", len(secret), secretSize)
+	}
+
+	decoded, err := b32.DecodeString(EncodeSecret(secret))
+	if err != nil || string(decoded) != string(secret) {
+		t.Errorf("EncodeSecret() does not decode back: %v", err)
+	}
+}
+
+func TestURI(t *testing.T) {
+	u, err := url.Parse(URI("CloudStorage", "user@example.com", rfcSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if u.Scheme != "otpauth" || u.Host != "totp" || u.Path != "/CloudStorage:user@example.com" {
+		t.Errorf("URI() = %s, want otpauth://totp/CloudStorage:user@example.com", u)
+	}
+	q := u.Query()
+	if q.Get("secret") != EncodeSecret(rfcSecret) || q.Get("digits") != "6" || q.Get("period") != "30" {
+		t.Errorf("URI() query = %v", q)
+	}
+}
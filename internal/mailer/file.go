@@ -0,0 +1,76 @@
+package mailer
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// FileSender writes every message into a Maildir-like directory instead of
+// delivering it. Messages are written into tmp/ and then atomically moved
+// into new/, so a reader never sees a partially written file.
+// It is meant for development and tests.
+type FileSender struct {
+	dir  string
+	from string
+}
+
+// NewFileSender constructs a file drop sender and creates its directories.
+func NewFileSender(dir, from string) (*FileSender, error) {
+	for _, sub := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o750); err != nil {
+			return nil, fmt.Errorf("failed to create mail directory: %w", err)
+		}
+	}
+
+	return &FileSender{
+		dir:  dir,
+		from: from,
+	}, nil
+}
+
+// Ensure interface implementation at compile time.
+var _ Sender = (*FileSender)(nil)
+
+// Send writes the message to the directory.
+func (s *FileSender) Send(_ context.Context, msg Message) error {
+	const op = "FileSender.Send"
+
+	raw, err := buildMIME(s.from, msg)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	name, err := uniqueName()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	tmpPath := filepath.Join(s.dir, "tmp", name)
+	if err := os.WriteFile(tmpPath, raw, 0o640); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := os.Rename(tmpPath, filepath.Join(s.dir, "new", name)); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// uniqueName returns a Maildir-style file name: <time>.<pid>_<random>.eml
+func uniqueName() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return strconv.FormatInt(time.Now().UnixNano(), 10) + "." +
+		strconv.Itoa(os.Getpid()) + "_" + hex.EncodeToString(b) + ".eml", nil
+}
@@ -0,0 +1,44 @@
+package mailer
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Message is an outbound email. HTML is optional;
+// when set the message is sent as multipart/alternative.
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Sender delivers messages to recipients.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// LogSender does not deliver anything and only logs outgoing messages.
+// Message bodies are logged on debug level, so it is meant for development only.
+type LogSender struct {
+	log *slog.Logger
+}
+
+// NewLogSender constructs a sender that writes messages to the log.
+func NewLogSender(log *slog.Logger) *LogSender {
+	return &LogSender{log: log}
+}
+
+// Ensure interface implementation at compile time.
+var _ Sender = (*LogSender)(nil)
+
+// Send logs the message.
+func (s *LogSender) Send(ctx context.Context, msg Message) error {
+	s.log.InfoContext(ctx, "mail sent",
+		slog.String("to", msg.To),
+		slog.String("subject", msg.Subject),
+	)
+	s.log.DebugContext(ctx, "mail body", slog.String("text", msg.Text))
+	return nil
+}
@@ -0,0 +1,208 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/config"
+)
+
+// ErrQueueFull is returned when the delivery queue can not accept more messages.
+var ErrQueueFull = errors.New("mail queue is full")
+
+// Mailer renders templated emails and delivers them in the background,
+// so a slow mail relay never blocks the caller. Failed deliveries are
+// retried with exponential backoff.
+type Mailer struct {
+	log       *slog.Logger
+	sender    Sender
+	templates *Templates
+	cfg       config.MailQueueConfig
+
+	queue chan Message
+	// ctx is cancelled when Close gives up waiting; it aborts
+	// in-flight sends and retry backoff.
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	mu        sync.RWMutex
+	closed    bool
+	closeOnce sync.Once
+}
+
+// New creates a mailer with the transport selected in config
+// and starts its delivery workers.
+func New(log *slog.Logger, cfg config.MailConfig) (*Mailer, error) {
+	sender, err := NewSender(log, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	templates, err := NewTemplates(cfg.DefaultLocale)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWithSender(log, sender, templates, cfg.Queue), nil
+}
+
+// MustNew creates a mailer and panics on any error.
+// Used in the application's startup layer.
+func MustNew(log *slog.Logger, cfg config.MailConfig) *Mailer {
+	m, err := New(log, cfg)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// NewSender creates a transport based on the configured driver.
+func NewSender(log *slog.Logger, cfg config.MailConfig) (Sender, error) {
+	switch cfg.Driver {
+	case "smtp":
+		return NewSMTPSender(cfg.SMTP, cfg.From)
+	case "file":
+		return NewFileSender(cfg.File.Dir, cfg.From)
+	case "log", "":
+		return NewLogSender(log), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", cfg.Driver)
+	}
+}
+
+// NewWithSender creates a mailer on top of an arbitrary transport
+// and starts its delivery workers.
+func NewWithSender(log *slog.Logger, sender Sender, templates *Templates, cfg config.MailQueueConfig) *Mailer {
+	workers := max(cfg.Workers, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	m := &Mailer{
+		log:       log,
+		sender:    sender,
+		templates: templates,
+		cfg:       cfg,
+		queue:     make(chan Message, max(cfg.Size, 1)),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+
+	m.wg.Add(workers)
+	for range workers {
+		go m.worker()
+	}
+
+	return m
+}
+
+// Send renders the template for the locale and queues the message.
+// Rendering errors are returned immediately; delivery errors are only logged.
+func (m *Mailer) Send(ctx context.Context, to, locale string, name Template, data any) error {
+	const op = "Mailer.Send"
+
+	msg, err := m.templates.Render(name, locale, data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	msg.To = to
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.closed {
+		return fmt.Errorf("%s: mailer is closed", op)
+	}
+
+	select {
+	case m.queue <- msg:
+		return nil
+	default:
+		m.log.WarnContext(ctx, "mail queue is full, message dropped",
+			slog.String("template", string(name)),
+		)
+		return fmt.Errorf("%s: %w", op, ErrQueueFull)
+	}
+}
+
+// Close stops accepting new messages and waits until queued ones are delivered.
+// When ctx is done first, pending deliveries and retries are abandoned
+// and ctx.Err() is returned.
+func (m *Mailer) Close(ctx context.Context) error {
+	m.closeOnce.Do(func() {
+		m.mu.Lock()
+		m.closed = true
+		close(m.queue)
+		m.mu.Unlock()
+	})
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		m.cancel()
+		return nil
+	case <-ctx.Done():
+		m.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (m *Mailer) worker() {
+	defer m.wg.Done()
+
+	for msg := range m.queue {
+		if m.ctx.Err() != nil {
+			m.log.Error("mailer closed, mail dropped",
+				slog.String("subject", msg.Subject),
+			)
+			continue
+		}
+		m.deliver(msg)
+	}
+}
+
+// deliver sends the message, retrying with exponential backoff.
+func (m *Mailer) deliver(msg Message) {
+	backoff := m.cfg.RetryBackoff
+
+	for attempt := 0; ; attempt++ {
+		err := m.sender.Send(m.ctx, msg)
+		if err == nil {
+			return
+		}
+
+		if attempt >= m.cfg.MaxRetries {
+			m.log.Error("failed to deliver mail, giving up",
+				slog.String("subject", msg.Subject),
+				slog.Int("attempts", attempt+1),
+				slog.Any("err", err),
+			)
+			return
+		}
+
+		m.log.Warn("failed to deliver mail, retrying",
+			slog.String("subject", msg.Subject),
+			slog.Int("attempt", attempt+1),
+			slog.Duration("backoff", backoff),
+			slog.Any("err", err),
+		)
+
+		select {
+		case <-time.After(backoff):
+		case <-m.ctx.Done():
+			m.log.Error("mailer closed, mail dropped",
+				slog.String("subject", msg.Subject),
+			)
+			return
+		}
+		backoff *= 2
+	}
+}
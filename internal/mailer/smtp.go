@@ -0,0 +1,133 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/config"
+)
+
+// SMTP TLS modes.
+const (
+	smtpTLSStartTLS = "starttls"
+	smtpTLSImplicit = "tls"
+	smtpTLSNone     = "none"
+)
+
+// SMTPSender delivers messages through an SMTP relay.
+type SMTPSender struct {
+	cfg  config.SMTPConfig
+	from string
+}
+
+// NewSMTPSender constructs an SMTP sender based on application config.
+func NewSMTPSender(cfg config.SMTPConfig, from string) (*SMTPSender, error) {
+	switch cfg.TLS {
+	case smtpTLSStartTLS, smtpTLSImplicit, smtpTLSNone:
+	default:
+		return nil, fmt.Errorf("unknown SMTP TLS mode %q", cfg.TLS)
+	}
+
+	if _, err := mail.ParseAddress(from); err != nil {
+		return nil, fmt.Errorf("invalid from address: %w", err)
+	}
+
+	return &SMTPSender{
+		cfg:  cfg,
+		from: from,
+	}, nil
+}
+
+// Ensure interface implementation at compile time.
+var _ Sender = (*SMTPSender)(nil)
+
+// Send delivers the message. Every call opens a new connection,
+// which is fine for the low volume of transactional mail.
+func (s *SMTPSender) Send(ctx context.Context, msg Message) error {
+	const op = "SMTPSender.Send"
+
+	raw, err := buildMIME(s.from, msg)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if s.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.cfg.Timeout)
+		defer cancel()
+	}
+
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	tlsCfg := &tls.Config{ServerName: s.cfg.Host, MinVersion: tls.VersionTLS12}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	} else {
+		_ = conn.SetDeadline(time.Now().Add(time.Minute))
+	}
+
+	if s.cfg.TLS == smtpTLSImplicit {
+		conn = tls.Client(conn, tlsCfg)
+	}
+
+	c, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer c.Close()
+
+	if s.cfg.TLS == smtpTLSStartTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("%s: server does not support STARTTLS", op)
+		}
+		if err := c.StartTLS(tlsCfg); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if s.cfg.Username != "" {
+		auth := smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)
+		if err := c.Auth(auth); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	fromAddr, _ := mail.ParseAddress(s.from)
+	toAddr, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("%s: invalid recipient address: %w", op, err)
+	}
+
+	if err := c.Mail(fromAddr.Address); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := c.Rcpt(toAddr.Address); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if _, err := w.Write(raw); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return c.Quit()
+}
@@ -0,0 +1,139 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"strings"
+	texttemplate "text/template"
+)
+
+//go:embed templates
+var templatesFS embed.FS
+
+// Template is a name of an email template. Every template consists of
+// "<name>.subject" and "<name>.text" blocks in <locale>/*.txt.tmpl and
+// an optional "<name>.html" block in <locale>/*.html.tmpl.
+type Template string
+
+const (
+	TemplateVerifyEmail   Template = "verify_email"
+	TemplatePasswordReset Template = "password_reset"
+	TemplateLoginCode     Template = "login_code"
+	TemplateLoginLink     Template = "login_link"
+)
+
+// VerificationCodeData is rendered by templates carrying a one-time code.
+type VerificationCodeData struct {
+	Code             string
+	ExpiresInMinutes int
+}
+
+// LinkData is rendered by templates carrying a one-time link.
+type LinkData struct {
+	Link             string
+	ExpiresInMinutes int
+}
+
+// Templates holds parsed email templates for every supported locale.
+type Templates struct {
+	defaultLocale string
+	text          map[string]*texttemplate.Template
+	html          map[string]*htmltemplate.Template
+}
+
+// NewTemplates parses embedded templates. defaultLocale is used when
+// the requested locale is not supported and must itself be supported.
+func NewTemplates(defaultLocale string) (*Templates, error) {
+	locales, err := fs.ReadDir(templatesFS, "templates")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read templates: %w", err)
+	}
+
+	t := &Templates{
+		defaultLocale: defaultLocale,
+		text:          make(map[string]*texttemplate.Template, len(locales)),
+		html:          make(map[string]*htmltemplate.Template, len(locales)),
+	}
+
+	for _, l := range locales {
+		if !l.IsDir() {
+			continue
+		}
+		locale := l.Name()
+
+		text, err := texttemplate.ParseFS(templatesFS, "templates/"+locale+"/*.txt.tmpl")
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s text templates: %w", locale, err)
+		}
+		t.text[locale] = text
+
+		html, err := htmltemplate.ParseFS(templatesFS, "templates/"+locale+"/*.html.tmpl")
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s html templates: %w", locale, err)
+		}
+		t.html[locale] = html
+	}
+
+	if _, ok := t.text[defaultLocale]; !ok {
+		return nil, fmt.Errorf("default locale %q has no templates", defaultLocale)
+	}
+
+	return t, nil
+}
+
+// Render renders the template for the best matching locale.
+// The returned message has no recipient set.
+func (t *Templates) Render(name Template, locale string, data any) (Message, error) {
+	locale = t.resolveLocale(locale)
+
+	subject, err := executeText(t.text[locale], string(name)+".subject", data)
+	if err != nil {
+		return Message{}, err
+	}
+
+	text, err := executeText(t.text[locale], string(name)+".text", data)
+	if err != nil {
+		return Message{}, err
+	}
+
+	var html string
+	if tmpl := t.html[locale].Lookup(string(name) + ".html"); tmpl != nil {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return Message{}, fmt.Errorf("failed to render %s.html: %w", name, err)
+		}
+		html = buf.String()
+	}
+
+	return Message{
+		Subject: strings.TrimSpace(subject),
+		Text:    text,
+		HTML:    html,
+	}, nil
+}
+
+// resolveLocale picks a supported locale from a language tag or
+// an Accept-Language value, e.g. "ru-RU,ru;q=0.9,en;q=0.8" -> "ru".
+func (t *Templates) resolveLocale(locale string) string {
+	for _, tag := range strings.Split(locale, ",") {
+		tag, _, _ = strings.Cut(tag, ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		tag, _, _ = strings.Cut(strings.ReplaceAll(tag, "_", "-"), "-")
+
+		if _, ok := t.text[tag]; ok {
+			return tag
+		}
+	}
+	return t.defaultLocale
+}
+
+func executeText(tmpl *texttemplate.Template, name string, data any) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, name, data); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,39 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+)
+
+// Scope separates keys of different RPCs, so a key sent with one call
+// never replays the response of another.
+type Scope string
+
+const (
+	ScopeRegister Scope = "register"
+)
+
+// Record is what is remembered about a call made with an idempotency key.
+type Record struct {
+	// Fingerprint identifies the request the key was first used with.
+	Fingerprint string `json:"fingerprint"`
+	// Done is set once the call has completed and Response is stored.
+	Done     bool   `json:"done"`
+	Response []byte `json:"response,omitempty"`
+}
+
+// Repository describes storage of idempotency keys. Implementations
+// store only hashes of keys.
+type Repository interface {
+	// Reserve atomically claims key for a call with fingerprint for ttl.
+	// It returns true when the key is claimed by this call, otherwise
+	// false and the record left by the call which claimed it.
+	Reserve(ctx context.Context, scope Scope, key, fingerprint string, ttl time.Duration) (Record, bool, error)
+
+	// Complete stores the response of the call which reserved key;
+	// it is replayed for ttl.
+	Complete(ctx context.Context, scope Scope, key string, record Record, ttl time.Duration) error
+
+	// Release forgets key, so a failed call can be retried with it.
+	Release(ctx context.Context, scope Scope, key string) error
+}
@@ -0,0 +1,33 @@
+package introspection
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned when no result is cached for a token.
+var ErrNotFound = errors.New("introspection result not found")
+
+// Result is an outcome of token introspection.
+// Fields other than Active are only set for active tokens.
+type Result struct {
+	Active    bool      `json:"active"`
+	Subject   string    `json:"sub,omitempty"`
+	ClientID  string    `json:"client_id,omitempty"`
+	Scope     string    `json:"scope,omitempty"`
+	SessionID string    `json:"sid,omitempty"`
+	TokenID   string    `json:"jti,omitempty"`
+	IssuedAt  time.Time `json:"iat"`
+	ExpiresAt time.Time `json:"exp"`
+}
+
+// Cache keeps introspection results for a short time.
+// Implementations key results by token hashes, never by raw tokens.
+type Cache interface {
+	// Get returns a cached result for the token.
+	Get(ctx context.Context, token string) (Result, error)
+
+	// Set caches the result for ttl.
+	Set(ctx context.Context, token string, result Result, ttl time.Duration) error
+}
@@ -0,0 +1,51 @@
+package mfa
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when the user has not enrolled TOTP.
+	ErrNotFound = errors.New("totp not enrolled")
+
+	// ErrAlreadyConfirmed is returned when enrolling or confirming TOTP
+	// which is already active.
+	ErrAlreadyConfirmed = errors.New("totp already confirmed")
+)
+
+// TOTP is a TOTP enrollment of a user. Secret is encrypted by the caller.
+type TOTP struct {
+	UserID       int64
+	Secret       []byte
+	ConfirmedAt  *time.Time
+	LastUsedStep int64
+}
+
+// Active reports whether the enrollment has been confirmed.
+func (t TOTP) Active() bool {
+	return t.ConfirmedAt != nil
+}
+
+// Repository describes storage of MFA factors.
+type Repository interface {
+	// SaveTOTP stores a pending enrollment, replacing a previous pending one.
+	// It returns ErrAlreadyConfirmed if TOTP is already active.
+	SaveTOTP(ctx context.Context, userID int64, secret []byte) error
+
+	// GetTOTP returns the enrollment of the user.
+	GetTOTP(ctx context.Context, userID int64) (TOTP, error)
+
+	// ConfirmTOTP activates the enrollment, marks step as used and
+	// replaces recovery codes of the user with codeHashes.
+	ConfirmTOTP(ctx context.Context, userID, step int64, codeHashes []string) error
+
+	// UseTOTPStep marks step as used. It returns false if this or
+	// a later step has been used already.
+	UseTOTPStep(ctx context.Context, userID, step int64) (bool, error)
+
+	// UseRecoveryCode marks an unused recovery code as used.
+	// It returns false if there is no such unused code.
+	UseRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error)
+}
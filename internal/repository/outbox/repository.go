@@ -0,0 +1,62 @@
+package outbox
+
+import (
+	"context"
+	"time"
+)
+
+// Event is a stored event waiting to be published.
+type Event struct {
+	ID            int64
+	UserID        int64
+	Type          string
+	SchemaVersion int
+	// Payload is the JSON encoded event data.
+	Payload []byte
+	// Attempts counts failed publications.
+	Attempts  int
+	CreatedAt time.Time
+}
+
+// Repository describes the transactional outbox. Events are added in
+// the transaction of the change they describe and published later.
+type Repository interface {
+	// Add stores a new event.
+	Add(ctx context.Context, e Event) error
+
+	// Claim locks up to limit events which are due to be published.
+	// Only the oldest unpublished event of each user is claimed, so
+	// events of a user are published in order. It must run in
+	// a transaction; the events stay locked until it ends and other
+	// relays skip them.
+	Claim(ctx context.Context, limit int) ([]Event, error)
+
+	// MarkPublished marks the event as published.
+	MarkPublished(ctx context.Context, id int64) error
+
+	// MarkFailed records a failed publication and postpones the next
+	// attempt until retryAt.
+	MarkFailed(ctx context.Context, id int64, reason string, retryAt time.Time) error
+
+	// DeletePublished deletes up to limit events published before
+	// the given time and returns how many were deleted.
+	DeletePublished(ctx context.Context, before time.Time, limit int) (int64, error)
+}
+
+// Message is an event as published to a sink.
+type Message struct {
+	// Key is the user ID; sinks which partition messages use it, so
+	// events of a user stay in order.
+	Key  string
+	Type string
+	// Body is the JSON encoded domain.EventEnvelope.
+	Body []byte
+}
+
+// Sink is a message broker events are published to: Redis Streams,
+// Kafka, NATS.
+type Sink interface {
+	// Publish delivers the message. A message may be published again
+	// when marking it as published fails, so consumers must deduplicate.
+	Publish(ctx context.Context, m Message) error
+}
@@ -0,0 +1,48 @@
+package passkey
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when a credential does not exist.
+	ErrNotFound = errors.New("passkey credential not found")
+
+	// ErrAlreadyExists is returned when a credential ID is already registered.
+	ErrAlreadyExists = errors.New("passkey credential already exists")
+)
+
+// Credential is a WebAuthn public key credential registered by a user.
+type Credential struct {
+	ID              int64
+	UserID          int64
+	CredentialID    []byte
+	PublicKey       []byte
+	AttestationType string
+	AAGUID          []byte
+	SignCount       uint32
+	Transports      []string
+	BackupEligible  bool
+	BackupState     bool
+	Name            string
+	CreatedAt       time.Time
+	LastUsedAt      *time.Time
+}
+
+// Repository describes storage operations for passkey credentials.
+type Repository interface {
+	// Create stores a new credential.
+	Create(ctx context.Context, credential Credential) (Credential, error)
+
+	// ListByUser returns all credentials of the user.
+	ListByUser(ctx context.Context, userID int64) ([]Credential, error)
+
+	// GetByCredentialID looks up a credential by its WebAuthn credential ID.
+	GetByCredentialID(ctx context.Context, credentialID []byte) (Credential, error)
+
+	// MarkUsed stores the sign counter and backup state reported
+	// by a successful assertion.
+	MarkUsed(ctx context.Context, credentialID []byte, signCount uint32, backupState bool) error
+}
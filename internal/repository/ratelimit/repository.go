@@ -0,0 +1,38 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Lockout describes when repeated failures lock a key and for how long.
+// The n-th lock in a row lasts BaseDuration * 2^(n-1), capped at MaxDuration.
+type Lockout struct {
+	// Threshold is how many failures within Window lock the key.
+	Threshold int
+	Window    time.Duration
+	// BaseDuration is the length of the first lock.
+	BaseDuration time.Duration
+	MaxDuration  time.Duration
+	// ResetAfter is how long the lock count is remembered.
+	ResetAfter time.Duration
+}
+
+// Repository describes storage of rate limit counters and lockouts.
+// Implementations store only hashes of keys, as keys contain emails.
+type Repository interface {
+	// Allow counts a hit in the sliding window of key unless limit hits
+	// are already counted within window. It returns zero when the hit
+	// is allowed, otherwise how long to wait until the next one is.
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (time.Duration, error)
+
+	// Fail counts a failure of key. It returns how long the key is
+	// locked when this failure reaches the threshold, otherwise zero.
+	Fail(ctx context.Context, key string, lockout Lockout) (time.Duration, error)
+
+	// LockedFor returns how long the key stays locked; zero when it is not.
+	LockedFor(ctx context.Context, key string) (time.Duration, error)
+
+	// Reset forgets failures and locks of key.
+	Reset(ctx context.Context, key string) error
+}
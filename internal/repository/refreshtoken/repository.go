@@ -0,0 +1,78 @@
+package refreshtoken
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when a refresh token does not exist or has expired.
+	ErrNotFound = errors.New("refresh token not found")
+
+	// ErrRevoked is returned when the token family has been revoked.
+	ErrRevoked = errors.New("refresh token family revoked")
+
+	// ErrReused is returned when an already rotated token is presented again.
+	// The whole token family is revoked before this error is returned.
+	ErrReused = errors.New("refresh token reuse detected")
+)
+
+// Token describes a refresh token and the family it belongs to.
+// A family is started at login and is shared by all tokens
+// obtained from it through rotation.
+type Token struct {
+	FamilyID string
+	UserID   int64
+	ClientID string
+	// Scope is granted to access tokens issued within the family.
+	Scope string
+}
+
+// SessionInfo describes the login a token family is started by.
+type SessionInfo struct {
+	UserID    int64
+	ClientID  string
+	Scope     string
+	UserAgent string
+	IP        string
+}
+
+// Session is an active token family as shown to users.
+type Session struct {
+	ID            string
+	UserID        int64
+	ClientID      string
+	UserAgent     string
+	IP            string
+	CreatedAt     time.Time
+	LastRefreshAt time.Time
+}
+
+// Repository describes storage operations for refresh tokens.
+// Implementations never keep raw tokens, only their hashes.
+type Repository interface {
+	// Create starts a new token family for the session
+	// and stores token as its first member.
+	Create(ctx context.Context, token string, info SessionInfo) (Token, error)
+
+	// Get looks up an active token.
+	Get(ctx context.Context, token string) (Token, error)
+
+	// GetFamily looks up an active token family by its ID.
+	GetFamily(ctx context.Context, familyID string) (Token, error)
+
+	// Rotate marks oldToken as used and stores newToken in the same family.
+	// Presenting an already used token revokes the family and returns ErrReused.
+	Rotate(ctx context.Context, oldToken, newToken string) (Token, error)
+
+	// ListSessions returns active token families of the user.
+	ListSessions(ctx context.Context, userID int64) ([]Session, error)
+
+	// RevokeFamily revokes every token of the family.
+	RevokeFamily(ctx context.Context, familyID string) error
+
+	// RevokeUser revokes every token family of the user except
+	// exceptFamilyID, which may be empty to revoke them all.
+	RevokeUser(ctx context.Context, userID int64, exceptFamilyID string) error
+}
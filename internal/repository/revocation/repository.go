@@ -0,0 +1,17 @@
+package revocation
+
+import (
+	"context"
+	"time"
+)
+
+// Repository records per-user revocation markers. Access tokens of
+// the user issued before the marker are no longer valid,
+// whichever session they belong to.
+type Repository interface {
+	// RevokeUser invalidates access tokens of the user issued before at.
+	RevokeUser(ctx context.Context, userID int64, at time.Time) error
+
+	// RevokedAt returns the marker of the user or zero time if there is none.
+	RevokedAt(ctx context.Context, userID int64) (time.Time, error)
+}
@@ -0,0 +1,34 @@
+package signingkey
+
+import (
+	"context"
+	"time"
+)
+
+// Key is a stored token signing key.
+// A key signs tokens from ActivatesAt until RetiredAt; after that
+// it stays published only to verify tokens signed before.
+type Key struct {
+	ID            string
+	Algorithm     string
+	PrivateKeyPEM []byte
+	CreatedAt     time.Time
+	ActivatesAt   time.Time
+	RetiredAt     *time.Time
+}
+
+// Repository describes storage operations for signing keys.
+type Repository interface {
+	// ListPublished returns keys which are not retired or
+	// were retired after retiredAfter.
+	ListPublished(ctx context.Context, retiredAfter time.Time) ([]Key, error)
+
+	// Rotate stores key and retires the current keys at key.ActivatesAt,
+	// unless another key has been created after createdAfter.
+	// Concurrent rotations are serialized, so only one replica rotates.
+	// It reports whether the key has been stored.
+	Rotate(ctx context.Context, key Key, createdAfter time.Time) (bool, error)
+
+	// DeleteRetired removes keys retired before retiredBefore.
+	DeleteRetired(ctx context.Context, retiredBefore time.Time) error
+}
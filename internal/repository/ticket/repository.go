@@ -0,0 +1,36 @@
+package ticket
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned when a ticket does not exist, has expired
+// or has already been consumed.
+var ErrNotFound = errors.New("ticket not found")
+
+// Kind separates tickets issued for different purposes,
+// so a ticket of one kind can not be consumed as another.
+type Kind string
+
+const (
+	KindPasswordReset         Kind = "password_reset"
+	KindPasskeyRegistration   Kind = "passkey_registration"
+	KindPasskeyAuthentication Kind = "passkey_authentication"
+	KindSocialLogin           Kind = "social_login"
+)
+
+// Repository describes storage of short-lived single-use tickets
+// such as password reset tokens. Implementations store only
+// hashes of ticket keys, never the keys themselves.
+type Repository interface {
+	// Create stores value under key; the ticket expires after ttl.
+	Create(ctx context.Context, kind Kind, key string, value []byte, ttl time.Duration) error
+
+	// Peek returns the ticket without consuming it.
+	Peek(ctx context.Context, kind Kind, key string) ([]byte, error)
+
+	// Consume atomically returns and deletes the ticket.
+	Consume(ctx context.Context, kind Kind, key string) ([]byte, error)
+}
@@ -0,0 +1,32 @@
+package transaction
+
+import "context"
+
+// Isolation is a transaction isolation level.
+type Isolation string
+
+const (
+	ReadCommitted  Isolation = "read committed"
+	RepeatableRead Isolation = "repeatable read"
+	Serializable   Isolation = "serializable"
+)
+
+// Options configure a transaction. The zero value is a read-write
+// transaction at the database default isolation level.
+type Options struct {
+	Isolation Isolation
+	ReadOnly  bool
+}
+
+// Manager runs units of work atomically. Repositories called with the
+// context passed to fn take part in the transaction; called with any
+// other context they work outside of it.
+type Manager interface {
+	// Do runs fn in a transaction, committing it when fn returns nil and
+	// rolling it back otherwise. fn may be run again when the transaction
+	// fails to serialize, so it must not have side effects outside of
+	// the database, and should return storage errors as is, so such
+	// failures are recognized. Do called inside fn joins the outer
+	// transaction.
+	Do(ctx context.Context, opts Options, fn func(ctx context.Context) error) error
+}
@@ -3,19 +3,106 @@ package user
 import (
 	"context"
 	"errors"
+	"time"
 
-	"authorization-service/internal/domain"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/domain"
 )
 
-// ErrNotFound is returned when a user does not exist in storage.
-var ErrNotFound = errors.New("user not found")
+var (
+	// ErrNotFound is returned when a user does not exist in storage.
+	ErrNotFound = errors.New("user not found")
+	// ErrAlreadyExists is returned when a user with the same email
+	// or provider account already exists.
+	ErrAlreadyExists = errors.New("user already exists")
+	// ErrIdentityTaken is returned when a provider account is linked to another user.
+	ErrIdentityTaken = errors.New("identity is linked to another user")
+	// ErrConflict is returned by Update when the user has changed since it was read.
+	ErrConflict = errors.New("user was modified concurrently")
+	// ErrUnknownProvider is returned for identity providers users can not be linked to.
+	ErrUnknownProvider = errors.New("unknown identity provider")
+)
+
+// Identity providers users can be linked to.
+const (
+	ProviderGitHub = "github"
+	ProviderGoogle = "google"
+)
+
+// Update lists fields to change; nil fields are kept.
+type Update struct {
+	Email         *string
+	Login         *string
+	EmailVerified *bool
+	Scopes        *[]string
+}
+
+// ListFilter narrows List; zero fields do not filter.
+type ListFilter struct {
+	EmailVerified *bool
+	// CreatedFrom and CreatedTo bound creation time as [from, to).
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	// Provider keeps only users linked to the identity provider.
+	Provider string
+}
+
+// Page selects a page of List. Users are ordered by ID; AfterID is
+// the last ID of the previous page, zero for the first page.
+type Page struct {
+	AfterID int64
+	// Limit is the page size; zero means DefaultPageLimit.
+	Limit int
+}
+
+// Page sizes of List.
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 500
+)
 
 // Repository describes storage operations for users.
+// Deleted users are invisible to every method.
 type Repository interface {
 	// Create creates a new user record in storage.
-	// It returns full User with ID and timestamps.
+	// It returns full User with ID and timestamps, or ErrAlreadyExists
+	// when the email or a provider account is taken.
 	Create(ctx context.Context, u domain.User) (domain.User, error)
 
+	// GetByID looks up a user by ID.
+	GetByID(ctx context.Context, id int64) (domain.User, error)
+
 	// GetByEmail looks up a user by email.
 	GetByEmail(ctx context.Context, email string) (domain.User, error)
+
+	// GetByLogin looks up a user by login. Logins are not unique;
+	// the earliest registered user with the login is returned.
+	GetByLogin(ctx context.Context, login string) (domain.User, error)
+
+	// GetByProviderID looks up a user by account ID at an identity provider.
+	GetByProviderID(ctx context.Context, provider, providerID string) (domain.User, error)
+
+	// SetProviderID links the user to an account at an identity provider.
+	SetProviderID(ctx context.Context, id int64, provider, providerID string) error
+
+	// UnsetProviderID unlinks the user from the identity provider.
+	UnsetProviderID(ctx context.Context, id int64, provider string) error
+
+	// MarkEmailVerified sets email_verified flag of the user.
+	MarkEmailVerified(ctx context.Context, id int64) error
+
+	// UpdatePassword replaces password hash of the user.
+	UpdatePassword(ctx context.Context, id int64, passwordHash string) error
+
+	// Update changes fields of the user if it has not changed since
+	// updatedAt, the UpdatedAt it was read with. It returns ErrConflict
+	// otherwise and ErrAlreadyExists when the new email is taken.
+	Update(ctx context.Context, id int64, updatedAt time.Time, update Update) (domain.User, error)
+
+	// Delete marks the user as deleted. The email and provider
+	// accounts of a deleted user can be registered again.
+	Delete(ctx context.Context, id int64) error
+
+	// List returns a page of users matching the filter and the ID
+	// to pass as AfterID for the next page, zero on the last page.
+	List(ctx context.Context, filter ListFilter, page Page) ([]domain.User, int64, error)
 }
@@ -0,0 +1,39 @@
+package verification
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned when a flow does not exist or has expired.
+var ErrNotFound = errors.New("verification flow not found")
+
+// Flow is a pending verification of a one-time code sent to the user.
+// Only a hash of the code is stored. ClientID is set by flows which
+// end with a login.
+type Flow struct {
+	ID       string
+	Purpose  string
+	UserID   int64
+	Email    string
+	ClientID string
+	CodeHash string
+	Attempts int
+}
+
+// Repository describes storage operations for verification flows.
+type Repository interface {
+	// Create stores a new flow which expires after ttl.
+	Create(ctx context.Context, flow Flow, ttl time.Duration) error
+
+	// Get returns the flow without counting an attempt.
+	Get(ctx context.Context, id string) (Flow, error)
+
+	// Attempt registers one more verification attempt and returns
+	// the flow with the updated attempt counter.
+	Attempt(ctx context.Context, id string) (Flow, error)
+
+	// Delete removes the flow.
+	Delete(ctx context.Context, id string) error
+}
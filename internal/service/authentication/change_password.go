@@ -0,0 +1,76 @@
+package authentication
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	authorizationservicev1 "github.com/GrishanyaaShustov/CloudStorage-Protos-Service/gen/go/authorization-service"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/domain"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/lib/password"
+	userrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/user"
+)
+
+// ChangePassword replaces the password of the authenticated user.
+// The caller must prove knowledge of the current password. Every other
+// session of the user is revoked; the calling one stays signed in.
+func (s *AuthService) ChangePassword(
+	ctx context.Context,
+	request *authorizationservicev1.ChangePasswordRequest,
+) (*authorizationservicev1.ChangePasswordResponse, error) {
+
+	// 1. Authenticate caller by access token
+	claims, userID, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, userrepo.ErrNotFound) {
+			return nil, domain.ErrAccessTokenInvalid
+		}
+		return nil, domain.Internal("failed to find user")
+	}
+
+	// 2. Verify current password
+	if !s.verifyPassword(ctx, user, request.GetOldPassword()) {
+		return nil, domain.ErrWrongPassword
+	}
+
+	if request.GetNewPassword() == request.GetOldPassword() {
+		return nil, domain.ErrPasswordUnchanged
+	}
+
+	// 3. Check policy, hash and store new password
+	owner := password.Owner{Email: user.Email, Login: user.Login}
+	if err := s.checkPasswordPolicy(ctx, request.GetNewPassword(), owner); err != nil {
+		return nil, err
+	}
+
+	hash, err := s.hasher.Hash(request.GetNewPassword())
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to hash password", slog.Any("err", err))
+		return nil, domain.Internal("failed to hash password")
+	}
+
+	if err := s.users.UpdatePassword(ctx, user.ID, hash); err != nil {
+		return nil, domain.Internal("failed to change password")
+	}
+
+	// 4. Revoke every other session
+	if err := s.refreshTokens.RevokeUser(ctx, user.ID, claims.SessionID); err != nil {
+		s.log.ErrorContext(ctx, "failed to revoke sessions after password change",
+			slog.Int64("user_id", user.ID),
+			slog.Any("err", err),
+		)
+		return nil, domain.Internal("failed to revoke sessions")
+	}
+
+	s.log.InfoContext(ctx, "ChangePassword completed",
+		slog.Int64("user_id", user.ID),
+	)
+
+	return &authorizationservicev1.ChangePasswordResponse{}, nil
+}
@@ -0,0 +1,27 @@
+package authentication
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/domain"
+	outboxrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/outbox"
+)
+
+// addEvent writes a user lifecycle event to the outbox. It is called
+// in the transaction of the change, so the event is published exactly
+// when the change is committed.
+func (s *AuthService) addEvent(ctx context.Context, userID int64, eventType domain.EventType, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+
+	return s.outbox.Add(ctx, outboxrepo.Event{
+		UserID:        userID,
+		Type:          string(eventType),
+		SchemaVersion: domain.EventSchemaVersion,
+		Payload:       payload,
+	})
+}
@@ -1,19 +1,111 @@
 package authentication
 
 import (
-	"golang.org/x/crypto/bcrypt"
-	"google.golang.org/grpc/status"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/domain"
+	refreshrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/refreshtoken"
+	verificationrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/verification"
 )
-import "google.golang.org/grpc/codes"
 
-func statusUnimplemented(method string) error {
-	return status.Errorf(codes.Unimplemented, "%s is not implemented yet", method)
-}
+// tokenTypeBearer is a token_type value returned along with access tokens.
+const tokenTypeBearer = "Bearer"
 
-func hashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
+// newOpaqueToken generates a random URL-safe token (256 bits of entropy).
+func newOpaqueToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
 		return "", err
 	}
-	return string(bytes), nil
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// refreshTokenError maps refresh token repository errors to domain errors.
+// Missing, revoked and reused tokens are indistinguishable for the caller.
+func refreshTokenError(err error) error {
+	switch {
+	case errors.Is(err, refreshrepo.ErrNotFound),
+		errors.Is(err, refreshrepo.ErrRevoked),
+		errors.Is(err, refreshrepo.ErrReused):
+		return domain.ErrRefreshTokenInvalid
+	default:
+		return domain.Internal("failed to refresh token")
+	}
+}
+
+// verificationError maps verification flow repository errors to domain errors.
+func verificationError(err error) error {
+	if errors.Is(err, verificationrepo.ErrNotFound) {
+		return domain.ErrFlowNotFound
+	}
+	return domain.Internal("failed to verify code")
+}
+
+// localeFromContext returns the caller's preferred language taken
+// from the accept-language metadata, or an empty string.
+func localeFromContext(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if v := md.Get("accept-language"); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// bearerToken extracts the access token from the authorization metadata.
+func bearerToken(ctx context.Context) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return "", false
+	}
+
+	scheme, token, found := strings.Cut(values[0], " ")
+	if !found || !strings.EqualFold(scheme, tokenTypeBearer) || token == "" {
+		return "", false
+	}
+
+	return strings.TrimSpace(token), true
+}
+
+// clientFromContext returns the caller's user agent and IP address.
+// Forwarding metadata is only believed from trusted proxies, see
+// clientip.Resolver; otherwise the IP is the peer address.
+func (s *AuthService) clientFromContext(ctx context.Context) (userAgent, ip string) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	if v := md.Get("user-agent"); len(v) > 0 {
+		userAgent = v[0]
+	}
+
+	var peerAddr string
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		peerAddr = p.Addr.String()
+	}
+
+	var realIP string
+	if v := md.Get("x-real-ip"); len(v) > 0 {
+		realIP = v[0]
+	}
+
+	return userAgent, s.clientIPs.ClientIP(peerAddr, md.Get("x-forwarded-for"), realIP)
+}
+
+// hasScope reports whether a space-separated scope list contains want.
+func hasScope(scope, want string) bool {
+	return slices.Contains(strings.Fields(scope), want)
 }
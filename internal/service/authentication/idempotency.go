@@ -0,0 +1,137 @@
+package authentication
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/domain"
+	idempotencyrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/idempotency"
+)
+
+// idempotencyKeyHeader is the metadata clients send to make a call safe
+// to retry: a retried call with the same key replays the first response.
+const idempotencyKeyHeader = "idempotency-key"
+
+const maxIdempotencyKeyLength = 255
+
+// idempotencyKeyFromContext returns the idempotency key of the call,
+// or an empty string when the client sent none.
+func idempotencyKeyFromContext(ctx context.Context) (string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	values := md.Get(idempotencyKeyHeader)
+	if len(values) == 0 {
+		return "", nil
+	}
+
+	key := values[0]
+	valid := key != "" && len(key) <= maxIdempotencyKeyLength &&
+		strings.IndexFunc(key, func(r rune) bool { return r <= ' ' || r > '~' }) < 0
+	if !valid {
+		msg := "idempotency-key must be 1-255 printable ASCII characters without spaces"
+		return "", &domain.Error{
+			Code:    domain.CodeInvalidArgument,
+			Message: msg,
+			Fields: []domain.FieldViolation{
+				{Field: idempotencyKeyHeader, Reason: "INVALID_FORMAT", Description: msg},
+			},
+		}
+	}
+
+	return key, nil
+}
+
+// fingerprint hashes request fields a key is bound to, so the key can
+// not be reused for a different request. It is an HMAC with a server
+// key, so secret fields may be fingerprinted too.
+func (s *AuthService) fingerprint(fields ...string) string {
+	mac := hmac.New(sha256.New, []byte(s.cfg.Idempotency.FingerprintKey))
+	mac.Write([]byte(strings.Join(fields, "\x00")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// idempotent runs call once per idempotency key of the scope. A retry
+// with the same key and request gets the stored response; a retry while
+// the first call is still running is rejected. Failed calls release the
+// key so the client can retry them. Calls without a key just run.
+func idempotent[T proto.Message](
+	ctx context.Context,
+	s *AuthService,
+	scope idempotencyrepo.Scope,
+	requestFingerprint string,
+	call func() (T, error),
+) (T, error) {
+	var zero T
+
+	key, err := idempotencyKeyFromContext(ctx)
+	if err != nil {
+		return zero, err
+	}
+	if key == "" {
+		return call()
+	}
+
+	// 1. Claim the key or find the call which has claimed it
+	record, reserved, err := s.idempotency.Reserve(ctx, scope, key, requestFingerprint, s.cfg.Idempotency.LockTTL)
+	if err != nil {
+		return zero, domain.Internal("failed to check idempotency key")
+	}
+
+	if !reserved {
+		if record.Fingerprint != requestFingerprint {
+			return zero, domain.ErrIdempotencyKeyReused
+		}
+		if !record.Done {
+			return zero, domain.ErrRequestInProgress
+		}
+
+		resp := zero.ProtoReflect().New().Interface().(T)
+		if err := proto.Unmarshal(record.Response, resp); err != nil {
+			s.log.ErrorContext(ctx, "failed to decode idempotent response",
+				slog.String("scope", string(scope)),
+				slog.Any("err", err),
+			)
+			return zero, domain.Internal("failed to replay response")
+		}
+
+		s.log.InfoContext(ctx, "idempotent response replayed",
+			slog.String("scope", string(scope)),
+		)
+		return resp, nil
+	}
+
+	// 2. Make the call
+	resp, err := call()
+	if err != nil {
+		if releaseErr := s.idempotency.Release(ctx, scope, key); releaseErr != nil {
+			s.log.ErrorContext(ctx, "failed to release idempotency key", slog.Any("err", releaseErr))
+		}
+		return zero, err
+	}
+
+	// 3. Remember the response for retries. The call has succeeded, so
+	// a failure here is only logged: the key is released by its lock TTL.
+	data, err := proto.Marshal(resp)
+	if err == nil {
+		err = s.idempotency.Complete(ctx, scope, key, idempotencyrepo.Record{
+			Fingerprint: requestFingerprint,
+			Done:        true,
+			Response:    data,
+		}, s.cfg.Idempotency.TTL)
+	}
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to store idempotent response",
+			slog.String("scope", string(scope)),
+			slog.Any("err", err),
+		)
+	}
+
+	return resp, nil
+}
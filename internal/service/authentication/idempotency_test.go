@@ -0,0 +1,35 @@
+package authentication
+
+import (
+	"testing"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/config"
+)
+
+func TestFingerprint(t *testing.T) {
+	newService := func(key string) *AuthService {
+		return &AuthService{cfg: Config{Idempotency: config.IdempotencyConfig{FingerprintKey: key}}}
+	}
+	s := newService("0123456789abcdef0123456789abcdef")
+	base := s.fingerprint("user@example.com", "user", "secret")
+
+	tests := []struct {
+		name string
+		got  string
+		same bool
+	}{
+		{name: "same request", got: s.fingerprint("user@example.com", "user", "secret"), same: true},
+		{name: "other password", got: s.fingerprint("user@example.com", "user", "other")},
+		{name: "other login", got: s.fingerprint("user@example.com", "user2", "secret")},
+		{name: "fields are separated", got: s.fingerprint("user@example.com", "users", "ecret")},
+		{name: "other server key", got: newService("fedcba9876543210fedcba9876543210").fingerprint("user@example.com", "user", "secret")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if same := tt.got == base; same != tt.same {
+				t.Errorf("fingerprint equal = %v, want %v", same, tt.same)
+			}
+		})
+	}
+}
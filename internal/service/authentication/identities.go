@@ -0,0 +1,192 @@
+package authentication
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	authorizationservicev1 "github.com/GrishanyaaShustov/CloudStorage-Protos-Service/gen/go/authorization-service"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/domain"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/transaction"
+	userrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/user"
+)
+
+// LinkIdentity attaches a provider account to the caller. The social
+// login must have been started by the caller with link set.
+func (s *AuthService) LinkIdentity(
+	ctx context.Context,
+	request *authorizationservicev1.LinkIdentityRequest,
+) (*authorizationservicev1.LinkIdentityResponse, error) {
+
+	// 1. Authenticate; the login must be finished by whoever started it
+	_, userID, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	login, err := s.consumeSocialLogin(ctx, request.GetProvider(), request.GetState(), request.GetClientId())
+	if err != nil {
+		return nil, err
+	}
+	if login.UserID != userID {
+		return nil, domain.ErrSocialLoginNotFound
+	}
+
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, domain.Internal("failed to find user")
+	}
+	if providerID(user, login.Provider) != nil {
+		return nil, domain.ErrIdentityAlreadyLinked
+	}
+
+	// 2. Exchange the code for the provider identity
+	identity, err := s.exchangeSocialCode(ctx, login, request.GetCode())
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Link and record the event; a provider account can belong
+	// to one user only
+	err = s.tx.Do(ctx, transaction.Options{}, func(ctx context.Context) error {
+		if err := s.users.SetProviderID(ctx, userID, identity.Provider, identity.Subject); err != nil {
+			return err
+		}
+		return s.addEvent(ctx, userID, domain.EventIdentityLinked, domain.IdentityChanged{
+			Provider: identity.Provider,
+		})
+	})
+	if err != nil {
+		if errors.Is(err, userrepo.ErrIdentityTaken) {
+			return nil, domain.ErrIdentityTaken
+		}
+		return nil, domain.Internal("failed to link provider account")
+	}
+
+	s.log.InfoContext(ctx, "LinkIdentity completed",
+		slog.Int64("user_id", userID),
+		slog.String("provider", identity.Provider),
+	)
+
+	return &authorizationservicev1.LinkIdentityResponse{
+		Identity: &authorizationservicev1.Identity{
+			Provider:   identity.Provider,
+			ProviderId: identity.Subject,
+		},
+	}, nil
+}
+
+// UnlinkIdentity detaches a provider account from the caller. A user
+// without a password must keep at least one other way to sign in.
+func (s *AuthService) UnlinkIdentity(
+	ctx context.Context,
+	request *authorizationservicev1.UnlinkIdentityRequest,
+) (*authorizationservicev1.UnlinkIdentityResponse, error) {
+
+	// 1. Authenticate
+	_, userID, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := request.GetProvider()
+	if provider != userrepo.ProviderGitHub && provider != userrepo.ProviderGoogle {
+		return nil, domain.ErrUnsupportedProvider
+	}
+
+	// 2. Check and unlink in one serializable transaction, so concurrent
+	// unlinks can not remove the last login method between them
+	err = s.tx.Do(ctx, transaction.Options{Isolation: transaction.Serializable}, func(ctx context.Context) error {
+		user, err := s.users.GetByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if providerID(user, provider) == nil {
+			return domain.ErrIdentityNotLinked
+		}
+
+		// Refuse to remove the last login method
+		if user.PasswordHash == "" {
+			credentials, err := s.passkeys.ListByUser(ctx, userID)
+			if err != nil {
+				return err
+			}
+			if len(userIdentities(user)) == 1 && len(credentials) == 0 {
+				return domain.ErrLastLoginMethod
+			}
+		}
+
+		if err := s.users.UnsetProviderID(ctx, userID, provider); err != nil {
+			return err
+		}
+		return s.addEvent(ctx, userID, domain.EventIdentityUnlinked, domain.IdentityChanged{
+			Provider: provider,
+		})
+	})
+	if err != nil {
+		var derr *domain.Error
+		if errors.As(err, &derr) {
+			return nil, err
+		}
+		return nil, domain.Internal("failed to unlink provider account")
+	}
+
+	s.log.InfoContext(ctx, "UnlinkIdentity completed",
+		slog.Int64("user_id", userID),
+		slog.String("provider", provider),
+	)
+
+	return &authorizationservicev1.UnlinkIdentityResponse{}, nil
+}
+
+// ListIdentities returns provider accounts linked to the caller.
+func (s *AuthService) ListIdentities(
+	ctx context.Context,
+	_ *authorizationservicev1.ListIdentitiesRequest,
+) (*authorizationservicev1.ListIdentitiesResponse, error) {
+
+	// 1. Authenticate
+	_, userID, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, domain.Internal("failed to find user")
+	}
+
+	return &authorizationservicev1.ListIdentitiesResponse{
+		Identities:  userIdentities(user),
+		HasPassword: user.PasswordHash != "",
+	}, nil
+}
+
+// providerID returns the account ID of the user at the provider, or nil.
+func providerID(user domain.User, provider string) *string {
+	switch provider {
+	case userrepo.ProviderGitHub:
+		return user.GithubID
+	case userrepo.ProviderGoogle:
+		return user.GoogleID
+	default:
+		return nil
+	}
+}
+
+// userIdentities lists provider accounts linked to the user.
+func userIdentities(user domain.User) []*authorizationservicev1.Identity {
+	var identities []*authorizationservicev1.Identity
+
+	for _, provider := range []string{userrepo.ProviderGitHub, userrepo.ProviderGoogle} {
+		if id := providerID(user, provider); id != nil {
+			identities = append(identities, &authorizationservicev1.Identity{
+				Provider:   provider,
+				ProviderId: *id,
+			})
+		}
+	}
+
+	return identities
+}
@@ -0,0 +1,155 @@
+package authentication
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	authorizationservicev1 "github.com/GrishanyaaShustov/CloudStorage-Protos-Service/gen/go/authorization-service"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/domain"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/lib/jwt"
+	introspectionrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/introspection"
+	refreshrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/refreshtoken"
+)
+
+// scopeIntrospect allows resource servers to introspect tokens.
+const scopeIntrospect = "tokens:introspect"
+
+// IntrospectToken reports whether an access token is active (RFC 7662).
+// The caller must be authorized with an access token carrying the
+// tokens:introspect scope. Besides the signature and expiry, the token's
+// session must not be revoked and the token must not be issued before
+// the last revocation of all user's tokens.
+//
+// Results are cached for introspection.cache-ttl and the cache is not
+// cleared on revocation, so a revoked token may be reported as active
+// for that long.
+func (s *AuthService) IntrospectToken(
+	ctx context.Context,
+	request *authorizationservicev1.IntrospectTokenRequest,
+) (*authorizationservicev1.IntrospectTokenResponse, error) {
+
+	// 1. Authorize the caller
+	claims, _, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !hasScope(claims.Scope, scopeIntrospect) {
+		return nil, domain.ErrIntrospectionForbidden
+	}
+
+	token := request.GetToken()
+
+	// 2. Serve a recent result from cache
+	cached, err := s.introspection.Get(ctx, token)
+	if err == nil {
+		return introspectionResponse(cached), nil
+	}
+	if !errors.Is(err, introspectionrepo.ErrNotFound) {
+		s.log.WarnContext(ctx, "failed to read introspection cache", slog.Any("err", err))
+	}
+
+	// 3. Verify token and its session
+	result, err := s.introspect(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	// 4. Cache the result, but not beyond token expiry
+	ttl := s.cfg.Introspection.CacheTTL
+	if result.Active {
+		ttl = min(ttl, time.Until(result.ExpiresAt))
+	}
+	if ttl > 0 {
+		if err := s.introspection.Set(ctx, token, result, ttl); err != nil {
+			s.log.WarnContext(ctx, "failed to cache introspection result", slog.Any("err", err))
+		}
+	}
+
+	return introspectionResponse(result), nil
+}
+
+// introspect verifies the token. Invalid and revoked tokens are
+// reported as inactive, not as errors.
+func (s *AuthService) introspect(ctx context.Context, token string) (introspectionrepo.Result, error) {
+	claims, err := s.tokens.Parse(token)
+	if err != nil {
+		return introspectionrepo.Result{Active: false}, nil
+	}
+
+	userID, err := claims.UserID()
+	if err != nil {
+		return introspectionrepo.Result{Active: false}, nil
+	}
+
+	active, err := s.tokenActive(ctx, claims, userID)
+	if err != nil {
+		return introspectionrepo.Result{}, domain.Internal("failed to introspect token")
+	}
+	if !active {
+		return introspectionrepo.Result{Active: false}, nil
+	}
+
+	return introspectionrepo.Result{
+		Active:    true,
+		Subject:   claims.Subject,
+		ClientID:  claims.ClientID,
+		Scope:     claims.Scope,
+		SessionID: claims.SessionID,
+		TokenID:   claims.ID,
+		IssuedAt:  claims.IssuedAt.Time,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}, nil
+}
+
+// tokenActive checks revocations of a token with a valid signature:
+// its session must still be active and it must not be issued before
+// the user's revocation marker. iat has second resolution, so a token
+// issued in the second of the marker, e.g. on login right after a
+// password reset, stays active.
+func (s *AuthService) tokenActive(ctx context.Context, claims *jwt.Claims, userID int64) (bool, error) {
+	if claims.SessionID == "" || claims.IssuedAt == nil {
+		return false, nil
+	}
+
+	session, err := s.refreshTokens.GetFamily(ctx, claims.SessionID)
+	if errors.Is(err, refreshrepo.ErrNotFound) || errors.Is(err, refreshrepo.ErrRevoked) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if session.UserID != userID {
+		return false, nil
+	}
+
+	revokedAt, err := s.revocations.RevokedAt(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if !revokedAt.IsZero() && claims.IssuedAt.Before(revokedAt) {
+		return false, nil
+	}
+
+	return true, nil
+}
+
+func introspectionResponse(result introspectionrepo.Result) *authorizationservicev1.IntrospectTokenResponse {
+	if !result.Active {
+		return &authorizationservicev1.IntrospectTokenResponse{Active: false}
+	}
+
+	return &authorizationservicev1.IntrospectTokenResponse{
+		Active:    true,
+		Sub:       result.Subject,
+		ClientId:  result.ClientID,
+		Scope:     result.Scope,
+		Sid:       result.SessionID,
+		Jti:       result.TokenID,
+		TokenType: tokenTypeBearer,
+		Iat:       result.IssuedAt.Unix(),
+		Exp:       result.ExpiresAt.Unix(),
+	}
+}
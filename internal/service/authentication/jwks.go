@@ -0,0 +1,32 @@
+package authentication
+
+import (
+	"context"
+
+	authorizationservicev1 "github.com/GrishanyaaShustov/CloudStorage-Protos-Service/gen/go/authorization-service"
+)
+
+// GetJWKS returns public keys of all published signing keys,
+// including retired ones whose tokens may still be valid.
+func (s *AuthService) GetJWKS(
+	ctx context.Context,
+	_ *authorizationservicev1.GetJWKSRequest,
+) (*authorizationservicev1.GetJWKSResponse, error) {
+	set := s.keys.JWKS()
+
+	keys := make([]*authorizationservicev1.JWK, 0, len(set.Keys))
+	for _, k := range set.Keys {
+		keys = append(keys, &authorizationservicev1.JWK{
+			Kty: k.Kty,
+			Kid: k.Kid,
+			Use: k.Use,
+			Alg: k.Alg,
+			Crv: k.Crv,
+			X:   k.X,
+			N:   k.N,
+			E:   k.E,
+		})
+	}
+
+	return &authorizationservicev1.GetJWKSResponse{Keys: keys}, nil
+}
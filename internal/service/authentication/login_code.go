@@ -0,0 +1,223 @@
+package authentication
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/url"
+
+	authorizationservicev1 "github.com/GrishanyaaShustov/CloudStorage-Protos-Service/gen/go/authorization-service"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/domain"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/mailer"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/transaction"
+	userrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/user"
+	verificationrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/verification"
+)
+
+// Ways a login code is delivered.
+const (
+	loginDeliveryCode = "code"
+	loginDeliveryLink = "link"
+)
+
+// RequestLoginCode emails a one-time code or a magic link the user signs
+// in with. The response is the same whether the email is registered or
+// not, so the endpoint can not be used to enumerate accounts.
+func (s *AuthService) RequestLoginCode(
+	ctx context.Context,
+	request *authorizationservicev1.RequestLoginCodeRequest,
+) (*authorizationservicev1.RequestLoginCodeResponse, error) {
+	delivery := request.GetDelivery()
+	if delivery == "" {
+		delivery = loginDeliveryCode
+	}
+	if delivery != loginDeliveryCode && delivery != loginDeliveryLink {
+		return nil, domain.NewError(domain.CodeInvalidArgument, "delivery must be code or link")
+	}
+
+	// 1. Throttle before the lookup, unknown emails count too
+	if err := s.checkRateLimit(ctx, actionLoginCode, s.cfg.RateLimit.LoginCode, request.GetEmail()); err != nil {
+		return nil, err
+	}
+
+	// 2. Look up user; unknown email gets a flow ID which can never be completed
+	user, err := s.users.GetByEmail(ctx, request.GetEmail())
+	if errors.Is(err, userrepo.ErrNotFound) {
+		s.log.InfoContext(ctx, "RequestLoginCode for unknown email")
+
+		flowID, err := newOpaqueToken()
+		if err != nil {
+			return nil, domain.Internal("failed to start verification")
+		}
+		return &authorizationservicev1.RequestLoginCodeResponse{FlowId: flowID}, nil
+	}
+	if err != nil {
+		return nil, domain.Internal("failed to find user")
+	}
+
+	// 3. Links carry a long random token, codes are typed in by hand
+	var code string
+	if delivery == loginDeliveryLink {
+		code, err = newOpaqueToken()
+	} else {
+		code, err = newNumericCode(s.cfg.Verification.CodeLength)
+	}
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to generate login code", slog.Any("err", err))
+		return nil, domain.Internal("failed to start verification")
+	}
+
+	flow := verificationrepo.Flow{
+		Purpose:  purposeLoginCode,
+		UserID:   user.ID,
+		Email:    user.Email,
+		ClientID: request.GetClientId(),
+	}
+
+	flowID, err := s.createVerification(ctx, flow, code, s.cfg.LoginCode.TTL)
+	if err != nil {
+		return nil, err
+	}
+
+	// 4. Email the code or the link
+	expiresIn := int(s.cfg.LoginCode.TTL.Minutes())
+	if delivery == loginDeliveryLink {
+		data := mailer.LinkData{
+			Link:             s.loginLink(flowID, code),
+			ExpiresInMinutes: expiresIn,
+		}
+		err = s.mail.Send(ctx, user.Email, localeFromContext(ctx), mailer.TemplateLoginLink, data)
+	} else {
+		data := mailer.VerificationCodeData{
+			Code:             code,
+			ExpiresInMinutes: expiresIn,
+		}
+		err = s.mail.Send(ctx, user.Email, localeFromContext(ctx), mailer.TemplateLoginCode, data)
+	}
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to send login code",
+			slog.Int64("user_id", user.ID),
+			slog.Any("err", err),
+		)
+	}
+
+	s.log.InfoContext(ctx, "RequestLoginCode completed",
+		slog.Int64("user_id", user.ID),
+		slog.String("delivery", delivery),
+	)
+
+	return &authorizationservicev1.RequestLoginCodeResponse{FlowId: flowID}, nil
+}
+
+// CompleteLoginCode exchanges a code or a magic link token for tokens.
+// Receiving the email proves the address, so it is marked as verified.
+// Users with MFA get a challenge instead, exactly as with Login.
+func (s *AuthService) CompleteLoginCode(
+	ctx context.Context,
+	request *authorizationservicev1.CompleteLoginCodeRequest,
+) (*authorizationservicev1.CompleteLoginCodeResponse, error) {
+
+	// 1. Throttle code guessing; the flow tells whose email it is
+	var email string
+	if flow, err := s.verifications.Get(ctx, request.GetFlowId()); err == nil {
+		email = flow.Email
+	}
+	if err := s.checkRateLimit(ctx, actionLoginCode, s.cfg.RateLimit.LoginCode, email); err != nil {
+		return nil, err
+	}
+
+	// 2. Count the attempt; wrong codes lock the flow
+	flow, err := s.attemptVerification(ctx, purposeLoginCode, request.GetFlowId())
+	if err != nil {
+		return nil, err
+	}
+	if flow.ClientID != request.GetClientId() {
+		return nil, domain.ErrFlowNotFound
+	}
+
+	if !codeMatches(flow, request.GetCode()) {
+		return nil, s.invalidCodeError(flow)
+	}
+
+	if err := s.verifications.Delete(ctx, flow.ID); err != nil {
+		return nil, domain.Internal("failed to complete verification")
+	}
+
+	// 3. Load user and mark the email as verified
+	user, err := s.users.GetByID(ctx, flow.UserID)
+	if err != nil {
+		if errors.Is(err, userrepo.ErrNotFound) {
+			return nil, domain.ErrFlowNotFound
+		}
+		return nil, domain.Internal("failed to find user")
+	}
+
+	if !user.EmailVerified {
+		err := s.tx.Do(ctx, transaction.Options{}, func(ctx context.Context) error {
+			if err := s.users.MarkEmailVerified(ctx, user.ID); err != nil {
+				return err
+			}
+			return s.addEvent(ctx, user.ID, domain.EventUserEmailVerified, domain.UserEmailVerified{
+				Email: user.Email,
+			})
+		})
+		if err != nil {
+			return nil, domain.Internal("failed to verify email")
+		}
+		user.EmailVerified = true
+	}
+
+	// 4. Users with MFA get a challenge instead of tokens
+	methods, err := s.mfaMethods(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(methods) > 0 {
+		challengeID, err := s.startMFAChallenge(ctx, user, flow.ClientID)
+		if err != nil {
+			return nil, err
+		}
+
+		s.log.InfoContext(ctx, "CompleteLoginCode requires mfa",
+			slog.Int64("user_id", user.ID),
+		)
+
+		return &authorizationservicev1.CompleteLoginCodeResponse{
+			MfaRequired:    true,
+			MfaChallengeId: challengeID,
+			MfaMethods:     methods,
+		}, nil
+	}
+
+	// 5. Issue tokens
+	tokens, err := s.startSession(ctx, user, flow.ClientID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "CompleteLoginCode completed",
+		slog.Int64("user_id", user.ID),
+		slog.String("client_id", flow.ClientID),
+	)
+
+	return &authorizationservicev1.CompleteLoginCodeResponse{
+		AccessToken:  tokens.accessToken,
+		RefreshToken: tokens.refreshToken,
+		TokenType:    tokenTypeBearer,
+		ExpiresIn:    tokens.expiresIn,
+	}, nil
+}
+
+// loginLink appends the flow ID and the token to the configured client
+// page. The URL is checked when the config is loaded.
+func (s *AuthService) loginLink(flowID, code string) string {
+	u, _ := url.Parse(s.cfg.LoginCode.URL)
+
+	q := u.Query()
+	q.Set("flow_id", flowID)
+	q.Set("code", code)
+	u.RawQuery = q.Encode()
+
+	return u.String()
+}
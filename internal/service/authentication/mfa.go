@@ -0,0 +1,354 @@
+package authentication
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	authorizationservicev1 "github.com/GrishanyaaShustov/CloudStorage-Protos-Service/gen/go/authorization-service"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/domain"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/lib/totp"
+	mfarepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/mfa"
+	verificationrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/verification"
+)
+
+// purposeMFAChallenge is a verification flow started by Login
+// when the user has to present a second factor.
+const purposeMFAChallenge = "mfa_challenge"
+
+// Second factors a challenge can be completed with.
+const (
+	mfaMethodTOTP    = "totp"
+	mfaMethodPasskey = "passkey"
+)
+
+// recoveryCodeEncoding renders recovery codes in lower case without
+// l, o, 0 and 1, which are easy to confuse.
+var recoveryCodeEncoding = base32.NewEncoding("abcdefghijkmnpqrstuvwxyz23456789").WithPadding(base32.NoPadding)
+
+// EnrollTOTP generates a TOTP secret for the caller. The enrollment
+// stays pending until ConfirmTOTP proves the authenticator app works.
+func (s *AuthService) EnrollTOTP(
+	ctx context.Context,
+	_ *authorizationservicev1.EnrollTOTPRequest,
+) (*authorizationservicev1.EnrollTOTPResponse, error) {
+
+	// 1. Authenticate
+	_, userID, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, domain.Internal("failed to find user")
+	}
+
+	// 2. Generate and store encrypted secret
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to generate totp secret", slog.Any("err", err))
+		return nil, domain.Internal("failed to enroll totp")
+	}
+
+	sealed, err := s.secrets.Seal(secret, totpAdditionalData(userID))
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to encrypt totp secret", slog.Any("err", err))
+		return nil, domain.Internal("failed to enroll totp")
+	}
+
+	if err := s.mfa.SaveTOTP(ctx, userID, sealed); err != nil {
+		if errors.Is(err, mfarepo.ErrAlreadyConfirmed) {
+			return nil, domain.ErrMFAAlreadyEnabled
+		}
+		return nil, domain.Internal("failed to enroll totp")
+	}
+
+	s.log.InfoContext(ctx, "EnrollTOTP completed",
+		slog.Int64("user_id", userID),
+	)
+
+	return &authorizationservicev1.EnrollTOTPResponse{
+		Secret:          totp.EncodeSecret(secret),
+		ProvisioningUri: totp.URI(s.cfg.MFA.Issuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTOTP activates a pending enrollment with the first code from
+// the authenticator app and returns one-time recovery codes.
+func (s *AuthService) ConfirmTOTP(
+	ctx context.Context,
+	request *authorizationservicev1.ConfirmTOTPRequest,
+) (*authorizationservicev1.ConfirmTOTPResponse, error) {
+
+	// 1. Authenticate
+	_, userID, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Load pending enrollment
+	enrollment, err := s.mfa.GetTOTP(ctx, userID)
+	if err != nil {
+		if errors.Is(err, mfarepo.ErrNotFound) {
+			return nil, domain.ErrMFANotEnrolled
+		}
+		return nil, domain.Internal("failed to confirm totp")
+	}
+	if enrollment.Active() {
+		return nil, domain.ErrMFAAlreadyEnabled
+	}
+
+	// 3. Check the code
+	secret, err := s.secrets.Open(enrollment.Secret, totpAdditionalData(userID))
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to decrypt totp secret",
+			slog.Int64("user_id", userID),
+			slog.Any("err", err),
+		)
+		return nil, domain.Internal("failed to confirm totp")
+	}
+
+	step, ok := totp.Validate(secret, request.GetCode(), time.Now())
+	if !ok {
+		return nil, domain.NewError(domain.CodeInvalidCode, "invalid totp code")
+	}
+
+	// 4. Activate and issue recovery codes
+	recoveryCodes, hashes, err := newRecoveryCodes(s.cfg.MFA.RecoveryCodes)
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to generate recovery codes", slog.Any("err", err))
+		return nil, domain.Internal("failed to confirm totp")
+	}
+
+	if err := s.mfa.ConfirmTOTP(ctx, userID, step, hashes); err != nil {
+		if errors.Is(err, mfarepo.ErrAlreadyConfirmed) {
+			return nil, domain.ErrMFAAlreadyEnabled
+		}
+		return nil, domain.Internal("failed to confirm totp")
+	}
+
+	s.log.InfoContext(ctx, "ConfirmTOTP completed",
+		slog.Int64("user_id", userID),
+	)
+
+	return &authorizationservicev1.ConfirmTOTPResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+// CompleteMFAChallenge finishes a login started by Login with a TOTP
+// code or a recovery code and issues tokens.
+func (s *AuthService) CompleteMFAChallenge(
+	ctx context.Context,
+	request *authorizationservicev1.CompleteMFAChallengeRequest,
+) (*authorizationservicev1.CompleteMFAChallengeResponse, error) {
+
+	// 1. Throttle code guessing and refuse locked accounts; the
+	// challenge tells whose account it is
+	var email string
+	if flow, err := s.verifications.Get(ctx, request.GetChallengeId()); err == nil {
+		email = flow.Email
+	}
+	if err := s.checkRateLimit(ctx, actionMFA, s.cfg.RateLimit.MFA, email); err != nil {
+		return nil, err
+	}
+	if email != "" {
+		if err := s.checkLockout(ctx, email); err != nil {
+			return nil, err
+		}
+	}
+
+	// 2. Count the attempt; wrong codes lock the challenge
+	flow, err := s.attemptVerification(ctx, purposeMFAChallenge, request.GetChallengeId())
+	if err != nil {
+		return nil, err
+	}
+	if flow.ClientID != request.GetClientId() {
+		return nil, domain.ErrFlowNotFound
+	}
+
+	// 3. Check the second factor; wrong codes count towards the
+	// account lockout like wrong passwords, so new challenges do not
+	// bring new guesses
+	ok, err := s.checkMFACode(ctx, flow.UserID, request.GetCode())
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		s.registerLoginFailure(ctx, flow.Email)
+		return nil, s.invalidCodeError(flow)
+	}
+	s.resetLoginFailures(ctx, flow.Email)
+
+	if err := s.verifications.Delete(ctx, flow.ID); err != nil {
+		return nil, domain.Internal("failed to complete verification")
+	}
+
+	// 4. Issue tokens
+	user, err := s.users.GetByID(ctx, flow.UserID)
+	if err != nil {
+		return nil, domain.Internal("failed to find user")
+	}
+
+	tokens, err := s.startSession(ctx, user, flow.ClientID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "CompleteMFAChallenge completed",
+		slog.Int64("user_id", user.ID),
+		slog.String("client_id", flow.ClientID),
+	)
+
+	return &authorizationservicev1.CompleteMFAChallengeResponse{
+		AccessToken:  tokens.accessToken,
+		RefreshToken: tokens.refreshToken,
+		TokenType:    tokenTypeBearer,
+		ExpiresIn:    tokens.expiresIn,
+	}, nil
+}
+
+// mfaMethods returns second factors of the user. A password login
+// has to be completed with one of them unless the list is empty.
+func (s *AuthService) mfaMethods(ctx context.Context, userID int64) ([]string, error) {
+	var methods []string
+
+	enrollment, err := s.mfa.GetTOTP(ctx, userID)
+	if err != nil && !errors.Is(err, mfarepo.ErrNotFound) {
+		return nil, domain.Internal("failed to check mfa")
+	}
+	if err == nil && enrollment.Active() {
+		methods = append(methods, mfaMethodTOTP)
+	}
+
+	credentials, err := s.passkeys.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, domain.Internal("failed to check mfa")
+	}
+	if len(credentials) > 0 {
+		methods = append(methods, mfaMethodPasskey)
+	}
+
+	return methods, nil
+}
+
+// startMFAChallenge starts a challenge the client completes with
+// CompleteMFAChallenge. The challenge holds no code: TOTP codes are
+// derived from the stored secret.
+func (s *AuthService) startMFAChallenge(ctx context.Context, user domain.User, clientID string) (string, error) {
+	challengeID, err := newOpaqueToken()
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to generate challenge id", slog.Any("err", err))
+		return "", domain.Internal("failed to start mfa challenge")
+	}
+
+	flow := verificationrepo.Flow{
+		ID:       challengeID,
+		Purpose:  purposeMFAChallenge,
+		UserID:   user.ID,
+		Email:    user.Email,
+		ClientID: clientID,
+	}
+
+	if err := s.verifications.Create(ctx, flow, s.cfg.MFA.ChallengeTTL); err != nil {
+		return "", domain.Internal("failed to start mfa challenge")
+	}
+
+	return challengeID, nil
+}
+
+// checkMFACode accepts a current TOTP code which has not been used yet
+// or an unused recovery code.
+func (s *AuthService) checkMFACode(ctx context.Context, userID int64, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+
+	if len(code) != totp.Digits {
+		used, err := s.mfa.UseRecoveryCode(ctx, userID, hashRecoveryCode(code))
+		if err != nil {
+			return false, domain.Internal("failed to check mfa code")
+		}
+		if used {
+			s.log.InfoContext(ctx, "recovery code used", slog.Int64("user_id", userID))
+		}
+		return used, nil
+	}
+
+	enrollment, err := s.mfa.GetTOTP(ctx, userID)
+	if errors.Is(err, mfarepo.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, domain.Internal("failed to check mfa code")
+	}
+	if !enrollment.Active() {
+		return false, nil
+	}
+
+	secret, err := s.secrets.Open(enrollment.Secret, totpAdditionalData(userID))
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to decrypt totp secret",
+			slog.Int64("user_id", userID),
+			slog.Any("err", err),
+		)
+		return false, domain.Internal("failed to check mfa code")
+	}
+
+	step, ok := totp.Validate(secret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+
+	// A code can be used only once, even within its time window.
+	used, err := s.mfa.UseTOTPStep(ctx, userID, step)
+	if err != nil {
+		return false, domain.Internal("failed to check mfa code")
+	}
+
+	return used, nil
+}
+
+// totpAdditionalData binds an encrypted secret to its owner.
+func totpAdditionalData(userID int64) []byte {
+	return []byte("totp:" + strconv.FormatInt(userID, 10))
+}
+
+// newRecoveryCodes generates n codes formatted as xxxxx-xxxxx
+// along with their hashes.
+func newRecoveryCodes(n int) ([]string, []string, error) {
+	recoveryCodes := make([]string, 0, n)
+	hashes := make([]string, 0, n)
+
+	for range n {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+
+		raw := recoveryCodeEncoding.EncodeToString(b)[:10]
+		recoveryCodes = append(recoveryCodes, raw[:5]+"-"+raw[5:])
+		hashes = append(hashes, hashRecoveryCode(raw))
+	}
+
+	return recoveryCodes, hashes, nil
+}
+
+// hashRecoveryCode hashes a code ignoring case, spaces and dashes.
+// Codes are random enough for a plain hash to be sufficient.
+func hashRecoveryCode(code string) string {
+	normalized := strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(code))
+
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,57 @@
+package authentication
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewRecoveryCodes(t *testing.T) {
+	codes, hashes, err := newRecoveryCodes(10)
+	if err != nil {
+		t.Fatalf("newRecoveryCodes() error = %v", err)
+	}
+	if len(codes) != 10 || len(hashes) != 10 {
+		t.Fatalf("newRecoveryCodes() returned %d codes and %d hashes, want 10", len(codes), len(hashes))
+	}
+
+	seen := make(map[string]bool, len(codes))
+	for i, code := range codes {
+		if len(code) != 11 || code[5] != '-' {
+			t.Errorf("code %q is not in the xxxxx-xxxxx form", code)
+		}
+		if strings.ContainsAny(code, "01lo") {
+			t.Errorf("code %q contains look-alike characters", code)
+		}
+		if hashRecoveryCode(code) != hashes[i] {
+			t.Errorf("hash of code %q does not match the stored hash", code)
+		}
+		if seen[code] {
+			t.Errorf("code %q is repeated", code)
+		}
+		seen[code] = true
+	}
+}
+
+func TestHashRecoveryCode(t *testing.T) {
+	want := hashRecoveryCode("abcde-fghij")
+
+	tests := []struct {
+		name  string
+		code  string
+		match bool
+	}{
+		{name: "as issued", code: "abcde-fghij", match: true},
+		{name: "without dash", code: "abcdefghij", match: true},
+		{name: "upper case", code: "ABCDE-FGHIJ", match: true},
+		{name: "spaces", code: "abcde fghij", match: true},
+		{name: "other code", code: "abcde-fghik"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hashRecoveryCode(tt.code) == want; got != tt.match {
+				t.Errorf("hashRecoveryCode(%q) matches = %v, want %v", tt.code, got, tt.match)
+			}
+		})
+	}
+}
@@ -0,0 +1,434 @@
+package authentication
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+
+	authorizationservicev1 "github.com/GrishanyaaShustov/CloudStorage-Protos-Service/gen/go/authorization-service"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/domain"
+	passkeyrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/passkey"
+	ticketrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/ticket"
+	userrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/user"
+)
+
+// passkeySession is a started WebAuthn ceremony kept in the ticket store
+// until it is finished.
+type passkeySession struct {
+	Data webauthn.SessionData `json:"data"`
+	// UserID is set for registrations.
+	UserID int64 `json:"user_id,omitempty"`
+	// MFAChallengeID is set when the passkey completes a password login.
+	MFAChallengeID string `json:"mfa_challenge_id,omitempty"`
+}
+
+// BeginPasskeyRegistration starts registration of a passkey for the caller.
+// OptionsJson is passed to navigator.credentials.create() as is.
+func (s *AuthService) BeginPasskeyRegistration(
+	ctx context.Context,
+	_ *authorizationservicev1.BeginPasskeyRegistrationRequest,
+) (*authorizationservicev1.BeginPasskeyRegistrationResponse, error) {
+
+	// 1. Authenticate
+	_, userID, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.loadPasskeyUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Create options; registered credentials are excluded
+	// and the new one must be discoverable to allow passwordless login
+	creation, data, err := s.webauthn.BeginRegistration(user,
+		webauthn.WithExclusions(webauthn.Credentials(user.WebAuthnCredentials()).CredentialDescriptors()),
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementRequired),
+	)
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to begin passkey registration", slog.Any("err", err))
+		return nil, domain.Internal("failed to begin passkey registration")
+	}
+
+	// 3. Keep the ceremony state until it is finished
+	sessionID, err := s.savePasskeySession(ctx, ticketrepo.KindPasskeyRegistration, passkeySession{
+		Data:   *data,
+		UserID: userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := json.Marshal(creation)
+	if err != nil {
+		return nil, domain.Internal("failed to begin passkey registration")
+	}
+
+	return &authorizationservicev1.BeginPasskeyRegistrationResponse{
+		SessionId:   sessionID,
+		OptionsJson: string(options),
+	}, nil
+}
+
+// FinishPasskeyRegistration verifies the authenticator response and stores the credential.
+func (s *AuthService) FinishPasskeyRegistration(
+	ctx context.Context,
+	request *authorizationservicev1.FinishPasskeyRegistrationRequest,
+) (*authorizationservicev1.FinishPasskeyRegistrationResponse, error) {
+
+	// 1. Authenticate; the ceremony must be finished by whoever started it
+	_, userID, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := s.consumePasskeySession(ctx, ticketrepo.KindPasskeyRegistration, request.GetSessionId())
+	if err != nil {
+		return nil, err
+	}
+	if session.UserID != userID {
+		return nil, domain.ErrPasskeySessionNotFound
+	}
+
+	user, err := s.loadPasskeyUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Verify attestation
+	parsed, err := protocol.ParseCredentialCreationResponseBytes([]byte(request.GetCredentialJson()))
+	if err != nil {
+		return nil, domain.ErrPasskeyCredential
+	}
+
+	credential, err := s.webauthn.CreateCredential(user, session.Data, parsed)
+	if err != nil {
+		s.log.InfoContext(ctx, "passkey registration rejected",
+			slog.Int64("user_id", userID),
+			slog.Any("err", err),
+		)
+		return nil, domain.ErrPasskeyCredential
+	}
+
+	// 3. Store the credential
+	transports := make([]string, 0, len(credential.Transport))
+	for _, t := range credential.Transport {
+		transports = append(transports, string(t))
+	}
+
+	_, err = s.passkeys.Create(ctx, passkeyrepo.Credential{
+		UserID:          userID,
+		CredentialID:    credential.ID,
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		AAGUID:          credential.Authenticator.AAGUID,
+		SignCount:       credential.Authenticator.SignCount,
+		Transports:      transports,
+		BackupEligible:  credential.Flags.BackupEligible,
+		BackupState:     credential.Flags.BackupState,
+		Name:            strings.TrimSpace(request.GetName()),
+	})
+	if err != nil {
+		if errors.Is(err, passkeyrepo.ErrAlreadyExists) {
+			return nil, domain.ErrPasskeyExists
+		}
+		return nil, domain.Internal("failed to register passkey")
+	}
+
+	s.log.InfoContext(ctx, "FinishPasskeyRegistration completed",
+		slog.Int64("user_id", userID),
+	)
+
+	return &authorizationservicev1.FinishPasskeyRegistrationResponse{}, nil
+}
+
+// BeginPasskeyLogin starts a passkey assertion. Without mfa_challenge_id
+// it is a passwordless login with any discoverable passkey; with it the
+// passkey completes a password login as the second factor.
+// OptionsJson is passed to navigator.credentials.get() as is.
+func (s *AuthService) BeginPasskeyLogin(
+	ctx context.Context,
+	request *authorizationservicev1.BeginPasskeyLoginRequest,
+) (*authorizationservicev1.BeginPasskeyLoginResponse, error) {
+	var (
+		assertion *protocol.CredentialAssertion
+		data      *webauthn.SessionData
+		err       error
+	)
+
+	challengeID := request.GetMfaChallengeId()
+
+	if challengeID == "" {
+		// 1a. Passwordless: the authenticator picks the account and
+		// must verify the user, so the passkey is both factors at once
+		assertion, data, err = s.webauthn.BeginDiscoverableLogin(
+			webauthn.WithUserVerification(protocol.VerificationRequired),
+		)
+	} else {
+		// 1b. Second factor: only passkeys of the challenged user are allowed
+		flow, ferr := s.verifications.Get(ctx, challengeID)
+		if ferr != nil || flow.Purpose != purposeMFAChallenge {
+			return nil, domain.ErrFlowNotFound
+		}
+
+		user, uerr := s.loadPasskeyUser(ctx, flow.UserID)
+		if uerr != nil {
+			return nil, uerr
+		}
+		if len(user.credentials) == 0 {
+			return nil, domain.ErrNoPasskeys
+		}
+
+		assertion, data, err = s.webauthn.BeginLogin(user)
+	}
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to begin passkey login", slog.Any("err", err))
+		return nil, domain.Internal("failed to begin passkey login")
+	}
+
+	// 2. Keep the ceremony state until it is finished
+	sessionID, err := s.savePasskeySession(ctx, ticketrepo.KindPasskeyAuthentication, passkeySession{
+		Data:           *data,
+		MFAChallengeID: challengeID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := json.Marshal(assertion)
+	if err != nil {
+		return nil, domain.Internal("failed to begin passkey login")
+	}
+
+	return &authorizationservicev1.BeginPasskeyLoginResponse{
+		SessionId:   sessionID,
+		OptionsJson: string(options),
+	}, nil
+}
+
+// FinishPasskeyLogin verifies the assertion and issues tokens.
+func (s *AuthService) FinishPasskeyLogin(
+	ctx context.Context,
+	request *authorizationservicev1.FinishPasskeyLoginRequest,
+) (*authorizationservicev1.FinishPasskeyLoginResponse, error) {
+
+	// 1. Take the ceremony state; a session can be finished only once
+	session, err := s.consumePasskeySession(ctx, ticketrepo.KindPasskeyAuthentication, request.GetSessionId())
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBytes([]byte(request.GetCredentialJson()))
+	if err != nil {
+		return nil, domain.ErrPasskeyMalformed
+	}
+
+	clientID := request.GetClientId()
+
+	// 2. Verify the assertion
+	var (
+		user       *passkeyUser
+		credential *webauthn.Credential
+	)
+
+	if session.MFAChallengeID == "" {
+		var found webauthn.User
+		found, credential, err = s.webauthn.ValidatePasskeyLogin(
+			func(_, userHandle []byte) (webauthn.User, error) {
+				userID, ok := passkeyUserID(userHandle)
+				if !ok {
+					return nil, userrepo.ErrNotFound
+				}
+				return s.loadPasskeyUser(ctx, userID)
+			},
+			session.Data, parsed,
+		)
+		if err == nil {
+			user, _ = found.(*passkeyUser)
+		}
+	} else {
+		// Counts as an attempt to complete the MFA challenge
+		flow, ferr := s.attemptVerification(ctx, purposeMFAChallenge, session.MFAChallengeID)
+		if ferr != nil {
+			return nil, ferr
+		}
+		if flow.ClientID != clientID {
+			return nil, domain.ErrFlowNotFound
+		}
+
+		user, err = s.loadPasskeyUser(ctx, flow.UserID)
+		if err != nil {
+			return nil, err
+		}
+
+		credential, err = s.webauthn.ValidateLogin(user, session.Data, parsed)
+		if err != nil {
+			s.log.InfoContext(ctx, "passkey assertion rejected", slog.Any("err", err))
+			return nil, s.invalidCodeError(flow)
+		}
+		// The password login is complete now
+		s.resetLoginFailures(ctx, flow.Email)
+
+		if err := s.verifications.Delete(ctx, flow.ID); err != nil {
+			return nil, domain.Internal("failed to complete verification")
+		}
+	}
+	if err != nil || user == nil {
+		s.log.InfoContext(ctx, "passkey assertion rejected", slog.Any("err", err))
+		return nil, domain.ErrPasskeyRejected
+	}
+
+	// 3. A counter going backwards means the key may have been cloned
+	if credential.Authenticator.CloneWarning {
+		s.log.WarnContext(ctx, "passkey sign counter did not increase, possible clone",
+			slog.Int64("user_id", user.ID),
+		)
+		return nil, domain.ErrPasskeyRejected
+	}
+
+	if err := s.passkeys.MarkUsed(ctx, credential.ID, credential.Authenticator.SignCount, credential.Flags.BackupState); err != nil {
+		return nil, domain.Internal("failed to update passkey")
+	}
+
+	// 4. Issue tokens
+	tokens, err := s.startSession(ctx, user.User, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "FinishPasskeyLogin completed",
+		slog.Int64("user_id", user.ID),
+		slog.String("client_id", clientID),
+		slog.Bool("second_factor", session.MFAChallengeID != ""),
+	)
+
+	return &authorizationservicev1.FinishPasskeyLoginResponse{
+		AccessToken:  tokens.accessToken,
+		RefreshToken: tokens.refreshToken,
+		TokenType:    tokenTypeBearer,
+		ExpiresIn:    tokens.expiresIn,
+	}, nil
+}
+
+// savePasskeySession stores ceremony state under a new random session ID.
+func (s *AuthService) savePasskeySession(ctx context.Context, kind ticketrepo.Kind, session passkeySession) (string, error) {
+	sessionID, err := newOpaqueToken()
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to generate passkey session id", slog.Any("err", err))
+		return "", domain.Internal("failed to start passkey ceremony")
+	}
+
+	value, err := json.Marshal(session)
+	if err != nil {
+		return "", domain.Internal("failed to start passkey ceremony")
+	}
+
+	if err := s.tickets.Create(ctx, kind, sessionID, value, s.cfg.WebAuthn.SessionTTL); err != nil {
+		return "", domain.Internal("failed to start passkey ceremony")
+	}
+
+	return sessionID, nil
+}
+
+// consumePasskeySession takes ceremony state out of the store.
+func (s *AuthService) consumePasskeySession(ctx context.Context, kind ticketrepo.Kind, sessionID string) (passkeySession, error) {
+	value, err := s.tickets.Consume(ctx, kind, sessionID)
+	if err != nil {
+		if errors.Is(err, ticketrepo.ErrNotFound) {
+			return passkeySession{}, domain.ErrPasskeySessionNotFound
+		}
+		return passkeySession{}, domain.Internal("failed to finish passkey ceremony")
+	}
+
+	var session passkeySession
+	if err := json.Unmarshal(value, &session); err != nil {
+		s.log.ErrorContext(ctx, "malformed passkey session", slog.Any("err", err))
+		return passkeySession{}, domain.Internal("failed to finish passkey ceremony")
+	}
+
+	return session, nil
+}
+
+// loadPasskeyUser loads the user along with registered credentials.
+func (s *AuthService) loadPasskeyUser(ctx context.Context, userID int64) (*passkeyUser, error) {
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, userrepo.ErrNotFound) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, domain.Internal("failed to find user")
+	}
+
+	credentials, err := s.passkeys.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, domain.Internal("failed to load passkeys")
+	}
+
+	return &passkeyUser{User: user, credentials: credentials}, nil
+}
+
+// passkeyUser adapts domain.User to webauthn.User.
+type passkeyUser struct {
+	domain.User
+	credentials []passkeyrepo.Credential
+}
+
+// WebAuthnID returns the user handle: the user ID as 8 big-endian bytes.
+func (u *passkeyUser) WebAuthnID() []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(u.ID))
+}
+
+func (u *passkeyUser) WebAuthnName() string {
+	return u.Email
+}
+
+func (u *passkeyUser) WebAuthnDisplayName() string {
+	if u.Login != "" {
+		return u.Login
+	}
+	return u.Email
+}
+
+func (u *passkeyUser) WebAuthnCredentials() []webauthn.Credential {
+	credentials := make([]webauthn.Credential, 0, len(u.credentials))
+
+	for _, c := range u.credentials {
+		transports := make([]protocol.AuthenticatorTransport, 0, len(c.Transports))
+		for _, t := range c.Transports {
+			transports = append(transports, protocol.AuthenticatorTransport(t))
+		}
+
+		credentials = append(credentials, webauthn.Credential{
+			ID:              c.CredentialID,
+			PublicKey:       c.PublicKey,
+			AttestationType: c.AttestationType,
+			Transport:       transports,
+			Flags: webauthn.CredentialFlags{
+				UserPresent:    true,
+				BackupEligible: c.BackupEligible,
+				BackupState:    c.BackupState,
+			},
+			Authenticator: webauthn.Authenticator{
+				AAGUID:    c.AAGUID,
+				SignCount: c.SignCount,
+			},
+		})
+	}
+
+	return credentials
+}
+
+// passkeyUserID decodes a user handle produced by WebAuthnID.
+func passkeyUserID(userHandle []byte) (int64, bool) {
+	if len(userHandle) != 8 {
+		return 0, false
+	}
+	return int64(binary.BigEndian.Uint64(userHandle)), true
+}
@@ -0,0 +1,83 @@
+package authentication
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/domain"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/lib/password"
+)
+
+// checkPasswordPolicy rejects a new password violating the policy.
+// Every failed rule is listed as a field violation of the password field.
+func (s *AuthService) checkPasswordPolicy(ctx context.Context, pw string, owner password.Owner) error {
+	violations, err := s.passwordPolicy.Check(pw, owner)
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to check password policy", slog.Any("err", err))
+		return domain.Internal("failed to check password")
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+
+	fields := make([]domain.FieldViolation, 0, len(violations))
+	for _, v := range violations {
+		fields = append(fields, domain.FieldViolation{
+			Field:       "password",
+			Reason:      "PASSWORD_" + strings.ToUpper(v.Rule),
+			Description: v.Message,
+		})
+	}
+
+	return &domain.Error{
+		Code:    domain.CodePasswordPolicy,
+		Message: "password does not meet the policy",
+		Fields:  fields,
+	}
+}
+
+// verifyPassword checks the password of the user. Users without
+// a password are checked against a dummy hash, so unknown emails and
+// social-only accounts take as long as real ones. A hash made with old
+// parameters is replaced once the password is known to be right.
+func (s *AuthService) verifyPassword(ctx context.Context, user domain.User, pw string) bool {
+	ok, rehash, err := s.hasher.Verify(user.PasswordHash, pw)
+	if errors.Is(err, password.ErrUnknownPepper) {
+		// Configure the pepper in PASSWORD_OLD_PEPPERS, or the user
+		// has to reset the password.
+		s.log.ErrorContext(ctx, "password hash needs reset: its pepper is not configured",
+			slog.Int64("user_id", user.ID),
+			slog.Any("err", err),
+		)
+		return false
+	}
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to verify password",
+			slog.Int64("user_id", user.ID),
+			slog.Any("err", err),
+		)
+		return false
+	}
+	if !ok || !rehash {
+		return ok
+	}
+
+	// Upgrading is best effort: the old hash keeps working.
+	hash, err := s.hasher.Hash(pw)
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to rehash password", slog.Any("err", err))
+		return true
+	}
+	if err := s.users.UpdatePassword(ctx, user.ID, hash); err != nil {
+		s.log.ErrorContext(ctx, "failed to store rehashed password",
+			slog.Int64("user_id", user.ID),
+			slog.Any("err", err),
+		)
+		return true
+	}
+
+	s.log.InfoContext(ctx, "password hash upgraded", slog.Int64("user_id", user.ID))
+	return true
+}
@@ -0,0 +1,174 @@
+package authentication
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"time"
+
+	authorizationservicev1 "github.com/GrishanyaaShustov/CloudStorage-Protos-Service/gen/go/authorization-service"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/domain"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/lib/password"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/mailer"
+	ticketrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/ticket"
+	userrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/user"
+)
+
+// RequestPasswordReset issues a single-use reset token and emails it to the user.
+// The response is the same whether the email is registered or not,
+// so the endpoint can not be used to enumerate accounts.
+func (s *AuthService) RequestPasswordReset(
+	ctx context.Context,
+	request *authorizationservicev1.RequestPasswordResetRequest,
+) (*authorizationservicev1.RequestPasswordResetResponse, error) {
+	resp := &authorizationservicev1.RequestPasswordResetResponse{}
+
+	// 1. Look up user; unknown email silently ends the flow
+	user, err := s.users.GetByEmail(ctx, request.GetEmail())
+	if errors.Is(err, userrepo.ErrNotFound) {
+		s.log.InfoContext(ctx, "RequestPasswordReset for unknown email")
+		return resp, nil
+	}
+	if err != nil {
+		return nil, domain.Internal("failed to find user")
+	}
+
+	// 2. Store hashed single-use token
+	token, err := newOpaqueToken()
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to generate reset token", slog.Any("err", err))
+		return nil, domain.Internal("failed to request password reset")
+	}
+
+	err = s.tickets.Create(ctx, ticketrepo.KindPasswordReset, token,
+		[]byte(strconv.FormatInt(user.ID, 10)), s.cfg.PasswordReset.TTL,
+	)
+	if err != nil {
+		return nil, domain.Internal("failed to request password reset")
+	}
+
+	// 3. Email the link
+	data := mailer.LinkData{
+		Link:             s.passwordResetLink(token),
+		ExpiresInMinutes: int(s.cfg.PasswordReset.TTL.Minutes()),
+	}
+
+	err = s.mail.Send(ctx, user.Email, localeFromContext(ctx), mailer.TemplatePasswordReset, data)
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to send password reset link",
+			slog.Int64("user_id", user.ID),
+			slog.Any("err", err),
+		)
+	}
+
+	s.log.InfoContext(ctx, "RequestPasswordReset completed",
+		slog.Int64("user_id", user.ID),
+	)
+
+	return resp, nil
+}
+
+// ResetPassword sets a new password using a reset token and
+// revokes all refresh sessions of the user.
+func (s *AuthService) ResetPassword(
+	ctx context.Context,
+	request *authorizationservicev1.ResetPasswordRequest,
+) (*authorizationservicev1.ResetPasswordResponse, error) {
+
+	// 1. Check the token; it is consumed only once the new password
+	// has passed the policy, so a rejected password does not burn it
+	value, err := s.tickets.Peek(ctx, ticketrepo.KindPasswordReset, request.GetToken())
+	if err != nil {
+		if errors.Is(err, ticketrepo.ErrNotFound) {
+			return nil, domain.ErrResetTokenInvalid
+		}
+		return nil, domain.Internal("failed to reset password")
+	}
+
+	userID, err := strconv.ParseInt(string(value), 10, 64)
+	if err != nil {
+		s.log.ErrorContext(ctx, "malformed password reset ticket", slog.Any("err", err))
+		return nil, domain.Internal("failed to reset password")
+	}
+
+	// 2. Check policy and hash new password
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, userrepo.ErrNotFound) {
+			return nil, domain.ErrResetTokenInvalid
+		}
+		return nil, domain.Internal("failed to reset password")
+	}
+
+	owner := password.Owner{Email: user.Email, Login: user.Login}
+	if err := s.checkPasswordPolicy(ctx, request.GetNewPassword(), owner); err != nil {
+		return nil, err
+	}
+
+	hash, err := s.hasher.Hash(request.GetNewPassword())
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to hash password", slog.Any("err", err))
+		return nil, domain.Internal("failed to hash password")
+	}
+
+	// 3. Consume the token right before the update; of concurrent
+	// resets with the same token only one gets it
+	consumed, err := s.tickets.Consume(ctx, ticketrepo.KindPasswordReset, request.GetToken())
+	if err != nil {
+		if errors.Is(err, ticketrepo.ErrNotFound) {
+			return nil, domain.ErrResetTokenInvalid
+		}
+		return nil, domain.Internal("failed to reset password")
+	}
+	if string(consumed) != string(value) {
+		return nil, domain.ErrResetTokenInvalid
+	}
+
+	// 4. Store the new password
+	if err := s.users.UpdatePassword(ctx, userID, hash); err != nil {
+		if errors.Is(err, userrepo.ErrNotFound) {
+			return nil, domain.ErrResetTokenInvalid
+		}
+		return nil, domain.Internal("failed to reset password")
+	}
+
+	// 5. Sign out everywhere: whoever knew the old password
+	// must not keep a session
+	if err := s.refreshTokens.RevokeUser(ctx, userID, ""); err != nil {
+		s.log.ErrorContext(ctx, "failed to revoke sessions after password reset",
+			slog.Int64("user_id", userID),
+			slog.Any("err", err),
+		)
+		return nil, domain.Internal("failed to revoke sessions")
+	}
+
+	// 6. Access tokens issued so far stop working too
+	if err := s.revocations.RevokeUser(ctx, userID, time.Now()); err != nil {
+		s.log.ErrorContext(ctx, "failed to revoke access tokens after password reset",
+			slog.Int64("user_id", userID),
+			slog.Any("err", err),
+		)
+		return nil, domain.Internal("failed to revoke sessions")
+	}
+
+	s.log.InfoContext(ctx, "ResetPassword completed",
+		slog.Int64("user_id", userID),
+	)
+
+	return &authorizationservicev1.ResetPasswordResponse{}, nil
+}
+
+// passwordResetLink appends the token to the configured client page.
+// The URL is checked when the config is loaded.
+func (s *AuthService) passwordResetLink(token string) string {
+	u, _ := url.Parse(s.cfg.PasswordReset.URL)
+
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+
+	return u.String()
+}
@@ -5,58 +5,198 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
+	"time"
 
 	authorizationservicev1 "github.com/GrishanyaaShustov/CloudStorage-Protos-Service/gen/go/authorization-service"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+	"github.com/go-webauthn/webauthn/webauthn"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
-	"authorization-service/internal/domain"
-	grpcauth "authorization-service/internal/grpc/authentication"
-	userrepo "authorization-service/internal/repository/user"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/config"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/domain"
+	grpcauth "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/grpc/authentication"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/lib/clientip"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/lib/jwt"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/lib/password"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/lib/secretbox"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/mailer"
+	idempotencyrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/idempotency"
+	introspectionrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/introspection"
+	mfarepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/mfa"
+	outboxrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/outbox"
+	passkeyrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/passkey"
+	ratelimitrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/ratelimit"
+	refreshrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/refreshtoken"
+	revocationrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/revocation"
+	ticketrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/ticket"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/transaction"
+	userrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/user"
+	verificationrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/verification"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/social"
 )
 
 // AuthService is a concrete implementation of the authentication Service.
 type AuthService struct {
-	log   *slog.Logger
-	users userrepo.Repository
+	log            *slog.Logger
+	users          userrepo.Repository
+	refreshTokens  refreshrepo.Repository
+	verifications  verificationrepo.Repository
+	tickets        ticketrepo.Repository
+	revocations    revocationrepo.Repository
+	introspection  introspectionrepo.Cache
+	mfa            mfarepo.Repository
+	passkeys       passkeyrepo.Repository
+	rateLimits     ratelimitrepo.Repository
+	idempotency    idempotencyrepo.Repository
+	outbox         outboxrepo.Repository
+	tx             transaction.Manager
+	tokens         *jwt.Issuer
+	keys           KeySet
+	secrets        *secretbox.Box
+	passwordPolicy *password.Policy
+	hasher         *password.Hasher
+	webauthn       *webauthn.WebAuthn
+	providers      social.Providers
+	clientIPs      *clientip.Resolver
+	mail           Mailer
+	cfg            Config
 }
 
-func NewAuthService(log *slog.Logger, users userrepo.Repository) *AuthService {
+// Mailer sends templated emails to users.
+type Mailer interface {
+	Send(ctx context.Context, to, locale string, name mailer.Template, data any) error
+}
+
+// KeySet publishes public keys access tokens are verified with.
+type KeySet interface {
+	JWKS() jwt.JWKS
+}
+
+// Repositories groups storages AuthService works with.
+type Repositories struct {
+	Users         userrepo.Repository
+	RefreshTokens refreshrepo.Repository
+	Verifications verificationrepo.Repository
+	Tickets       ticketrepo.Repository
+	Revocations   revocationrepo.Repository
+	Introspection introspectionrepo.Cache
+	MFA           mfarepo.Repository
+	Passkeys      passkeyrepo.Repository
+	RateLimits    ratelimitrepo.Repository
+	Idempotency   idempotencyrepo.Repository
+	// Outbox stores user events; it is written in the transactions
+	// of the changes the events describe.
+	Outbox outboxrepo.Repository
+	// Transactions run units of work over the Postgres repositories.
+	Transactions transaction.Manager
+}
+
+// Config groups settings of AuthService flows.
+type Config struct {
+	Verification  config.VerificationConfig
+	PasswordReset config.PasswordResetConfig
+	LoginCode     config.LoginCodeConfig
+	Introspection config.IntrospectionConfig
+	MFA           config.MFAConfig
+	WebAuthn      config.WebAuthnConfig
+	Social        config.SocialConfig
+	RateLimit     config.RateLimitConfig
+	Idempotency   config.IdempotencyConfig
+}
+
+func NewAuthService(
+	log *slog.Logger,
+	repos Repositories,
+	tokens *jwt.Issuer,
+	keys KeySet,
+	secrets *secretbox.Box,
+	passwordPolicy *password.Policy,
+	hasher *password.Hasher,
+	wa *webauthn.WebAuthn,
+	providers social.Providers,
+	clientIPs *clientip.Resolver,
+	mail Mailer,
+	cfg Config,
+) *AuthService {
 	return &AuthService{
-		log:   log,
-		users: users,
+		log:            log,
+		users:          repos.Users,
+		refreshTokens:  repos.RefreshTokens,
+		verifications:  repos.Verifications,
+		tickets:        repos.Tickets,
+		revocations:    repos.Revocations,
+		introspection:  repos.Introspection,
+		mfa:            repos.MFA,
+		passkeys:       repos.Passkeys,
+		rateLimits:     repos.RateLimits,
+		idempotency:    repos.Idempotency,
+		outbox:         repos.Outbox,
+		tx:             repos.Transactions,
+		tokens:         tokens,
+		keys:           keys,
+		secrets:        secrets,
+		passwordPolicy: passwordPolicy,
+		hasher:         hasher,
+		webauthn:       wa,
+		providers:      providers,
+		clientIPs:      clientIPs,
+		mail:           mail,
+		cfg:            cfg,
 	}
 }
 
 // Make sure AuthService implements the grpcauth.Service interface.
 var _ grpcauth.Service = (*AuthService)(nil)
 
-// --- Stub implementations ---
-
+// Register creates a user. Calls sent with an Idempotency-Key are
+// safe to retry: a retry returns the response of the first call.
 func (s *AuthService) Register(
 	ctx context.Context,
 	request *authorizationservicev1.RegisterRequest,
 ) (*authorizationservicev1.RegisterResponse, error) {
+	// The password is fingerprinted too: a retry with another password
+	// must not get the response of an account with the first one, and
+	// only whoever knows the password can replay the response.
+	fp := s.fingerprint(request.GetEmail(), request.GetLogin(), request.GetPassword())
+
+	return idempotent(ctx, s, idempotencyrepo.ScopeRegister, fp, func() (*authorizationservicev1.RegisterResponse, error) {
+		return s.register(ctx, request)
+	})
+}
+
+func (s *AuthService) register(
+	ctx context.Context,
+	request *authorizationservicev1.RegisterRequest,
+) (*authorizationservicev1.RegisterResponse, error) {
+
+	// 1. Throttle sign-up floods
+	if err := s.checkRateLimit(ctx, actionRegister, s.cfg.RateLimit.Register, request.GetEmail()); err != nil {
+		return nil, err
+	}
 
-	// 1. Check, user with that email does not exist
+	// 2. Check, user with that email does not exist
 	_, err := s.users.GetByEmail(ctx, request.GetEmail())
 	if err == nil {
 		// пользователь найден → ошибка
-		return nil, status.Error(codes.AlreadyExists, "email is already registered")
+		return nil, domain.ErrEmailTaken
 	}
 	if !errors.Is(err, userrepo.ErrNotFound) {
-		return nil, status.Error(codes.Internal, "failed to check email")
+		return nil, domain.Internal("failed to check email")
 	}
 
-	// 2. Password hashing
-	hash, err := hashPassword(request.GetPassword())
+	// 3. Check password policy and hash the password
+	owner := password.Owner{Email: request.GetEmail(), Login: request.GetLogin()}
+	if err := s.checkPasswordPolicy(ctx, request.GetPassword(), owner); err != nil {
+		return nil, err
+	}
+
+	hash, err := s.hasher.Hash(request.GetPassword())
 	if err != nil {
 		s.log.ErrorContext(ctx, "failed to hash password", slog.Any("err", err))
-		return nil, status.Error(codes.Internal, "failed to hash password")
+		return nil, domain.Internal("failed to hash password")
 	}
 
-	// 3. Create domain user model
+	// 4. Create domain user model
 	user := domain.User{
 		Email:         request.GetEmail(),
 		Login:         request.GetLogin(),
@@ -64,14 +204,37 @@ func (s *AuthService) Register(
 		EmailVerified: false,
 	}
 
-	// 4. Write user in DB
-	created, err := s.users.Create(ctx, user)
+	// 5. Write user in DB with its registered event; a concurrent
+	// registration may have taken the email since the check
+	var created domain.User
+	err = s.tx.Do(ctx, transaction.Options{}, func(ctx context.Context) error {
+		var err error
+		created, err = s.users.Create(ctx, user)
+		if err != nil {
+			return err
+		}
+
+		return s.addEvent(ctx, created.ID, domain.EventUserRegistered, domain.UserRegistered{
+			Email:         created.Email,
+			Login:         created.Login,
+			EmailVerified: created.EmailVerified,
+		})
+	})
 	if err != nil {
+		if errors.Is(err, userrepo.ErrAlreadyExists) {
+			return nil, domain.ErrEmailTaken
+		}
 		s.log.ErrorContext(ctx, "failed to create user", slog.Any("err", err))
-		return nil, status.Error(codes.Internal, "failed to create user")
+		return nil, domain.Internal("failed to create user")
 	}
 
-	// 5. Turn domain.User to protobuf User
+	// 6. Start email verification and send the code
+	flowID, err := s.startEmailVerification(ctx, created)
+	if err != nil {
+		return nil, err
+	}
+
+	// 7. Turn domain.User to protobuf User
 	resp := &authorizationservicev1.RegisterResponse{
 		User: &authorizationservicev1.User{
 			UserId:        fmt.Sprintf("%d", created.ID),
@@ -81,6 +244,7 @@ func (s *AuthService) Register(
 			CreatedAt:     timestamppb.New(created.CreatedAt),
 			UpdatedAt:     timestamppb.New(created.UpdatedAt),
 		},
+		FlowId: flowID,
 	}
 
 	s.log.InfoContext(ctx, "Register completed",
@@ -94,34 +258,269 @@ func (s *AuthService) VerifyEmail(
 	ctx context.Context,
 	request *authorizationservicev1.VerifyEmailRequest,
 ) (*authorizationservicev1.VerifyEmailResponse, error) {
-	// TODO: implement email verification using Kratos.
-	s.log.InfoContext(ctx, "VerifyEmail not implemented yet")
-	return nil, statusUnimplemented("VerifyEmail")
+
+	// 1. Throttle code guessing; the flow tells whose email is verified
+	var email string
+	if flow, err := s.verifications.Get(ctx, request.GetFlowId()); err == nil {
+		email = flow.Email
+	}
+	if err := s.checkRateLimit(ctx, actionVerifyEmail, s.cfg.RateLimit.VerifyEmail, email); err != nil {
+		return nil, err
+	}
+
+	// 2. Check the code; wrong codes are counted and lock the flow
+	flow, err := s.completeVerification(ctx, purposeVerifyEmail, request.GetFlowId(), request.GetVerificationCode())
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Mark email as verified and record the event
+	err = s.tx.Do(ctx, transaction.Options{}, func(ctx context.Context) error {
+		if err := s.users.MarkEmailVerified(ctx, flow.UserID); err != nil {
+			return err
+		}
+		return s.addEvent(ctx, flow.UserID, domain.EventUserEmailVerified, domain.UserEmailVerified{
+			Email: flow.Email,
+		})
+	})
+	if err != nil {
+		if errors.Is(err, userrepo.ErrNotFound) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, domain.Internal("failed to verify email")
+	}
+
+	s.log.InfoContext(ctx, "VerifyEmail completed",
+		slog.Int64("user_id", flow.UserID),
+	)
+
+	return &authorizationservicev1.VerifyEmailResponse{}, nil
 }
 
 func (s *AuthService) Login(
 	ctx context.Context,
 	request *authorizationservicev1.LoginRequest,
 ) (*authorizationservicev1.LoginResponse, error) {
-	// TODO: implement login via Kratos + token issuance via Hydra.
-	s.log.InfoContext(ctx, "Login not implemented yet")
-	return nil, statusUnimplemented("Login")
+	// 1. Throttle password guessing and refuse locked accounts
+	if err := s.checkRateLimit(ctx, actionLogin, s.cfg.RateLimit.Login, request.GetEmail()); err != nil {
+		return nil, err
+	}
+	if err := s.checkLockout(ctx, request.GetEmail()); err != nil {
+		return nil, err
+	}
+
+	// 2. Look up user. Unknown email is not an error yet: the password
+	// check below still runs against a dummy hash, so both cases take
+	// the same time and return the same error.
+	user, err := s.users.GetByEmail(ctx, request.GetEmail())
+	if err != nil && !errors.Is(err, userrepo.ErrNotFound) {
+		return nil, domain.Internal("failed to find user")
+	}
+	found := err == nil
+
+	// 3. Verify password; failures count towards a lockout
+	if !s.verifyPassword(ctx, user, request.GetPassword()) || !found {
+		s.registerLoginFailure(ctx, request.GetEmail())
+		return nil, domain.ErrInvalidCredentials
+	}
+
+	// 4. Users with MFA get a challenge instead of tokens. Failures are
+	// kept until the second factor passes too, so wrong MFA codes add
+	// up across challenges
+	methods, err := s.mfaMethods(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(methods) > 0 {
+		challengeID, err := s.startMFAChallenge(ctx, user, request.GetClientId())
+		if err != nil {
+			return nil, err
+		}
+
+		s.log.InfoContext(ctx, "Login requires mfa",
+			slog.Int64("user_id", user.ID),
+		)
+
+		return &authorizationservicev1.LoginResponse{
+			MfaRequired:    true,
+			MfaChallengeId: challengeID,
+			MfaMethods:     methods,
+		}, nil
+	}
+
+	s.resetLoginFailures(ctx, request.GetEmail())
+
+	// 5. Start a new refresh token family and issue tokens
+	tokens, err := s.startSession(ctx, user, request.GetClientId())
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "Login completed",
+		slog.Int64("user_id", user.ID),
+		slog.String("client_id", request.GetClientId()),
+	)
+
+	return &authorizationservicev1.LoginResponse{
+		AccessToken:  tokens.accessToken,
+		RefreshToken: tokens.refreshToken,
+		TokenType:    tokenTypeBearer,
+		ExpiresIn:    tokens.expiresIn,
+	}, nil
 }
 
 func (s *AuthService) RefreshToken(
 	ctx context.Context,
 	request *authorizationservicev1.RefreshTokenRequest,
 ) (*authorizationservicev1.RefreshTokenResponse, error) {
-	// TODO: implement refresh via Hydra.
-	s.log.InfoContext(ctx, "RefreshToken not implemented yet")
-	return nil, statusUnimplemented("RefreshToken")
+
+	// 1. Find token and make sure it belongs to the calling client
+	current, err := s.refreshTokens.Get(ctx, request.GetRefreshToken())
+	if err != nil {
+		return nil, refreshTokenError(err)
+	}
+	if current.ClientID != request.GetClientId() {
+		return nil, domain.ErrRefreshTokenInvalid
+	}
+
+	// 2. Rotate: the presented token becomes used, a new one replaces it
+	newRefreshToken, err := newOpaqueToken()
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to generate refresh token", slog.Any("err", err))
+		return nil, domain.Internal("failed to issue token")
+	}
+
+	rotated, err := s.refreshTokens.Rotate(ctx, request.GetRefreshToken(), newRefreshToken)
+	if err != nil {
+		if errors.Is(err, refreshrepo.ErrReused) {
+			s.log.WarnContext(ctx, "refresh token reuse detected",
+				slog.String("family_id", current.FamilyID),
+				slog.Int64("user_id", current.UserID),
+			)
+		}
+		return nil, refreshTokenError(err)
+	}
+
+	// 3. Issue a new access token for the same session
+	accessToken, _, err := s.tokens.NewAccessToken(rotated.UserID, rotated.ClientID, rotated.FamilyID, rotated.Scope)
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to issue access token", slog.Any("err", err))
+		return nil, domain.Internal("failed to issue token")
+	}
+
+	s.log.InfoContext(ctx, "RefreshToken completed",
+		slog.Int64("user_id", rotated.UserID),
+		slog.String("client_id", rotated.ClientID),
+	)
+
+	return &authorizationservicev1.RefreshTokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: newRefreshToken,
+		TokenType:    tokenTypeBearer,
+		ExpiresIn:    int64(s.tokens.TTL() / time.Second),
+	}, nil
 }
 
 func (s *AuthService) Logout(
 	ctx context.Context,
 	request *authorizationservicev1.LogoutRequest,
 ) (*authorizationservicev1.LogoutResponse, error) {
-	// TODO: implement logout (refresh token/session revocation) via Hydra.
-	s.log.InfoContext(ctx, "Logout not implemented yet")
-	return nil, statusUnimplemented("Logout")
+
+	// 1. Find token. Unknown, expired or already revoked token
+	// means there is nothing to log out from.
+	current, err := s.refreshTokens.Get(ctx, request.GetRefreshToken())
+	if errors.Is(err, refreshrepo.ErrNotFound) || errors.Is(err, refreshrepo.ErrRevoked) {
+		return &authorizationservicev1.LogoutResponse{}, nil
+	}
+	if err != nil {
+		return nil, domain.Internal("failed to find refresh token")
+	}
+	if current.ClientID != request.GetClientId() {
+		return nil, domain.ErrRefreshTokenInvalid
+	}
+
+	// 2. Revoke the whole family, so tokens rotated from it stop working too
+	err = s.refreshTokens.RevokeFamily(ctx, current.FamilyID)
+	if err != nil && !errors.Is(err, refreshrepo.ErrNotFound) {
+		return nil, domain.Internal("failed to revoke refresh token")
+	}
+
+	s.log.InfoContext(ctx, "Logout completed",
+		slog.Int64("user_id", current.UserID),
+		slog.String("family_id", current.FamilyID),
+	)
+
+	return &authorizationservicev1.LogoutResponse{}, nil
+}
+
+// issuedTokens is a pair of tokens issued for a session.
+type issuedTokens struct {
+	accessToken  string
+	refreshToken string
+	expiresIn    int64
+}
+
+// startSession creates a new refresh token family for the user and client
+// and issues the first access/refresh token pair of it. The caller's
+// user agent and IP are recorded so the session can be recognized later.
+func (s *AuthService) startSession(ctx context.Context, user domain.User, clientID string) (issuedTokens, error) {
+	refreshToken, err := newOpaqueToken()
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to generate refresh token", slog.Any("err", err))
+		return issuedTokens{}, domain.Internal("failed to issue token")
+	}
+
+	userAgent, ip := s.clientFromContext(ctx)
+
+	family, err := s.refreshTokens.Create(ctx, refreshToken, refreshrepo.SessionInfo{
+		UserID:    user.ID,
+		ClientID:  clientID,
+		Scope:     strings.Join(user.Scopes, " "),
+		UserAgent: userAgent,
+		IP:        ip,
+	})
+	if err != nil {
+		return issuedTokens{}, domain.Internal("failed to issue token")
+	}
+
+	accessToken, _, err := s.tokens.NewAccessToken(user.ID, clientID, family.FamilyID, family.Scope)
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to issue access token", slog.Any("err", err))
+		return issuedTokens{}, domain.Internal("failed to issue token")
+	}
+
+	return issuedTokens{
+		accessToken:  accessToken,
+		refreshToken: refreshToken,
+		expiresIn:    int64(s.tokens.TTL() / time.Second),
+	}, nil
+}
+
+// authenticate verifies the access token passed in the authorization
+// metadata, checks that its session has not been revoked and returns its claims.
+func (s *AuthService) authenticate(ctx context.Context) (*jwt.Claims, int64, error) {
+	token, ok := bearerToken(ctx)
+	if !ok {
+		return nil, 0, domain.ErrAccessTokenRequired
+	}
+
+	claims, err := s.tokens.Parse(token)
+	if err != nil {
+		return nil, 0, domain.ErrAccessTokenInvalid
+	}
+
+	userID, err := claims.UserID()
+	if err != nil {
+		return nil, 0, domain.ErrAccessTokenInvalid
+	}
+
+	active, err := s.tokenActive(ctx, claims, userID)
+	if err != nil {
+		return nil, 0, domain.Internal("failed to check access token")
+	}
+	if !active {
+		return nil, 0, domain.ErrAccessTokenRevoked
+	}
+
+	return claims, userID, nil
 }
@@ -0,0 +1,164 @@
+package authentication
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"time"
+
+	authorizationservicev1 "github.com/GrishanyaaShustov/CloudStorage-Protos-Service/gen/go/authorization-service"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/domain"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/lib/jwt"
+	refreshrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/refreshtoken"
+)
+
+// scopeSessionsAdmin lets support staff manage sessions of any user.
+const scopeSessionsAdmin = "sessions:admin"
+
+// ListSessions returns active sessions of the caller, or of the
+// requested user when the caller has the sessions:admin scope.
+func (s *AuthService) ListSessions(
+	ctx context.Context,
+	request *authorizationservicev1.ListSessionsRequest,
+) (*authorizationservicev1.ListSessionsResponse, error) {
+
+	// 1. Authenticate and resolve whose sessions are requested
+	claims, userID, err := s.sessionOwner(ctx, request.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Load sessions
+	sessions, err := s.refreshTokens.ListSessions(ctx, userID)
+	if err != nil {
+		return nil, domain.Internal("failed to list sessions")
+	}
+
+	resp := &authorizationservicev1.ListSessionsResponse{
+		Sessions: make([]*authorizationservicev1.Session, 0, len(sessions)),
+	}
+	for _, session := range sessions {
+		resp.Sessions = append(resp.Sessions, &authorizationservicev1.Session{
+			SessionId:     session.ID,
+			ClientId:      session.ClientID,
+			UserAgent:     session.UserAgent,
+			Ip:            session.IP,
+			CreatedAt:     timestamppb.New(session.CreatedAt),
+			LastRefreshAt: timestamppb.New(session.LastRefreshAt),
+			Current:       session.ID == claims.SessionID,
+		})
+	}
+
+	return resp, nil
+}
+
+// RevokeSession signs a single session out: its refresh tokens
+// stop working and its access tokens fail introspection.
+func (s *AuthService) RevokeSession(
+	ctx context.Context,
+	request *authorizationservicev1.RevokeSessionRequest,
+) (*authorizationservicev1.RevokeSessionResponse, error) {
+
+	// 1. Authenticate and resolve whose session is revoked
+	claims, userID, err := s.sessionOwner(ctx, request.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Make sure the session belongs to that user
+	session, err := s.refreshTokens.GetFamily(ctx, request.GetSessionId())
+	if errors.Is(err, refreshrepo.ErrRevoked) {
+		return &authorizationservicev1.RevokeSessionResponse{}, nil
+	}
+	if errors.Is(err, refreshrepo.ErrNotFound) || (err == nil && session.UserID != userID) {
+		return nil, domain.ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, domain.Internal("failed to find session")
+	}
+
+	// 3. Revoke the token family
+	err = s.refreshTokens.RevokeFamily(ctx, session.FamilyID)
+	if err != nil && !errors.Is(err, refreshrepo.ErrNotFound) {
+		return nil, domain.Internal("failed to revoke session")
+	}
+
+	s.log.InfoContext(ctx, "RevokeSession completed",
+		slog.Int64("user_id", userID),
+		slog.String("session_id", session.FamilyID),
+		slog.String("revoked_by", claims.Subject),
+	)
+
+	return &authorizationservicev1.RevokeSessionResponse{}, nil
+}
+
+// RevokeAllSessions signs the user out everywhere, optionally keeping
+// the caller's current session. When staff revoke sessions of another
+// user, all access tokens issued to that user are revoked as well.
+func (s *AuthService) RevokeAllSessions(
+	ctx context.Context,
+	request *authorizationservicev1.RevokeAllSessionsRequest,
+) (*authorizationservicev1.RevokeAllSessionsResponse, error) {
+
+	// 1. Authenticate and resolve whose sessions are revoked
+	claims, userID, err := s.sessionOwner(ctx, request.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	callerID, _ := claims.UserID()
+	self := callerID == userID
+
+	keep := ""
+	if self && request.GetKeepCurrent() {
+		keep = claims.SessionID
+	}
+
+	// 2. Revoke token families
+	if err := s.refreshTokens.RevokeUser(ctx, userID, keep); err != nil {
+		return nil, domain.Internal("failed to revoke sessions")
+	}
+
+	// 3. Admin action: access tokens without a session stop working too
+	if !self {
+		if err := s.revocations.RevokeUser(ctx, userID, time.Now()); err != nil {
+			return nil, domain.Internal("failed to revoke sessions")
+		}
+	}
+
+	s.log.InfoContext(ctx, "RevokeAllSessions completed",
+		slog.Int64("user_id", userID),
+		slog.String("revoked_by", claims.Subject),
+		slog.Bool("keep_current", keep != ""),
+	)
+
+	return &authorizationservicev1.RevokeAllSessionsResponse{}, nil
+}
+
+// sessionOwner authenticates the caller and returns the ID of the user
+// whose sessions are managed: the caller when requested is empty,
+// otherwise the requested user, which needs the sessions:admin scope.
+func (s *AuthService) sessionOwner(ctx context.Context, requested string) (*jwt.Claims, int64, error) {
+	claims, callerID, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if requested == "" || requested == claims.Subject {
+		return claims, callerID, nil
+	}
+
+	if !hasScope(claims.Scope, scopeSessionsAdmin) {
+		return nil, 0, domain.ErrSessionsForbidden
+	}
+
+	userID, err := strconv.ParseInt(requested, 10, 64)
+	if err != nil {
+		return nil, 0, domain.NewError(domain.CodeInvalidArgument, "invalid user_id")
+	}
+
+	return claims, userID, nil
+}
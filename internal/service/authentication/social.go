@@ -0,0 +1,274 @@
+package authentication
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+
+	authorizationservicev1 "github.com/GrishanyaaShustov/CloudStorage-Protos-Service/gen/go/authorization-service"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/domain"
+	ticketrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/ticket"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/transaction"
+	userrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/user"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/social"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/validation"
+)
+
+// socialLogin is a started social login kept in the ticket store
+// under its OAuth2 state until the provider redirects back.
+type socialLogin struct {
+	Provider string `json:"provider"`
+	ClientID string `json:"client_id"`
+	// Verifier is the PKCE code verifier.
+	Verifier string `json:"verifier"`
+	// UserID is set when the identity is linked with LinkIdentity
+	// rather than used to sign in.
+	UserID int64 `json:"user_id,omitempty"`
+}
+
+// StartSocialLogin returns the provider URL the user is sent to.
+// The state returned along with it comes back in the redirect
+// and is passed to CompleteSocialLogin, or to LinkIdentity when
+// the login was started with link set by a signed in user.
+func (s *AuthService) StartSocialLogin(
+	ctx context.Context,
+	request *authorizationservicev1.StartSocialLoginRequest,
+) (*authorizationservicev1.StartSocialLoginResponse, error) {
+
+	// 1. Find the provider
+	provider, ok := s.providers[request.GetProvider()]
+	if !ok {
+		return nil, domain.ErrUnsupportedProvider
+	}
+
+	// 2. Keep state and PKCE verifier until the callback
+	state, err := newOpaqueToken()
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to generate oauth state", slog.Any("err", err))
+		return nil, domain.Internal("failed to start social login")
+	}
+
+	login := socialLogin{
+		Provider: provider.Name(),
+		ClientID: request.GetClientId(),
+		Verifier: social.NewVerifier(),
+	}
+
+	if request.GetLink() {
+		_, userID, err := s.authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		login.UserID = userID
+	}
+
+	value, err := json.Marshal(login)
+	if err != nil {
+		return nil, domain.Internal("failed to start social login")
+	}
+
+	if err := s.tickets.Create(ctx, ticketrepo.KindSocialLogin, state, value, s.cfg.Social.StateTTL); err != nil {
+		return nil, domain.Internal("failed to start social login")
+	}
+
+	return &authorizationservicev1.StartSocialLoginResponse{
+		AuthorizeUrl: provider.AuthCodeURL(state, login.Verifier),
+		State:        state,
+	}, nil
+}
+
+// CompleteSocialLogin exchanges the authorization code, finds or creates
+// the user and issues tokens. Users with MFA get a challenge instead,
+// exactly as with Login.
+func (s *AuthService) CompleteSocialLogin(
+	ctx context.Context,
+	request *authorizationservicev1.CompleteSocialLoginRequest,
+) (*authorizationservicev1.CompleteSocialLoginResponse, error) {
+
+	// 1. Take the started login; a state can be used only once
+	login, err := s.consumeSocialLogin(ctx, request.GetProvider(), request.GetState(), request.GetClientId())
+	if err != nil {
+		return nil, err
+	}
+	if login.UserID != 0 {
+		return nil, domain.ErrSocialLoginNotFound
+	}
+
+	// 2. Exchange the code for the provider identity
+	identity, err := s.exchangeSocialCode(ctx, login, request.GetCode())
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Find or create the user
+	user, created, err := s.socialUser(ctx, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	// 4. Users with MFA get a challenge instead of tokens
+	methods, err := s.mfaMethods(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(methods) > 0 {
+		challengeID, err := s.startMFAChallenge(ctx, user, login.ClientID)
+		if err != nil {
+			return nil, err
+		}
+
+		s.log.InfoContext(ctx, "CompleteSocialLogin requires mfa",
+			slog.Int64("user_id", user.ID),
+			slog.String("provider", login.Provider),
+		)
+
+		return &authorizationservicev1.CompleteSocialLoginResponse{
+			MfaRequired:    true,
+			MfaChallengeId: challengeID,
+			MfaMethods:     methods,
+		}, nil
+	}
+
+	// 5. Issue tokens
+	tokens, err := s.startSession(ctx, user, login.ClientID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "CompleteSocialLogin completed",
+		slog.Int64("user_id", user.ID),
+		slog.String("provider", login.Provider),
+		slog.String("client_id", login.ClientID),
+		slog.Bool("user_created", created),
+	)
+
+	return &authorizationservicev1.CompleteSocialLoginResponse{
+		AccessToken:  tokens.accessToken,
+		RefreshToken: tokens.refreshToken,
+		TokenType:    tokenTypeBearer,
+		ExpiresIn:    tokens.expiresIn,
+		UserCreated:  created,
+	}, nil
+}
+
+// consumeSocialLogin takes a started social login out of the store.
+func (s *AuthService) consumeSocialLogin(ctx context.Context, provider, state, clientID string) (socialLogin, error) {
+	value, err := s.tickets.Consume(ctx, ticketrepo.KindSocialLogin, state)
+	if err != nil {
+		if errors.Is(err, ticketrepo.ErrNotFound) {
+			return socialLogin{}, domain.ErrSocialLoginNotFound
+		}
+		return socialLogin{}, domain.Internal("failed to complete social login")
+	}
+
+	var login socialLogin
+	if err := json.Unmarshal(value, &login); err != nil {
+		s.log.ErrorContext(ctx, "malformed social login", slog.Any("err", err))
+		return socialLogin{}, domain.Internal("failed to complete social login")
+	}
+	if login.Provider != provider || login.ClientID != clientID {
+		return socialLogin{}, domain.ErrSocialLoginNotFound
+	}
+
+	return login, nil
+}
+
+// exchangeSocialCode redeems the authorization code at the provider
+// the login was started with.
+func (s *AuthService) exchangeSocialCode(ctx context.Context, login socialLogin, code string) (social.Identity, error) {
+	provider, ok := s.providers[login.Provider]
+	if !ok {
+		return social.Identity{}, domain.ErrUnsupportedProvider
+	}
+
+	identity, err := provider.Exchange(ctx, code, login.Verifier)
+	if err != nil {
+		if errors.Is(err, social.ErrNoVerifiedEmail) {
+			return social.Identity{}, domain.ErrProviderEmailNotVerified
+		}
+		s.log.InfoContext(ctx, "social login rejected",
+			slog.String("provider", login.Provider),
+			slog.Any("err", err),
+		)
+		return social.Identity{}, domain.ErrProviderAuthFailed
+	}
+
+	// Providers return emails as typed by their users; normalize them
+	// like emails of requests so the same address maps to one account.
+	identity.Email, err = validation.NormalizeEmail(identity.Email)
+	if err != nil {
+		s.log.InfoContext(ctx, "social login rejected",
+			slog.String("provider", login.Provider),
+			slog.Any("err", err),
+		)
+		return social.Identity{}, domain.ErrProviderEmailNotVerified
+	}
+
+	return identity, nil
+}
+
+// socialUser returns the user linked to the identity or creates a new
+// user without a password. An existing account with the same email is
+// never merged silently: its owner has to sign in and link the identity
+// with LinkIdentity.
+func (s *AuthService) socialUser(ctx context.Context, identity social.Identity) (domain.User, bool, error) {
+	user, err := s.users.GetByProviderID(ctx, identity.Provider, identity.Subject)
+	if err == nil {
+		return user, false, nil
+	}
+	if !errors.Is(err, userrepo.ErrNotFound) {
+		return domain.User{}, false, domain.Internal("failed to find user")
+	}
+
+	_, err = s.users.GetByEmail(ctx, identity.Email)
+	if err == nil {
+		return domain.User{}, false, domain.ErrIdentityLinkRequired
+	}
+	if !errors.Is(err, userrepo.ErrNotFound) {
+		return domain.User{}, false, domain.Internal("failed to find user")
+	}
+
+	newUser := domain.User{
+		Email:         identity.Email,
+		Login:         identity.Login,
+		EmailVerified: true,
+	}
+	switch identity.Provider {
+	case userrepo.ProviderGitHub:
+		newUser.GithubID = &identity.Subject
+	case userrepo.ProviderGoogle:
+		newUser.GoogleID = &identity.Subject
+	}
+
+	var created domain.User
+	err = s.tx.Do(ctx, transaction.Options{}, func(ctx context.Context) error {
+		var err error
+		created, err = s.users.Create(ctx, newUser)
+		if err != nil {
+			return err
+		}
+
+		return s.addEvent(ctx, created.ID, domain.EventUserRegistered, domain.UserRegistered{
+			Email:         created.Email,
+			Login:         created.Login,
+			Provider:      identity.Provider,
+			EmailVerified: created.EmailVerified,
+		})
+	})
+	if err != nil {
+		// A concurrent login created the user first: either the same
+		// identity, which is then used, or another one with this email.
+		if errors.Is(err, userrepo.ErrAlreadyExists) {
+			if user, err := s.users.GetByProviderID(ctx, identity.Provider, identity.Subject); err == nil {
+				return user, false, nil
+			}
+			return domain.User{}, false, domain.ErrIdentityLinkRequired
+		}
+		s.log.ErrorContext(ctx, "failed to create user", slog.Any("err", err))
+		return domain.User{}, false, domain.Internal("failed to create user")
+	}
+
+	return created, true, nil
+}
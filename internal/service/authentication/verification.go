@@ -0,0 +1,169 @@
+package authentication
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"time"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/domain"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/mailer"
+	verificationrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/verification"
+)
+
+// Verification flow purposes. A flow started for one purpose
+// can not be completed by an RPC serving another one.
+const (
+	purposeVerifyEmail = "verify_email"
+	purposeLoginCode   = "login_code"
+)
+
+// startEmailVerification starts a verification flow for the user's email
+// and sends the code. It returns the flow ID the client has to present
+// along with the code.
+func (s *AuthService) startEmailVerification(ctx context.Context, user domain.User) (string, error) {
+	flowID, code, err := s.startVerification(ctx, purposeVerifyEmail, user)
+	if err != nil {
+		return "", err
+	}
+
+	data := mailer.VerificationCodeData{
+		Code:             code,
+		ExpiresInMinutes: int(s.cfg.Verification.TTL.Minutes()),
+	}
+
+	// Failing to queue the code does not fail the registration:
+	// the user already exists and the flow can be restarted.
+	err = s.mail.Send(ctx, user.Email, localeFromContext(ctx), mailer.TemplateVerifyEmail, data)
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to send verification code",
+			slog.Int64("user_id", user.ID),
+			slog.Any("err", err),
+		)
+	}
+
+	return flowID, nil
+}
+
+// startVerification stores a new flow with a random numeric code.
+// Only a hash of the code is stored; the code itself is returned
+// to be delivered to the user.
+func (s *AuthService) startVerification(ctx context.Context, purpose string, user domain.User) (string, string, error) {
+	code, err := newNumericCode(s.cfg.Verification.CodeLength)
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to generate verification code", slog.Any("err", err))
+		return "", "", domain.Internal("failed to start verification")
+	}
+
+	flow := verificationrepo.Flow{
+		Purpose: purpose,
+		UserID:  user.ID,
+		Email:   user.Email,
+	}
+
+	flowID, err := s.createVerification(ctx, flow, code, s.cfg.Verification.TTL)
+	if err != nil {
+		return "", "", err
+	}
+
+	return flowID, code, nil
+}
+
+// createVerification stores the flow under a new random ID along with
+// a hash of code and returns the ID.
+func (s *AuthService) createVerification(ctx context.Context, flow verificationrepo.Flow, code string, ttl time.Duration) (string, error) {
+	flowID, err := newOpaqueToken()
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to generate flow id", slog.Any("err", err))
+		return "", domain.Internal("failed to start verification")
+	}
+
+	flow.ID = flowID
+	flow.CodeHash = hashCode(flowID, code)
+
+	if err := s.verifications.Create(ctx, flow, ttl); err != nil {
+		return "", domain.Internal("failed to start verification")
+	}
+
+	return flowID, nil
+}
+
+// completeVerification registers an attempt to complete the flow with
+// the given code. The attempt is counted before the code is compared,
+// so concurrent guesses can not exceed the attempt limit.
+// The flow is deleted once the code matches.
+func (s *AuthService) completeVerification(ctx context.Context, purpose, flowID, code string) (verificationrepo.Flow, error) {
+	flow, err := s.attemptVerification(ctx, purpose, flowID)
+	if err != nil {
+		return verificationrepo.Flow{}, err
+	}
+
+	if !codeMatches(flow, code) {
+		return verificationrepo.Flow{}, s.invalidCodeError(flow)
+	}
+
+	if err := s.verifications.Delete(ctx, flowID); err != nil {
+		return verificationrepo.Flow{}, domain.Internal("failed to complete verification")
+	}
+
+	return flow, nil
+}
+
+// attemptVerification counts an attempt to complete the flow and
+// returns it unless the flow is of another purpose or locked.
+// Flows whose codes are not stored (e.g. MFA challenges) check
+// the code themselves and use invalidCodeError on mismatch.
+func (s *AuthService) attemptVerification(ctx context.Context, purpose, flowID string) (verificationrepo.Flow, error) {
+	flow, err := s.verifications.Attempt(ctx, flowID)
+	if err != nil {
+		return verificationrepo.Flow{}, verificationError(err)
+	}
+	if flow.Purpose != purpose {
+		return verificationrepo.Flow{}, domain.ErrFlowNotFound
+	}
+
+	if flow.Attempts > s.cfg.Verification.MaxAttempts {
+		return verificationrepo.Flow{}, domain.ErrFlowLocked
+	}
+
+	return flow, nil
+}
+
+// invalidCodeError reports a wrong code; the last allowed attempt locks the flow.
+func (s *AuthService) invalidCodeError(flow verificationrepo.Flow) error {
+	if flow.Attempts == s.cfg.Verification.MaxAttempts {
+		return domain.ErrFlowLocked
+	}
+	return domain.ErrInvalidCode
+}
+
+// codeMatches compares the code with the hash stored in the flow
+// in constant time.
+func codeMatches(flow verificationrepo.Flow, code string) bool {
+	return hmac.Equal([]byte(flow.CodeHash), []byte(hashCode(flow.ID, code)))
+}
+
+// newNumericCode generates a uniformly distributed decimal code of n digits.
+func newNumericCode(n int) (string, error) {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+
+	v, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%0*d", n, v), nil
+}
+
+// hashCode binds the code to its flow, so equal codes of different
+// flows produce different hashes.
+func hashCode(flowID, code string) string {
+	mac := hmac.New(sha256.New, []byte(flowID))
+	mac.Write([]byte(code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
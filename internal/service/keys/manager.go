@@ -0,0 +1,304 @@
+package keys
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/config"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/lib/jwt"
+	signingkeyrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/signingkey"
+)
+
+// Manager holds token signing keys. Keys are either loaded from PEM
+// files (static mode) or generated, persisted in the database and
+// rotated on schedule. Retired keys stay published until every token
+// they have signed expires.
+type Manager struct {
+	log  *slog.Logger
+	repo signingkeyrepo.Repository
+	cfg  config.KeysConfig
+
+	// publishFor is how long a retired key stays published.
+	publishFor time.Duration
+
+	mu     sync.RWMutex
+	keys   []managedKey
+	byID   map[string]*jwt.Key
+	static bool
+}
+
+type managedKey struct {
+	key         *jwt.Key
+	createdAt   time.Time
+	activatesAt time.Time
+	retiredAt   *time.Time
+}
+
+// NewManager creates a key manager. tokenTTL is a lifetime of tokens
+// signed by the keys; retired keys are published that much longer.
+func NewManager(log *slog.Logger, repo signingkeyrepo.Repository, cfg config.KeysConfig, tokenTTL time.Duration) *Manager {
+	return &Manager{
+		log:        log,
+		repo:       repo,
+		cfg:        cfg,
+		publishFor: tokenTTL + cfg.RefreshInterval,
+		byID:       make(map[string]*jwt.Key),
+		static:     len(cfg.Files) > 0,
+	}
+}
+
+// Ensure interface implementation at compile time.
+var _ jwt.KeyStore = (*Manager)(nil)
+
+// Load loads the keys. In database mode a first key is generated
+// when there is none.
+func (m *Manager) Load(ctx context.Context) error {
+	if m.static {
+		return m.loadFiles()
+	}
+
+	if err := m.reload(ctx); err != nil {
+		return err
+	}
+
+	if _, err := m.SigningKey(); err != nil {
+		if err := m.rotate(ctx, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MustLoad loads the keys and panics on any error.
+// Used in the application's startup layer.
+func (m *Manager) MustLoad(ctx context.Context) {
+	if err := m.Load(ctx); err != nil {
+		panic(err)
+	}
+}
+
+// Run periodically reloads keys from the database and rotates
+// them when the signing key gets older than the rotation period.
+// It blocks until ctx is canceled. In static mode it returns immediately.
+func (m *Manager) Run(ctx context.Context) {
+	if m.static {
+		return
+	}
+
+	ticker := time.NewTicker(m.cfg.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.tick(ctx); err != nil {
+				m.log.Error("failed to refresh signing keys", slog.Any("err", err))
+			}
+		}
+	}
+}
+
+func (m *Manager) tick(ctx context.Context) error {
+	if m.rotationDue() {
+		// The new key is published now and starts signing after the
+		// refresh interval, when every replica has loaded it.
+		if err := m.rotate(ctx, time.Now().Add(m.cfg.RefreshInterval)); err != nil {
+			return err
+		}
+
+		if err := m.repo.DeleteRetired(ctx, time.Now().Add(-m.publishFor)); err != nil {
+			return err
+		}
+	}
+
+	return m.reload(ctx)
+}
+
+// rotationDue reports whether the newest key is older than the rotation period.
+func (m *Manager) rotationDue() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if len(m.keys) == 0 {
+		return true
+	}
+
+	newest := m.keys[len(m.keys)-1]
+	return time.Since(newest.createdAt) >= m.cfg.RotationPeriod
+}
+
+// rotate generates a key which signs from activatesAt and stores it.
+func (m *Manager) rotate(ctx context.Context, activatesAt time.Time) error {
+	const op = "keys.Manager.rotate"
+
+	kid, err := newKeyID()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	key, err := jwt.GenerateKey(kid, m.cfg.Algorithm, m.cfg.RSABits)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	pem, err := key.MarshalPrivateKeyPEM()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	rotated, err := m.repo.Rotate(ctx, signingkeyrepo.Key{
+		ID:            kid,
+		Algorithm:     key.Algorithm,
+		PrivateKeyPEM: pem,
+		ActivatesAt:   activatesAt,
+	}, time.Now().Add(-m.cfg.RotationPeriod))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if rotated {
+		m.log.Info("signing key rotated",
+			slog.String("kid", kid),
+			slog.String("alg", key.Algorithm),
+			slog.Time("activates_at", activatesAt),
+		)
+	}
+
+	return m.reload(ctx)
+}
+
+// reload replaces in-memory keys with published keys from the database.
+func (m *Manager) reload(ctx context.Context) error {
+	const op = "keys.Manager.reload"
+
+	stored, err := m.repo.ListPublished(ctx, time.Now().Add(-m.publishFor))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	keys := make([]managedKey, 0, len(stored))
+	for _, s := range stored {
+		key, err := jwt.ParsePrivateKeyPEM(s.ID, s.PrivateKeyPEM)
+		if err != nil {
+			return fmt.Errorf("%s: key %s: %w", op, s.ID, err)
+		}
+
+		keys = append(keys, managedKey{
+			key:         key,
+			createdAt:   s.CreatedAt,
+			activatesAt: s.ActivatesAt,
+			retiredAt:   s.RetiredAt,
+		})
+	}
+
+	m.set(keys)
+	return nil
+}
+
+// loadFiles loads static keys; the first one signs, the rest only verify.
+func (m *Manager) loadFiles() error {
+	const op = "keys.Manager.loadFiles"
+
+	now := time.Now()
+	keys := make([]managedKey, 0, len(m.cfg.Files))
+
+	for i, f := range m.cfg.Files {
+		data, err := os.ReadFile(f.Path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		key, err := jwt.ParsePrivateKeyPEM(f.KID, data)
+		if err != nil {
+			return fmt.Errorf("%s: %s: %w", op, f.Path, err)
+		}
+
+		mk := managedKey{key: key, createdAt: now, activatesAt: now}
+		if i > 0 {
+			mk.retiredAt = &now
+		}
+		keys = append(keys, mk)
+	}
+
+	m.set(keys)
+	return nil
+}
+
+func (m *Manager) set(keys []managedKey) {
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].activatesAt.Before(keys[j].activatesAt)
+	})
+
+	byID := make(map[string]*jwt.Key, len(keys))
+	for _, k := range keys {
+		byID[k.key.ID] = k.key
+	}
+
+	m.mu.Lock()
+	m.keys = keys
+	m.byID = byID
+	m.mu.Unlock()
+}
+
+// SigningKey returns the most recently activated key which is not retired.
+func (m *Manager) SigningKey() (*jwt.Key, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	for i := len(m.keys) - 1; i >= 0; i-- {
+		k := m.keys[i]
+		if k.activatesAt.After(now) {
+			continue
+		}
+		if k.retiredAt != nil && !k.retiredAt.After(now) {
+			continue
+		}
+		return k.key, nil
+	}
+
+	return nil, errors.New("no active signing key")
+}
+
+// VerificationKey returns a published key by its ID.
+func (m *Manager) VerificationKey(kid string) (*jwt.Key, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	key, ok := m.byID[kid]
+	if !ok {
+		return nil, jwt.ErrKeyNotFound
+	}
+	return key, nil
+}
+
+// JWKS returns public parts of all published keys.
+func (m *Manager) JWKS() jwt.JWKS {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	set := jwt.JWKS{Keys: make([]jwt.JWK, 0, len(m.keys))}
+	for _, k := range m.keys {
+		set.Keys = append(set.Keys, k.key.JWK())
+	}
+	return set
+}
+
+func newKeyID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
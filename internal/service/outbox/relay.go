@@ -0,0 +1,174 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/config"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/domain"
+	outboxrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/outbox"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/transaction"
+)
+
+// cleanupBatchSize bounds how many published events one DELETE removes,
+// so cleanup does not hold long locks on the table.
+const cleanupBatchSize = 1000
+
+// Relay publishes events from the outbox to a sink. Several replicas
+// may run relays at once: events are claimed with SKIP LOCKED, so each
+// one is published by a single relay at a time. Delivery is at least
+// once; events of a user are published in order.
+type Relay struct {
+	log    *slog.Logger
+	tx     transaction.Manager
+	events outboxrepo.Repository
+	sink   outboxrepo.Sink
+	cfg    config.OutboxConfig
+}
+
+// NewRelay creates an outbox relay.
+func NewRelay(
+	log *slog.Logger,
+	tx transaction.Manager,
+	events outboxrepo.Repository,
+	sink outboxrepo.Sink,
+	cfg config.OutboxConfig,
+) *Relay {
+	return &Relay{
+		log:    log,
+		tx:     tx,
+		events: events,
+		sink:   sink,
+		cfg:    cfg,
+	}
+}
+
+// Run publishes pending events every poll interval and deletes old
+// published events every cleanup interval. It blocks until ctx is canceled.
+func (r *Relay) Run(ctx context.Context) {
+	poll := time.NewTicker(r.cfg.PollInterval)
+	defer poll.Stop()
+
+	cleanup := time.NewTicker(r.cfg.CleanupInterval)
+	defer cleanup.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-poll.C:
+			if err := r.drain(ctx); err != nil {
+				r.log.Error("failed to publish outbox events", slog.Any("err", err))
+			}
+		case <-cleanup.C:
+			if err := r.cleanup(ctx); err != nil {
+				r.log.Error("failed to clean up outbox", slog.Any("err", err))
+			}
+		}
+	}
+}
+
+// drain publishes batches until fewer events than the batch size are due.
+func (r *Relay) drain(ctx context.Context) error {
+	for ctx.Err() == nil {
+		n, err := r.publishBatch(ctx)
+		if err != nil {
+			return err
+		}
+		if n < r.cfg.BatchSize {
+			return nil
+		}
+	}
+	return nil
+}
+
+// publishBatch claims a batch of events and publishes them in one
+// transaction, which keeps the events locked until they are marked.
+func (r *Relay) publishBatch(ctx context.Context) (int, error) {
+	claimed := 0
+
+	err := r.tx.Do(ctx, transaction.Options{}, func(ctx context.Context) error {
+		events, err := r.events.Claim(ctx, r.cfg.BatchSize)
+		if err != nil {
+			return err
+		}
+		claimed = len(events)
+
+		for _, e := range events {
+			if err := r.publish(ctx, e); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	return claimed, err
+}
+
+// publish sends one event to the sink and records the outcome. Only
+// storage errors are returned; a failed publication is retried later
+// with exponential backoff.
+func (r *Relay) publish(ctx context.Context, e outboxrepo.Event) error {
+	body, err := json.Marshal(domain.EventEnvelope{
+		ID:            strconv.FormatInt(e.ID, 10),
+		Type:          domain.EventType(e.Type),
+		SchemaVersion: e.SchemaVersion,
+		UserID:        strconv.FormatInt(e.UserID, 10),
+		OccurredAt:    e.CreatedAt.UTC(),
+		Data:          json.RawMessage(e.Payload),
+	})
+	if err == nil {
+		err = r.sink.Publish(ctx, outboxrepo.Message{
+			Key:  strconv.FormatInt(e.UserID, 10),
+			Type: e.Type,
+			Body: body,
+		})
+	}
+	if err != nil {
+		delay := r.retryDelay(e.Attempts)
+		r.log.Warn("failed to publish outbox event",
+			slog.Int64("id", e.ID),
+			slog.String("type", e.Type),
+			slog.Int("attempt", e.Attempts+1),
+			slog.Duration("retry_in", delay),
+			slog.Any("err", err),
+		)
+		return r.events.MarkFailed(ctx, e.ID, err.Error(), time.Now().Add(delay))
+	}
+
+	return r.events.MarkPublished(ctx, e.ID)
+}
+
+// retryDelay doubles the base delay with every failed attempt, up to the max delay.
+func (r *Relay) retryDelay(attempts int) time.Duration {
+	delay := r.cfg.RetryBaseDelay
+	for i := 0; i < attempts && delay < r.cfg.RetryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, r.cfg.RetryMaxDelay)
+}
+
+// cleanup deletes events published longer than the retention period ago.
+func (r *Relay) cleanup(ctx context.Context) error {
+	before := time.Now().Add(-r.cfg.Retention)
+
+	var total int64
+	for ctx.Err() == nil {
+		n, err := r.events.DeletePublished(ctx, before, cleanupBatchSize)
+		if err != nil {
+			return err
+		}
+		total += n
+		if n < cleanupBatchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		r.log.Info("outbox cleaned up", slog.Int64("deleted", total))
+	}
+	return nil
+}
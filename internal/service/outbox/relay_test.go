@@ -0,0 +1,183 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/config"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/domain"
+	outboxrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/outbox"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/transaction"
+)
+
+// fakeTx runs fn without a transaction.
+type fakeTx struct{}
+
+func (fakeTx) Do(ctx context.Context, _ transaction.Options, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// fakeEvents claims pending events in the order they were added and
+// records what the relay marked.
+type fakeEvents struct {
+	pending   []outboxrepo.Event
+	published []int64
+	failed    []int64
+	retryAt   map[int64]time.Time
+	deleted   []int64
+}
+
+func (f *fakeEvents) Add(_ context.Context, e outboxrepo.Event) error {
+	f.pending = append(f.pending, e)
+	return nil
+}
+
+func (f *fakeEvents) Claim(_ context.Context, limit int) ([]outboxrepo.Event, error) {
+	n := min(limit, len(f.pending))
+	claimed := f.pending[:n]
+	f.pending = f.pending[n:]
+	return claimed, nil
+}
+
+func (f *fakeEvents) MarkPublished(_ context.Context, id int64) error {
+	f.published = append(f.published, id)
+	return nil
+}
+
+func (f *fakeEvents) MarkFailed(_ context.Context, id int64, _ string, retryAt time.Time) error {
+	f.failed = append(f.failed, id)
+	if f.retryAt == nil {
+		f.retryAt = make(map[int64]time.Time)
+	}
+	f.retryAt[id] = retryAt
+	return nil
+}
+
+func (f *fakeEvents) DeletePublished(_ context.Context, _ time.Time, limit int) (int64, error) {
+	n := min(limit, len(f.deleted))
+	f.deleted = f.deleted[n:]
+	return int64(n), nil
+}
+
+// fakeSink records published messages and fails for the listed keys.
+type fakeSink struct {
+	failKeys map[string]bool
+	messages []outboxrepo.Message
+}
+
+func (f *fakeSink) Publish(_ context.Context, m outboxrepo.Message) error {
+	if f.failKeys[m.Key] {
+		return errors.New("broker unavailable")
+	}
+	f.messages = append(f.messages, m)
+	return nil
+}
+
+var testConfig = config.OutboxConfig{
+	BatchSize:      2,
+	RetryBaseDelay: time.Second,
+	RetryMaxDelay:  time.Minute,
+}
+
+func newTestRelay(events *fakeEvents, sink *fakeSink) *Relay {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewRelay(log, fakeTx{}, events, sink, testConfig)
+}
+
+func TestRelayRetryDelay(t *testing.T) {
+	r := newTestRelay(&fakeEvents{}, &fakeSink{})
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 0, want: time.Second},
+		{attempts: 1, want: 2 * time.Second},
+		{attempts: 5, want: 32 * time.Second},
+		{attempts: 6, want: time.Minute},
+		{attempts: 100, want: time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := r.retryDelay(tt.attempts); got != tt.want {
+			t.Errorf("retryDelay(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestRelayDrain(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.FixedZone("MSK", 3*60*60))
+	events := &fakeEvents{}
+	for i, userID := range []int64{7, 8, 7, 9, 8} {
+		_ = events.Add(context.Background(), outboxrepo.Event{
+			ID:            int64(i + 1),
+			UserID:        userID,
+			Type:          string(domain.EventUserRegistered),
+			SchemaVersion: domain.EventSchemaVersion,
+			Payload:       []byte(`{"email":"user@example.com"}`),
+			Attempts:      2,
+			CreatedAt:     createdAt,
+		})
+	}
+	sink := &fakeSink{failKeys: map[string]bool{"8": true}}
+
+	before := time.Now()
+	if err := newTestRelay(events, sink).drain(context.Background()); err != nil {
+		t.Fatalf("drain() error = %v", err)
+	}
+
+	if len(events.pending) != 0 {
+		t.Errorf("drain() left %d events pending", len(events.pending))
+	}
+	if want := []int64{1, 3, 4}; !reflect.DeepEqual(events.published, want) {
+		t.Errorf("published = %v, want %v", events.published, want)
+	}
+	if want := []int64{2, 5}; !reflect.DeepEqual(events.failed, want) {
+		t.Errorf("failed = %v, want %v", events.failed, want)
+	}
+	// Third attempt: 1s * 2^2
+	if retryAt := events.retryAt[2]; retryAt.Before(before.Add(4*time.Second)) || retryAt.After(time.Now().Add(4*time.Second)) {
+		t.Errorf("retry of a third attempt at %v, want in 4s", retryAt)
+	}
+
+	var keys []string
+	for _, m := range sink.messages {
+		keys = append(keys, m.Key)
+	}
+	if want := []string{"7", "7", "9"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("published keys = %v, want %v", keys, want)
+	}
+
+	var envelope domain.EventEnvelope
+	if err := json.Unmarshal(sink.messages[0].Body, &envelope); err != nil {
+		t.Fatalf("message body is not an envelope: %v", err)
+	}
+	want := domain.EventEnvelope{
+		ID:            "1",
+		Type:          domain.EventUserRegistered,
+		SchemaVersion: domain.EventSchemaVersion,
+		UserID:        "7",
+		OccurredAt:    createdAt.UTC(),
+		Data:          json.RawMessage(`{"email":"user@example.com"}`),
+	}
+	if !reflect.DeepEqual(envelope, want) {
+		t.Errorf("envelope = %+v, want %+v", envelope, want)
+	}
+}
+
+func TestRelayCleanup(t *testing.T) {
+	events := &fakeEvents{deleted: make([]int64, cleanupBatchSize+10)}
+
+	if err := newTestRelay(events, &fakeSink{}).cleanup(context.Background()); err != nil {
+		t.Fatalf("cleanup() error = %v", err)
+	}
+	if len(events.deleted) != 0 {
+		t.Errorf("cleanup() left %d published events", len(events.deleted))
+	}
+}
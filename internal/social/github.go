@@ -0,0 +1,95 @@
+package social
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"golang.org/x/oauth2/endpoints"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/config"
+)
+
+const (
+	githubUserURL   = "https://api.github.com/user"
+	githubEmailsURL = "https://api.github.com/user/emails"
+)
+
+// GitHub signs users in with GitHub OAuth apps.
+type GitHub struct {
+	oauthClient
+	userURL   string
+	emailsURL string
+}
+
+// NewGitHub creates a GitHub provider. Empty endpoints in cfg
+// default to the public GitHub ones.
+func NewGitHub(cfg config.SocialProviderConfig, client *http.Client) *GitHub {
+	g := &GitHub{
+		oauthClient: newOAuthClient(cfg, endpoints.GitHub, client),
+		userURL:     cfg.UserInfoURL,
+		emailsURL:   cfg.EmailsURL,
+	}
+	if g.userURL == "" {
+		g.userURL = githubUserURL
+	}
+	if g.emailsURL == "" {
+		g.emailsURL = githubEmailsURL
+	}
+
+	return g
+}
+
+// Ensure interface implementation at compile time.
+var _ Provider = (*GitHub)(nil)
+
+func (g *GitHub) Name() string {
+	return ProviderGitHub
+}
+
+// Exchange redeems the code and looks up the account and its primary
+// verified email. GitHub does not return private emails in the profile,
+// so they are taken from the emails endpoint.
+func (g *GitHub) Exchange(ctx context.Context, code, verifier string) (Identity, error) {
+	client, err := g.exchange(ctx, code, verifier)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	var user struct {
+		ID    int64  `json:"id"`
+		Login string `json:"login"`
+	}
+	if err := getJSON(ctx, client, g.userURL, &user); err != nil {
+		return Identity{}, err
+	}
+	if user.ID == 0 {
+		return Identity{}, errors.New("github user has no id")
+	}
+
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := getJSON(ctx, client, g.emailsURL, &emails); err != nil {
+		return Identity{}, err
+	}
+
+	identity := Identity{
+		Provider: ProviderGitHub,
+		Subject:  strconv.FormatInt(user.ID, 10),
+		Login:    user.Login,
+	}
+	for _, e := range emails {
+		if e.Primary && e.Verified {
+			identity.Email = e.Email
+		}
+	}
+	if identity.Email == "" {
+		return Identity{}, ErrNoVerifiedEmail
+	}
+
+	return identity, nil
+}
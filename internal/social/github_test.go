@@ -0,0 +1,144 @@
+package social
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+type githubEmail struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+func TestGitHubExchange(t *testing.T) {
+	user := map[string]any{"id": 1234, "login": "octocat"}
+	verified := []githubEmail{{Email: "octocat@example.com", Primary: true, Verified: true}}
+
+	tests := []struct {
+		name      string
+		resources map[string]any
+		code      string
+		verifier  string
+		want      Identity
+		wantErr   error
+		wantAnErr bool
+	}{
+		{
+			name: "primary verified email",
+			resources: map[string]any{
+				"/user": user,
+				"/user/emails": []githubEmail{
+					{Email: "other@example.com", Verified: true},
+					{Email: "octocat@example.com", Primary: true, Verified: true},
+				},
+			},
+			want: Identity{Provider: ProviderGitHub, Subject: "1234", Email: "octocat@example.com", Login: "octocat"},
+		},
+		{
+			name: "primary email is not verified",
+			resources: map[string]any{
+				"/user": user,
+				"/user/emails": []githubEmail{
+					{Email: "other@example.com", Verified: true},
+					{Email: "octocat@example.com", Primary: true},
+				},
+			},
+			wantErr: ErrNoVerifiedEmail,
+		},
+		{
+			name:      "no emails",
+			resources: map[string]any{"/user": user, "/user/emails": []githubEmail{}},
+			wantErr:   ErrNoVerifiedEmail,
+		},
+		{
+			name:      "wrong verifier",
+			resources: map[string]any{"/user": user, "/user/emails": verified},
+			verifier:  "another-verifier",
+			wantAnErr: true,
+		},
+		{
+			name:      "wrong code",
+			resources: map[string]any{"/user": user, "/user/emails": verified},
+			code:      "stolen-code",
+			wantAnErr: true,
+		},
+		{
+			name:      "user endpoint fails",
+			resources: map[string]any{"/user": http.StatusInternalServerError},
+			wantAnErr: true,
+		},
+		{
+			name:      "emails endpoint fails",
+			resources: map[string]any{"/user": user, "/user/emails": http.StatusForbidden},
+			wantAnErr: true,
+		},
+		{
+			name:      "user without id",
+			resources: map[string]any{"/user": map[string]any{"login": "ghost"}},
+			wantAnErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := NewVerifier()
+			provider := newFakeProvider(t, verifier, tt.resources)
+			g := NewGitHub(provider.config(), provider.Client())
+
+			code := testCode
+			if tt.code != "" {
+				code = tt.code
+			}
+			if tt.verifier != "" {
+				verifier = tt.verifier
+			}
+
+			got, err := g.Exchange(context.Background(), code, verifier)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Exchange() error = %v, want %v", err, tt.wantErr)
+				}
+			case tt.wantAnErr:
+				if err == nil || errors.Is(err, ErrNoVerifiedEmail) {
+					t.Fatalf("Exchange() error = %v, want a provider error", err)
+				}
+			case err != nil:
+				t.Fatalf("Exchange() error = %v", err)
+			case got != tt.want:
+				t.Errorf("Exchange() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	verifier := NewVerifier()
+	provider := newFakeProvider(t, verifier, nil)
+	g := NewGitHub(provider.config(), provider.Client())
+
+	u, err := url.Parse(g.AuthCodeURL("state-1", verifier))
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+
+	sum := sha256.Sum256([]byte(verifier))
+	wantChallenge := base64.RawURLEncoding.EncodeToString(sum[:])
+	if q.Get("code_challenge") != wantChallenge || q.Get("code_challenge_method") != "S256" {
+		t.Errorf("AuthCodeURL() challenge = %q (%s), want %q (S256)",
+			q.Get("code_challenge"), q.Get("code_challenge_method"), wantChallenge)
+	}
+	if q.Get("code_verifier") != "" {
+		t.Error("AuthCodeURL() leaks the code verifier")
+	}
+	if q.Get("state") != "state-1" || q.Get("client_id") != testClientID {
+		t.Errorf("AuthCodeURL() query = %v", q)
+	}
+}
@@ -0,0 +1,73 @@
+package social
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"golang.org/x/oauth2/endpoints"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/config"
+)
+
+const googleUserInfoURL = "https://openidconnect.googleapis.com/v1/userinfo"
+
+// Google signs users in with Google accounts via OpenID Connect.
+type Google struct {
+	oauthClient
+	userInfoURL string
+}
+
+// NewGoogle creates a Google provider. Empty endpoints in cfg
+// default to the public Google ones.
+func NewGoogle(cfg config.SocialProviderConfig, client *http.Client) *Google {
+	g := &Google{
+		oauthClient: newOAuthClient(cfg, endpoints.Google, client),
+		userInfoURL: cfg.UserInfoURL,
+	}
+	if g.userInfoURL == "" {
+		g.userInfoURL = googleUserInfoURL
+	}
+
+	return g
+}
+
+// Ensure interface implementation at compile time.
+var _ Provider = (*Google)(nil)
+
+func (g *Google) Name() string {
+	return ProviderGoogle
+}
+
+// Exchange redeems the code and reads the account from the OIDC
+// userinfo endpoint, which is authenticated by the access token.
+func (g *Google) Exchange(ctx context.Context, code, verifier string) (Identity, error) {
+	client, err := g.exchange(ctx, code, verifier)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	var info struct {
+		Subject       string `json:"sub"`
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+		Name          string `json:"name"`
+	}
+	if err := getJSON(ctx, client, g.userInfoURL, &info); err != nil {
+		return Identity{}, err
+	}
+
+	if info.Subject == "" {
+		return Identity{}, errors.New("google userinfo has no subject")
+	}
+	if info.Email == "" || !info.EmailVerified {
+		return Identity{}, ErrNoVerifiedEmail
+	}
+
+	return Identity{
+		Provider: ProviderGoogle,
+		Subject:  info.Subject,
+		Email:    info.Email,
+		Login:    info.Name,
+	}, nil
+}
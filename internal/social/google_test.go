@@ -0,0 +1,85 @@
+package social
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestGoogleExchange(t *testing.T) {
+	tests := []struct {
+		name      string
+		userInfo  any
+		verifier  string
+		want      Identity
+		wantErr   error
+		wantAnErr bool
+	}{
+		{
+			name: "verified email",
+			userInfo: map[string]any{
+				"sub": "10769150350006150715113082367", "email": "jsmith@example.com",
+				"email_verified": true, "name": "Jane Smith",
+			},
+			want: Identity{
+				Provider: ProviderGoogle, Subject: "10769150350006150715113082367",
+				Email: "jsmith@example.com", Login: "Jane Smith",
+			},
+		},
+		{
+			name:     "email is not verified",
+			userInfo: map[string]any{"sub": "1", "email": "jsmith@example.com", "email_verified": false},
+			wantErr:  ErrNoVerifiedEmail,
+		},
+		{
+			name:     "no email",
+			userInfo: map[string]any{"sub": "1"},
+			wantErr:  ErrNoVerifiedEmail,
+		},
+		{
+			name:      "no subject",
+			userInfo:  map[string]any{"email": "jsmith@example.com", "email_verified": true},
+			wantAnErr: true,
+		},
+		{
+			name:      "wrong verifier",
+			userInfo:  map[string]any{"sub": "1", "email": "jsmith@example.com", "email_verified": true},
+			verifier:  "another-verifier",
+			wantAnErr: true,
+		},
+		{
+			name:      "userinfo endpoint fails",
+			userInfo:  http.StatusBadGateway,
+			wantAnErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := NewVerifier()
+			provider := newFakeProvider(t, verifier, map[string]any{"/user": tt.userInfo})
+			g := NewGoogle(provider.config(), provider.Client())
+
+			if tt.verifier != "" {
+				verifier = tt.verifier
+			}
+
+			got, err := g.Exchange(context.Background(), testCode, verifier)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Exchange() error = %v, want %v", err, tt.wantErr)
+				}
+			case tt.wantAnErr:
+				if err == nil || errors.Is(err, ErrNoVerifiedEmail) {
+					t.Fatalf("Exchange() error = %v, want a provider error", err)
+				}
+			case err != nil:
+				t.Fatalf("Exchange() error = %v", err)
+			case got != tt.want:
+				t.Errorf("Exchange() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package social
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"golang.org/x/oauth2"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/config"
+)
+
+// oauthClient holds what GitHub and Google have in common:
+// the authorization code flow and authorized JSON requests.
+type oauthClient struct {
+	config *oauth2.Config
+	http   *http.Client
+}
+
+func newOAuthClient(cfg config.SocialProviderConfig, endpoint oauth2.Endpoint, client *http.Client) oauthClient {
+	if cfg.AuthURL != "" {
+		endpoint.AuthURL = cfg.AuthURL
+	}
+	if cfg.TokenURL != "" {
+		endpoint.TokenURL = cfg.TokenURL
+	}
+
+	return oauthClient{
+		config: &oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			Endpoint:     endpoint,
+			RedirectURL:  cfg.RedirectURL,
+			Scopes:       cfg.Scopes,
+		},
+		http: client,
+	}
+}
+
+func (c oauthClient) AuthCodeURL(state, verifier string) string {
+	return c.config.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier))
+}
+
+// exchange redeems the code and returns a client authorized with the access token.
+func (c oauthClient) exchange(ctx context.Context, code, verifier string) (*http.Client, error) {
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, c.http)
+
+	token, err := c.config.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("exchange code: %w", err)
+	}
+
+	return c.config.Client(ctx, token), nil
+}
+
+// getJSON fetches url with an authorized client and decodes the response into v.
+func getJSON(ctx context.Context, client *http.Client, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("get %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("get %s: unexpected status %s", url, resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decode %s: %w", url, err)
+	}
+
+	return nil
+}
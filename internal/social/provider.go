@@ -0,0 +1,69 @@
+package social
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"golang.org/x/oauth2"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/config"
+)
+
+// Names of supported identity providers.
+const (
+	ProviderGitHub = "github"
+	ProviderGoogle = "google"
+)
+
+// ErrNoVerifiedEmail is returned when the provider account has
+// no email address confirmed by the provider.
+var ErrNoVerifiedEmail = errors.New("no verified email")
+
+// Identity is an account at an identity provider.
+type Identity struct {
+	Provider string
+	// Subject is the stable account ID at the provider.
+	Subject string
+	Email   string
+	// Login is a display name suggested for new users.
+	Login string
+}
+
+// Provider is an OAuth2 identity provider using authorization code
+// flow with PKCE.
+type Provider interface {
+	// Name returns the provider name, e.g. "github".
+	Name() string
+
+	// AuthCodeURL returns the URL the user is sent to in order to sign in.
+	// verifier is the PKCE code verifier; only its challenge is sent.
+	AuthCodeURL(state, verifier string) string
+
+	// Exchange redeems the authorization code and returns the
+	// identity of the signed in account.
+	Exchange(ctx context.Context, code, verifier string) (Identity, error)
+}
+
+// Providers is a set of enabled providers by name.
+type Providers map[string]Provider
+
+// New creates providers enabled in the configuration.
+func New(cfg config.SocialConfig) Providers {
+	client := &http.Client{Timeout: cfg.Timeout}
+
+	providers := make(Providers)
+	if cfg.GitHub.Enabled {
+		providers[ProviderGitHub] = NewGitHub(cfg.GitHub, client)
+	}
+	if cfg.Google.Enabled {
+		providers[ProviderGoogle] = NewGoogle(cfg.Google, client)
+	}
+
+	return providers
+}
+
+// NewVerifier generates a PKCE code verifier.
+func NewVerifier() string {
+	return oauth2.GenerateVerifier()
+}
@@ -0,0 +1,104 @@
+package social
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/config"
+)
+
+const (
+	testClientID     = "client-id"
+	testClientSecret = "client-secret"
+	testCode         = "auth-code"
+	testAccessToken  = "access-token"
+)
+
+// fakeProvider is an OAuth2 provider accepting testCode with the
+// expected PKCE verifier and serving JSON resources to testAccessToken.
+type fakeProvider struct {
+	*httptest.Server
+
+	verifier string
+	// resources maps a path to its response; an int value is
+	// answered with that status instead.
+	resources map[string]any
+}
+
+func newFakeProvider(t *testing.T, verifier string, resources map[string]any) *fakeProvider {
+	t.Helper()
+
+	p := &fakeProvider{verifier: verifier, resources: resources}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /token", p.token)
+	mux.HandleFunc("GET /", p.resource)
+
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Close)
+	return p
+}
+
+func (p *fakeProvider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if clientID != testClientID || clientSecret != testClientSecret ||
+		r.PostForm.Get("grant_type") != "authorization_code" ||
+		r.PostForm.Get("code") != testCode ||
+		r.PostForm.Get("code_verifier") != p.verifier {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"access_token": testAccessToken,
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+	})
+}
+
+func (p *fakeProvider) resource(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer "+testAccessToken {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	body, ok := p.resources[r.URL.Path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if status, ok := body.(int); ok {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// config points a provider at the fake.
+func (p *fakeProvider) config() config.SocialProviderConfig {
+	return config.SocialProviderConfig{
+		Enabled:      true,
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		RedirectURL:  "https://app.example.com/callback",
+		AuthURL:      p.URL + "/authorize",
+		TokenURL:     p.URL + "/token",
+		UserInfoURL:  p.URL + "/user",
+		EmailsURL:    p.URL + "/user/emails",
+	}
+}
@@ -0,0 +1,183 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	mfarepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/mfa"
+)
+
+// MFARepository is a Postgres implementation of mfa.Repository.
+type MFARepository struct {
+	log  *slog.Logger
+	pool *pgxpool.Pool
+}
+
+// NewMFARepository constructs a new Postgres-backed MFA repository.
+func NewMFARepository(log *slog.Logger, pool *pgxpool.Pool) *MFARepository {
+	return &MFARepository{
+		log:  log,
+		pool: pool,
+	}
+}
+
+// Ensure interface implementation at compile time.
+var _ mfarepo.Repository = (*MFARepository)(nil)
+
+// SaveTOTP stores a pending enrollment unless TOTP is already active.
+func (r *MFARepository) SaveTOTP(ctx context.Context, userID int64, secret []byte) error {
+	const op = "MFARepository.SaveTOTP"
+
+	query := `
+		INSERT INTO mfa_totp (user_id, secret)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret = EXCLUDED.secret,
+			last_used_step = 0,
+			created_at = now()
+		WHERE mfa_totp.confirmed_at IS NULL
+	`
+
+	tag, err := conn(ctx, r.pool).Exec(ctx, query, userID, secret)
+	if err != nil {
+		r.log.Error(op+" failed",
+			slog.Int64("user_id", userID),
+			slog.Any("err", err),
+		)
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return mfarepo.ErrAlreadyConfirmed
+	}
+
+	return nil
+}
+
+// GetTOTP returns the enrollment of the user.
+func (r *MFARepository) GetTOTP(ctx context.Context, userID int64) (mfarepo.TOTP, error) {
+	const op = "MFARepository.GetTOTP"
+
+	query := `
+		SELECT
+			user_id,
+			secret,
+			confirmed_at,
+			last_used_step
+		FROM mfa_totp
+		WHERE user_id = $1
+	`
+
+	var t mfarepo.TOTP
+
+	err := conn(ctx, r.pool).QueryRow(ctx, query, userID).Scan(
+		&t.UserID,
+		&t.Secret,
+		&t.ConfirmedAt,
+		&t.LastUsedStep,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return mfarepo.TOTP{}, mfarepo.ErrNotFound
+		}
+
+		r.log.Error(op+" failed",
+			slog.Int64("user_id", userID),
+			slog.Any("err", err),
+		)
+		return mfarepo.TOTP{}, err
+	}
+
+	return t, nil
+}
+
+// ConfirmTOTP activates the enrollment and replaces recovery codes.
+func (r *MFARepository) ConfirmTOTP(ctx context.Context, userID, step int64, codeHashes []string) error {
+	const op = "MFARepository.ConfirmTOTP"
+
+	err := pgx.BeginFunc(ctx, conn(ctx, r.pool), func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `
+			UPDATE mfa_totp
+			SET confirmed_at = now(),
+				last_used_step = $2
+			WHERE user_id = $1 AND confirmed_at IS NULL
+		`, userID, step)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return mfarepo.ErrAlreadyConfirmed
+		}
+
+		if _, err := tx.Exec(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO mfa_recovery_codes (user_id, code_hash)
+			SELECT $1, unnest($2::TEXT[])
+		`, userID, codeHashes)
+		return err
+	})
+	if errors.Is(err, mfarepo.ErrAlreadyConfirmed) {
+		return err
+	}
+	if err != nil {
+		r.log.Error(op+" failed",
+			slog.Int64("user_id", userID),
+			slog.Any("err", err),
+		)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// UseTOTPStep marks step as used unless this or a later step was used.
+func (r *MFARepository) UseTOTPStep(ctx context.Context, userID, step int64) (bool, error) {
+	const op = "MFARepository.UseTOTPStep"
+
+	query := `
+		UPDATE mfa_totp
+		SET last_used_step = $2
+		WHERE user_id = $1 AND last_used_step < $2
+	`
+
+	tag, err := conn(ctx, r.pool).Exec(ctx, query, userID, step)
+	if err != nil {
+		r.log.Error(op+" failed",
+			slog.Int64("user_id", userID),
+			slog.Any("err", err),
+		)
+		return false, err
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// UseRecoveryCode marks an unused recovery code as used.
+func (r *MFARepository) UseRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error) {
+	const op = "MFARepository.UseRecoveryCode"
+
+	query := `
+		UPDATE mfa_recovery_codes
+		SET used_at = now()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`
+
+	tag, err := conn(ctx, r.pool).Exec(ctx, query, userID, codeHash)
+	if err != nil {
+		r.log.Error(op+" failed",
+			slog.Int64("user_id", userID),
+			slog.Any("err", err),
+		)
+		return false, err
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
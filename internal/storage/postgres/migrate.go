@@ -0,0 +1,175 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/migrations"
+)
+
+// migrationLockID is the key of the advisory lock held while migrating,
+// so only one replica applies migrations at a time.
+const migrationLockID int64 = 0x61757468 // "auth"
+
+// MigrationStatus describes one migration.
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Migrator applies the embedded goose migrations.
+type Migrator struct {
+	log      *slog.Logger
+	provider *goose.Provider
+}
+
+// NewMigrator constructs a migrator over the pool. Every command
+// holds a Postgres advisory lock while it runs.
+func NewMigrator(log *slog.Logger, pool *pgxpool.Pool) (*Migrator, error) {
+	locker, err := lock.NewPostgresSessionLocker(lock.WithLockID(migrationLockID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migration lock: %w", err)
+	}
+
+	db := stdlib.OpenDBFromPool(pool)
+	provider, err := goose.NewProvider(goose.DialectPostgres, db, migrations.FS,
+		goose.WithSessionLocker(locker),
+		goose.WithGoMigrations(migrations.Go...),
+		goose.WithDisableGlobalRegistry(true),
+	)
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+
+	return &Migrator{
+		log:      log,
+		provider: provider,
+	}, nil
+}
+
+// Close releases the migrator; the pool stays open.
+func (m *Migrator) Close() error {
+	return m.provider.Close()
+}
+
+// Up applies all pending migrations.
+func (m *Migrator) Up(ctx context.Context) error {
+	results, err := m.provider.Up(ctx)
+	for _, r := range results {
+		m.logResult(r)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to apply migrations: %w", err)
+	}
+
+	if len(results) == 0 {
+		m.log.Info("database schema is up to date")
+	}
+	return nil
+}
+
+// Down rolls back the last applied migration.
+func (m *Migrator) Down(ctx context.Context) error {
+	result, err := m.provider.Down(ctx)
+	if errors.Is(err, goose.ErrNoNextVersion) {
+		m.log.Info("no migrations to roll back")
+		return nil
+	}
+	if result != nil {
+		m.logResult(result)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to roll back migration: %w", err)
+	}
+	return nil
+}
+
+// Redo rolls back the last applied migration and applies it again.
+func (m *Migrator) Redo(ctx context.Context) error {
+	current, err := m.provider.GetDBVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get schema version: %w", err)
+	}
+	if current == 0 {
+		return errors.New("no migrations to redo")
+	}
+
+	down, err := m.provider.ApplyVersion(ctx, current, false)
+	if down != nil {
+		m.logResult(down)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to roll back migration %d: %w", current, err)
+	}
+
+	up, err := m.provider.ApplyVersion(ctx, current, true)
+	if up != nil {
+		m.logResult(up)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to apply migration %d: %w", current, err)
+	}
+	return nil
+}
+
+// Status lists all migrations and whether they are applied.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	statuses, err := m.provider.Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get migration status: %w", err)
+	}
+
+	out := make([]MigrationStatus, 0, len(statuses))
+	for _, s := range statuses {
+		name := s.Source.Path
+		if s.Source.Type == goose.TypeGo {
+			name = "(go migration)"
+		}
+		out = append(out, MigrationStatus{
+			Version:   s.Source.Version,
+			Name:      name,
+			Applied:   s.State == goose.StateApplied,
+			AppliedAt: s.AppliedAt,
+		})
+	}
+	return out, nil
+}
+
+func (m *Migrator) logResult(r *goose.MigrationResult) {
+	attrs := []any{
+		slog.Int64("version", r.Source.Version),
+		slog.String("file", r.Source.Path),
+		slog.String("direction", r.Direction),
+		slog.Duration("duration", r.Duration),
+	}
+	if r.Error != nil {
+		m.log.Error("migration failed", append(attrs, slog.Any("err", r.Error))...)
+		return
+	}
+	m.log.Info("migration applied", attrs...)
+}
+
+// MustMigrate applies pending migrations and panics on any error.
+// Used in the application's startup layer.
+func MustMigrate(ctx context.Context, log *slog.Logger, pool *pgxpool.Pool) {
+	migrator, err := NewMigrator(log, pool)
+	if err != nil {
+		panic(err)
+	}
+	defer migrator.Close()
+
+	if err := migrator.Up(ctx); err != nil {
+		panic(err)
+	}
+}
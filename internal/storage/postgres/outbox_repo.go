@@ -0,0 +1,178 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	outboxrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/outbox"
+)
+
+// OutboxRepository is a Postgres implementation of outbox.Repository.
+type OutboxRepository struct {
+	log  *slog.Logger
+	pool *pgxpool.Pool
+}
+
+// NewOutboxRepository constructs a new Postgres-backed outbox repository.
+func NewOutboxRepository(log *slog.Logger, pool *pgxpool.Pool) *OutboxRepository {
+	return &OutboxRepository{
+		log:  log,
+		pool: pool,
+	}
+}
+
+// Ensure interface implementation at compile time.
+var _ outboxrepo.Repository = (*OutboxRepository)(nil)
+
+// Add stores a new event.
+func (r *OutboxRepository) Add(ctx context.Context, e outboxrepo.Event) error {
+	const op = "OutboxRepository.Add"
+
+	query := `
+		INSERT INTO outbox (user_id, event_type, schema_version, payload)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	if _, err := conn(ctx, r.pool).Exec(ctx, query, e.UserID, e.Type, e.SchemaVersion, string(e.Payload)); err != nil {
+		r.log.Error(op+" failed",
+			slog.Int64("user_id", e.UserID),
+			slog.String("type", e.Type),
+			slog.Any("err", err),
+		)
+		return err
+	}
+
+	return nil
+}
+
+// Claim locks due events which have no older unpublished event of the
+// same user. Events locked by other relays are skipped; their younger
+// events are not claimed either, because the older ones are still
+// unpublished.
+func (r *OutboxRepository) Claim(ctx context.Context, limit int) ([]outboxrepo.Event, error) {
+	const op = "OutboxRepository.Claim"
+
+	query := `
+		SELECT
+			o.id,
+			o.user_id,
+			o.event_type,
+			o.schema_version,
+			o.payload::text,
+			o.attempts,
+			o.created_at
+		FROM outbox o
+		WHERE o.published_at IS NULL
+		  AND o.next_attempt_at <= now()
+		  AND NOT EXISTS (
+			SELECT 1
+			FROM outbox older
+			WHERE older.user_id = o.user_id
+			  AND older.published_at IS NULL
+			  AND older.id < o.id
+		  )
+		ORDER BY o.id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, limit)
+	if err != nil {
+		r.log.Error(op+" failed", slog.Any("err", err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []outboxrepo.Event
+	for rows.Next() {
+		var (
+			e       outboxrepo.Event
+			payload string
+		)
+		if err := rows.Scan(&e.ID, &e.UserID, &e.Type, &e.SchemaVersion, &payload, &e.Attempts, &e.CreatedAt); err != nil {
+			r.log.Error(op+" failed", slog.Any("err", err))
+			return nil, err
+		}
+		e.Payload = []byte(payload)
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		r.log.Error(op+" failed", slog.Any("err", err))
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// MarkPublished marks the event as published.
+func (r *OutboxRepository) MarkPublished(ctx context.Context, id int64) error {
+	const op = "OutboxRepository.MarkPublished"
+
+	query := `
+		UPDATE outbox
+		SET published_at = now(),
+		    last_error = NULL
+		WHERE id = $1
+	`
+
+	if _, err := conn(ctx, r.pool).Exec(ctx, query, id); err != nil {
+		r.log.Error(op+" failed",
+			slog.Int64("id", id),
+			slog.Any("err", err),
+		)
+		return err
+	}
+
+	return nil
+}
+
+// MarkFailed records a failed publication and postpones the next attempt.
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id int64, reason string, retryAt time.Time) error {
+	const op = "OutboxRepository.MarkFailed"
+
+	query := `
+		UPDATE outbox
+		SET attempts = attempts + 1,
+		    last_error = $2,
+		    next_attempt_at = $3
+		WHERE id = $1
+	`
+
+	if _, err := conn(ctx, r.pool).Exec(ctx, query, id, reason, retryAt); err != nil {
+		r.log.Error(op+" failed",
+			slog.Int64("id", id),
+			slog.Any("err", err),
+		)
+		return err
+	}
+
+	return nil
+}
+
+// DeletePublished deletes up to limit events published before the given time.
+func (r *OutboxRepository) DeletePublished(ctx context.Context, before time.Time, limit int) (int64, error) {
+	const op = "OutboxRepository.DeletePublished"
+
+	query := `
+		DELETE FROM outbox
+		WHERE id IN (
+			SELECT id
+			FROM outbox
+			WHERE published_at IS NOT NULL
+			  AND published_at < $1
+			ORDER BY published_at
+			LIMIT $2
+		)
+	`
+
+	tag, err := conn(ctx, r.pool).Exec(ctx, query, before, limit)
+	if err != nil {
+		r.log.Error(op+" failed", slog.Any("err", err))
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
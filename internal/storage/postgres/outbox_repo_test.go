@@ -0,0 +1,175 @@
+package postgres
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	outboxrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/outbox"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/transaction"
+)
+
+func TestOutboxRepositoryClaim(t *testing.T) {
+	ctx := context.Background()
+	pool := newTestPool(t)
+	tx := NewTxManager(testLogger(), pool)
+	repo := NewOutboxRepository(testLogger(), pool)
+
+	userA := time.Now().UnixNano()
+	userB := userA + 1
+	t.Cleanup(func() {
+		_, _ = pool.Exec(context.Background(), `DELETE FROM outbox WHERE user_id IN ($1, $2)`, userA, userB)
+	})
+
+	for _, e := range []outboxrepo.Event{
+		{UserID: userA, Type: "a1", SchemaVersion: 1, Payload: []byte(`{"n":1}`)},
+		{UserID: userA, Type: "a2", SchemaVersion: 1, Payload: []byte(`{"n":2}`)},
+		{UserID: userB, Type: "b1", SchemaVersion: 1, Payload: []byte(`{"n":3}`)},
+	} {
+		if err := repo.Add(ctx, e); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+
+	// claim returns the claimed events of the test users; other tests
+	// may leave events of their own.
+	claim := func(ctx context.Context) []outboxrepo.Event {
+		t.Helper()
+
+		events, err := repo.Claim(ctx, 10000)
+		if err != nil {
+			t.Fatalf("Claim() error = %v", err)
+		}
+		var own []outboxrepo.Event
+		for _, e := range events {
+			if e.UserID == userA || e.UserID == userB {
+				own = append(own, e)
+			}
+		}
+		return own
+	}
+	types := func(events []outboxrepo.Event) []string {
+		var out []string
+		for _, e := range events {
+			out = append(out, e.Type)
+		}
+		return out
+	}
+
+	// 1. Only the oldest event of each user is claimed, and another
+	// relay skips the locked events without claiming younger ones.
+	var a1 outboxrepo.Event
+	err := tx.Do(ctx, transaction.Options{}, func(ctx context.Context) error {
+		claimed := claim(ctx)
+		if got, want := types(claimed), []string{"a1", "b1"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("Claim() = %v, want %v", got, want)
+		}
+		a1 = claimed[0]
+
+		other, err := pool.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = other.Rollback(ctx) }()
+		if got := types(claim(context.WithValue(ctx, txKey{}, other))); len(got) != 0 {
+			t.Errorf("Claim() by another relay = %v, want none", got)
+		}
+
+		if err := repo.MarkFailed(ctx, a1.ID, "broker unavailable", time.Now().Add(time.Hour)); err != nil {
+			return err
+		}
+		return repo.MarkPublished(ctx, claimed[1].ID)
+	})
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+
+	// 2. A failed event is not due yet and still holds back the younger one.
+	err = tx.Do(ctx, transaction.Options{}, func(ctx context.Context) error {
+		if got := types(claim(ctx)); len(got) != 0 {
+			t.Errorf("Claim() before retry = %v, want none", got)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+
+	// 3. Once the older event is published the next one is claimed.
+	if err := repo.MarkPublished(ctx, a1.ID); err != nil {
+		t.Fatalf("MarkPublished() error = %v", err)
+	}
+	err = tx.Do(ctx, transaction.Options{}, func(ctx context.Context) error {
+		claimed := claim(ctx)
+		if got, want := types(claimed), []string{"a2"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("Claim() after publishing = %v, want %v", got, want)
+		}
+		if e := claimed[0]; e.UserID != userA || e.SchemaVersion != 1 || string(e.Payload) != `{"n": 2}` || e.Attempts != 0 {
+			t.Errorf("Claim() event = %+v", e)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+
+	var attempts int
+	if err := pool.QueryRow(ctx, `SELECT attempts FROM outbox WHERE id = $1`, a1.ID).Scan(&attempts); err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 1 {
+		t.Errorf("attempts of a failed event = %d, want 1", attempts)
+	}
+}
+
+func TestOutboxRepositoryDeletePublished(t *testing.T) {
+	ctx := context.Background()
+	pool := newTestPool(t)
+	repo := NewOutboxRepository(testLogger(), pool)
+
+	userID := time.Now().UnixNano()
+	t.Cleanup(func() {
+		_, _ = pool.Exec(context.Background(), `DELETE FROM outbox WHERE user_id = $1`, userID)
+	})
+
+	for range 3 {
+		if err := repo.Add(ctx, outboxrepo.Event{UserID: userID, Type: "t", SchemaVersion: 1, Payload: []byte(`{}`)}); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+	if _, err := pool.Exec(ctx, `
+		UPDATE outbox SET published_at = now() - interval '1 hour'
+		WHERE id IN (SELECT id FROM outbox WHERE user_id = $1 ORDER BY id LIMIT 2)
+	`, userID); err != nil {
+		t.Fatal(err)
+	}
+
+	// Other published rows of a shared database may be deleted too, so
+	// only the rows of this test are checked.
+	before := time.Now().Add(-time.Minute)
+	for {
+		n, err := repo.DeletePublished(ctx, before, 1)
+		if err != nil {
+			t.Fatalf("DeletePublished() error = %v", err)
+		}
+		if n > 1 {
+			t.Fatalf("DeletePublished() deleted %d rows, want at most the limit", n)
+		}
+		if n == 0 {
+			break
+		}
+	}
+
+	var left int
+	if err := pool.QueryRow(ctx, `SELECT count(*) FROM outbox WHERE user_id = $1 AND published_at IS NULL`, userID).Scan(&left); err != nil {
+		t.Fatal(err)
+	}
+	var total int
+	if err := pool.QueryRow(ctx, `SELECT count(*) FROM outbox WHERE user_id = $1`, userID).Scan(&total); err != nil {
+		t.Fatal(err)
+	}
+	if left != 1 || total != 1 {
+		t.Errorf("rows left = %d (unpublished %d), want only the unpublished one", total, left)
+	}
+}
@@ -0,0 +1,204 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	passkeyrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/passkey"
+)
+
+// PasskeyRepository is a Postgres implementation of passkey.Repository.
+type PasskeyRepository struct {
+	log  *slog.Logger
+	pool *pgxpool.Pool
+}
+
+// NewPasskeyRepository constructs a new Postgres-backed passkey repository.
+func NewPasskeyRepository(log *slog.Logger, pool *pgxpool.Pool) *PasskeyRepository {
+	return &PasskeyRepository{
+		log:  log,
+		pool: pool,
+	}
+}
+
+// Ensure interface implementation at compile time.
+var _ passkeyrepo.Repository = (*PasskeyRepository)(nil)
+
+const passkeyColumns = `
+	id,
+	user_id,
+	credential_id,
+	public_key,
+	attestation_type,
+	aaguid,
+	sign_count,
+	transports,
+	backup_eligible,
+	backup_state,
+	name,
+	created_at,
+	last_used_at
+`
+
+// Create stores a new credential.
+func (r *PasskeyRepository) Create(ctx context.Context, c passkeyrepo.Credential) (passkeyrepo.Credential, error) {
+	const op = "PasskeyRepository.Create"
+
+	query := `
+		INSERT INTO webauthn_credentials (
+			user_id,
+			credential_id,
+			public_key,
+			attestation_type,
+			aaguid,
+			sign_count,
+			transports,
+			backup_eligible,
+			backup_state,
+			name
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING ` + passkeyColumns
+
+	created, err := scanPasskey(conn(ctx, r.pool).QueryRow(ctx, query,
+		c.UserID,
+		c.CredentialID,
+		c.PublicKey,
+		c.AttestationType,
+		c.AAGUID,
+		int64(c.SignCount),
+		c.Transports,
+		c.BackupEligible,
+		c.BackupState,
+		c.Name,
+	))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return passkeyrepo.Credential{}, passkeyrepo.ErrAlreadyExists
+		}
+
+		r.log.Error(op+" failed",
+			slog.Int64("user_id", c.UserID),
+			slog.Any("err", err),
+		)
+		return passkeyrepo.Credential{}, err
+	}
+
+	return created, nil
+}
+
+// ListByUser returns all credentials of the user.
+func (r *PasskeyRepository) ListByUser(ctx context.Context, userID int64) ([]passkeyrepo.Credential, error) {
+	const op = "PasskeyRepository.ListByUser"
+
+	query := `SELECT ` + passkeyColumns + ` FROM webauthn_credentials WHERE user_id = $1 ORDER BY id`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, userID)
+	if err != nil {
+		r.log.Error(op+" failed",
+			slog.Int64("user_id", userID),
+			slog.Any("err", err),
+		)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var credentials []passkeyrepo.Credential
+	for rows.Next() {
+		c, err := scanPasskey(rows)
+		if err != nil {
+			r.log.Error(op+" failed",
+				slog.Int64("user_id", userID),
+				slog.Any("err", err),
+			)
+			return nil, err
+		}
+		credentials = append(credentials, c)
+	}
+	if err := rows.Err(); err != nil {
+		r.log.Error(op+" failed",
+			slog.Int64("user_id", userID),
+			slog.Any("err", err),
+		)
+		return nil, err
+	}
+
+	return credentials, nil
+}
+
+// GetByCredentialID looks up a credential by its WebAuthn credential ID.
+func (r *PasskeyRepository) GetByCredentialID(ctx context.Context, credentialID []byte) (passkeyrepo.Credential, error) {
+	const op = "PasskeyRepository.GetByCredentialID"
+
+	query := `SELECT ` + passkeyColumns + ` FROM webauthn_credentials WHERE credential_id = $1`
+
+	c, err := scanPasskey(conn(ctx, r.pool).QueryRow(ctx, query, credentialID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return passkeyrepo.Credential{}, passkeyrepo.ErrNotFound
+		}
+
+		r.log.Error(op+" failed", slog.Any("err", err))
+		return passkeyrepo.Credential{}, err
+	}
+
+	return c, nil
+}
+
+// MarkUsed stores the sign counter and backup state of a successful assertion.
+func (r *PasskeyRepository) MarkUsed(ctx context.Context, credentialID []byte, signCount uint32, backupState bool) error {
+	const op = "PasskeyRepository.MarkUsed"
+
+	query := `
+		UPDATE webauthn_credentials
+		SET sign_count = $2,
+			backup_state = $3,
+			last_used_at = now()
+		WHERE credential_id = $1
+	`
+
+	tag, err := conn(ctx, r.pool).Exec(ctx, query, credentialID, int64(signCount), backupState)
+	if err != nil {
+		r.log.Error(op+" failed", slog.Any("err", err))
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return passkeyrepo.ErrNotFound
+	}
+
+	return nil
+}
+
+func scanPasskey(row pgx.Row) (passkeyrepo.Credential, error) {
+	var (
+		c         passkeyrepo.Credential
+		signCount int64
+	)
+
+	err := row.Scan(
+		&c.ID,
+		&c.UserID,
+		&c.CredentialID,
+		&c.PublicKey,
+		&c.AttestationType,
+		&c.AAGUID,
+		&signCount,
+		&c.Transports,
+		&c.BackupEligible,
+		&c.BackupState,
+		&c.Name,
+		&c.CreatedAt,
+		&c.LastUsedAt,
+	)
+	if err != nil {
+		return passkeyrepo.Credential{}, err
+	}
+
+	c.SignCount = uint32(signCount)
+	return c, nil
+}
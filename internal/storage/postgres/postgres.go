@@ -1,13 +1,14 @@
 package postgres
 
 import (
-	"authorization-service/internal/config"
 	"context"
 	"fmt"
 	"log/slog"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/config"
 )
 
 // New creates a new Postgres connection pool based on application config.
@@ -0,0 +1,217 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/lib/secretbox"
+	signingkeyrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/signingkey"
+)
+
+// signingKeysLockID is an advisory lock key serializing key rotation
+// between replicas.
+const signingKeysLockID = 7_310_001
+
+// SigningKeyRepository is a Postgres implementation of signingkey.Repository.
+// Private keys are encrypted with box; the key ID is bound to the
+// ciphertext, so a sealed key can not be moved to another row.
+type SigningKeyRepository struct {
+	log  *slog.Logger
+	pool *pgxpool.Pool
+	box  *secretbox.Box
+}
+
+// NewSigningKeyRepository constructs a new Postgres-backed signing key repository.
+func NewSigningKeyRepository(log *slog.Logger, pool *pgxpool.Pool, box *secretbox.Box) *SigningKeyRepository {
+	return &SigningKeyRepository{
+		log:  log,
+		pool: pool,
+		box:  box,
+	}
+}
+
+// Ensure interface implementation at compile time.
+var _ signingkeyrepo.Repository = (*SigningKeyRepository)(nil)
+
+// ListPublished returns keys which are not retired or were retired after retiredAfter.
+// Keys stored in plaintext before encryption was introduced are encrypted
+// in place.
+func (r *SigningKeyRepository) ListPublished(ctx context.Context, retiredAfter time.Time) ([]signingkeyrepo.Key, error) {
+	const op = "SigningKeyRepository.ListPublished"
+
+	query := `
+		SELECT
+			kid,
+			algorithm,
+			private_key,
+			private_key_sealed,
+			created_at,
+			activates_at,
+			retired_at
+		FROM signing_keys
+		WHERE retired_at IS NULL OR retired_at > $1
+		ORDER BY activates_at
+	`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, retiredAfter)
+	if err != nil {
+		r.log.Error(op+" failed", slog.Any("err", err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var (
+		keys   []signingkeyrepo.Key
+		legacy []signingkeyrepo.Key
+	)
+	for rows.Next() {
+		var (
+			k      signingkeyrepo.Key
+			pem    *string
+			sealed []byte
+		)
+		if err := rows.Scan(&k.ID, &k.Algorithm, &pem, &sealed, &k.CreatedAt, &k.ActivatesAt, &k.RetiredAt); err != nil {
+			r.log.Error(op+" failed", slog.Any("err", err))
+			return nil, err
+		}
+
+		if sealed == nil {
+			if pem == nil {
+				return nil, fmt.Errorf("%s: key %s has no private key", op, k.ID)
+			}
+			k.PrivateKeyPEM = []byte(*pem)
+			legacy = append(legacy, k)
+		} else {
+			k.PrivateKeyPEM, err = r.box.Open(sealed, []byte(k.ID))
+			if err != nil {
+				r.log.Error(op+" failed",
+					slog.String("kid", k.ID),
+					slog.Any("err", err),
+				)
+				return nil, fmt.Errorf("%s: key %s: %w", op, k.ID, err)
+			}
+		}
+		keys = append(keys, k)
+	}
+	if err := rows.Err(); err != nil {
+		r.log.Error(op+" failed", slog.Any("err", err))
+		return nil, err
+	}
+	rows.Close()
+
+	for _, k := range legacy {
+		if err := r.seal(ctx, k); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return keys, nil
+}
+
+// seal encrypts a key stored in plaintext and drops the plaintext.
+func (r *SigningKeyRepository) seal(ctx context.Context, k signingkeyrepo.Key) error {
+	const op = "SigningKeyRepository.seal"
+
+	sealed, err := r.box.Seal(k.PrivateKeyPEM, []byte(k.ID))
+	if err != nil {
+		return err
+	}
+
+	_, err = conn(ctx, r.pool).Exec(ctx, `
+		UPDATE signing_keys
+		SET private_key_sealed = $2,
+		    private_key = NULL
+		WHERE kid = $1 AND private_key_sealed IS NULL
+	`, k.ID, sealed)
+	if err != nil {
+		r.log.Error(op+" failed",
+			slog.String("kid", k.ID),
+			slog.Any("err", err),
+		)
+		return err
+	}
+
+	r.log.Info("signing key encrypted at rest", slog.String("kid", k.ID))
+	return nil
+}
+
+// Rotate stores key and retires current keys unless another key
+// has been created after createdAfter.
+func (r *SigningKeyRepository) Rotate(ctx context.Context, key signingkeyrepo.Key, createdAfter time.Time) (bool, error) {
+	const op = "SigningKeyRepository.Rotate"
+
+	sealed, err := r.box.Seal(key.PrivateKeyPEM, []byte(key.ID))
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rotated := false
+
+	err = pgx.BeginFunc(ctx, conn(ctx, r.pool), func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, signingKeysLockID); err != nil {
+			return err
+		}
+
+		var fresh bool
+		err := tx.QueryRow(ctx,
+			`SELECT EXISTS (SELECT 1 FROM signing_keys WHERE created_at > $1)`,
+			createdAfter,
+		).Scan(&fresh)
+		if err != nil {
+			return err
+		}
+		if fresh {
+			// Another replica has rotated already.
+			return nil
+		}
+
+		_, err = tx.Exec(ctx,
+			`UPDATE signing_keys SET retired_at = $1 WHERE retired_at IS NULL`,
+			key.ActivatesAt,
+		)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO signing_keys (kid, algorithm, private_key_sealed, activates_at)
+			VALUES ($1, $2, $3, $4)
+		`, key.ID, key.Algorithm, sealed, key.ActivatesAt)
+		if err != nil {
+			return err
+		}
+
+		rotated = true
+		return nil
+	})
+	if err != nil {
+		r.log.Error(op+" failed",
+			slog.String("kid", key.ID),
+			slog.Any("err", err),
+		)
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return rotated, nil
+}
+
+// DeleteRetired removes keys retired before retiredBefore.
+func (r *SigningKeyRepository) DeleteRetired(ctx context.Context, retiredBefore time.Time) error {
+	const op = "SigningKeyRepository.DeleteRetired"
+
+	_, err := conn(ctx, r.pool).Exec(ctx,
+		`DELETE FROM signing_keys WHERE retired_at IS NOT NULL AND retired_at < $1`,
+		retiredBefore,
+	)
+	if err != nil {
+		r.log.Error(op+" failed", slog.Any("err", err))
+		return err
+	}
+
+	return nil
+}
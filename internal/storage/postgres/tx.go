@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/transaction"
+)
+
+// Retries of transactions which failed to serialize.
+const (
+	maxTxAttempts = 3
+	txRetryDelay  = 20 * time.Millisecond
+)
+
+// txKey is the context key of the running transaction.
+type txKey struct{}
+
+// dbtx is what repositories query: the pool, or the transaction
+// found in the context. pgx.BeginFunc on a transaction starts
+// a savepoint, so repositories can nest their own transactions.
+type dbtx interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// conn returns the transaction of ctx, or pool outside of transactions.
+func conn(ctx context.Context, pool *pgxpool.Pool) dbtx {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	return pool
+}
+
+// TxManager is a Postgres implementation of transaction.Manager.
+type TxManager struct {
+	log  *slog.Logger
+	pool *pgxpool.Pool
+}
+
+// NewTxManager constructs a new Postgres transaction manager.
+func NewTxManager(log *slog.Logger, pool *pgxpool.Pool) *TxManager {
+	return &TxManager{
+		log:  log,
+		pool: pool,
+	}
+}
+
+// Ensure interface implementation at compile time.
+var _ transaction.Manager = (*TxManager)(nil)
+
+// Do runs fn in a transaction and retries it on serialization
+// failures and deadlocks.
+func (m *TxManager) Do(ctx context.Context, opts transaction.Options, fn func(ctx context.Context) error) error {
+	const op = "TxManager.Do"
+
+	// Nested units of work join the outer transaction.
+	if _, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return fn(ctx)
+	}
+
+	txOpts := pgx.TxOptions{IsoLevel: pgx.TxIsoLevel(opts.Isolation)}
+	if opts.ReadOnly {
+		txOpts.AccessMode = pgx.ReadOnly
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := pgx.BeginTxFunc(ctx, m.pool, txOpts, func(tx pgx.Tx) error {
+			return fn(context.WithValue(ctx, txKey{}, tx))
+		})
+		if err == nil || !isRetryable(err) {
+			return err
+		}
+		if attempt == maxTxAttempts {
+			m.log.Error(op+" failed",
+				slog.Int("attempts", attempt),
+				slog.Any("err", err),
+			)
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		// Back off with jitter, so retried transactions do not collide again.
+		delay := txRetryDelay*time.Duration(attempt) + rand.N(txRetryDelay)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// isRetryable tells whether a transaction failed because of concurrent
+// transactions and succeeds when run again: serialization_failure or
+// deadlock_detected.
+func isRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && (pgErr.Code == "40001" || pgErr.Code == "40P01")
+}
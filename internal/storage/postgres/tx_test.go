@@ -0,0 +1,166 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/domain"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/transaction"
+	userrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/user"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "serialization failure", err: &pgconn.PgError{Code: "40001"}, want: true},
+		{name: "deadlock", err: &pgconn.PgError{Code: "40P01"}, want: true},
+		{name: "wrapped", err: fmt.Errorf("query: %w", &pgconn.PgError{Code: "40001"}), want: true},
+		{name: "unique violation", err: &pgconn.PgError{Code: "23505"}},
+		{name: "not a postgres error", err: errors.New("boom")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryable(tt.err); got != tt.want {
+				t.Errorf("isRetryable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTxManagerDo(t *testing.T) {
+	pool := newTestPool(t)
+	tx := NewTxManager(testLogger(), pool)
+	users := NewUserRepository(testLogger(), pool)
+
+	errRollback := errors.New("rollback")
+
+	tests := []struct {
+		name string
+		// fn creates users with the given emails and returns the error Do is
+		// expected to return.
+		fn        func(ctx context.Context, outer, inner string) error
+		wantSaved bool
+	}{
+		{
+			name: "commit",
+			fn: func(ctx context.Context, outer, inner string) error {
+				return tx.Do(ctx, transaction.Options{}, func(ctx context.Context) error {
+					if _, err := users.Create(ctx, domain.User{Email: outer}); err != nil {
+						return err
+					}
+					return tx.Do(ctx, transaction.Options{}, func(ctx context.Context) error {
+						_, err := users.Create(ctx, domain.User{Email: inner})
+						return err
+					})
+				})
+			},
+			wantSaved: true,
+		},
+		{
+			name: "outer error rolls back the nested unit",
+			fn: func(ctx context.Context, outer, inner string) error {
+				err := tx.Do(ctx, transaction.Options{}, func(ctx context.Context) error {
+					if _, err := users.Create(ctx, domain.User{Email: outer}); err != nil {
+						return err
+					}
+					if err := tx.Do(ctx, transaction.Options{}, func(ctx context.Context) error {
+						_, err := users.Create(ctx, domain.User{Email: inner})
+						return err
+					}); err != nil {
+						return err
+					}
+					return errRollback
+				})
+				if !errors.Is(err, errRollback) {
+					return fmt.Errorf("Do() error = %v, want errRollback", err)
+				}
+				return nil
+			},
+		},
+		{
+			name: "nested error rolls back the outer unit",
+			fn: func(ctx context.Context, outer, inner string) error {
+				err := tx.Do(ctx, transaction.Options{}, func(ctx context.Context) error {
+					if _, err := users.Create(ctx, domain.User{Email: outer}); err != nil {
+						return err
+					}
+					return tx.Do(ctx, transaction.Options{}, func(ctx context.Context) error {
+						if _, err := users.Create(ctx, domain.User{Email: inner}); err != nil {
+							return err
+						}
+						return errRollback
+					})
+				})
+				if !errors.Is(err, errRollback) {
+					return fmt.Errorf("Do() error = %v, want errRollback", err)
+				}
+				return nil
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			outer, inner := testEmail("outer"), testEmail("inner")
+
+			if err := tt.fn(ctx, outer, inner); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, email := range []string{outer, inner} {
+				_, err := users.GetByEmail(ctx, email)
+				if saved := err == nil; saved != tt.wantSaved {
+					t.Errorf("user %s saved = %v (err %v), want %v", email, saved, err, tt.wantSaved)
+				}
+				if err != nil && !errors.Is(err, userrepo.ErrNotFound) {
+					t.Errorf("GetByEmail() error = %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestTxManagerDoRetries(t *testing.T) {
+	tx := NewTxManager(testLogger(), newTestPool(t))
+
+	tests := []struct {
+		name         string
+		failures     int
+		err          error
+		wantAttempts int
+		wantErr      bool
+	}{
+		{name: "no failure", wantAttempts: 1},
+		{name: "serialization failure is retried", failures: 2, err: &pgconn.PgError{Code: "40001"}, wantAttempts: 3},
+		{name: "retries are bounded", failures: maxTxAttempts, err: &pgconn.PgError{Code: "40P01"}, wantAttempts: maxTxAttempts, wantErr: true},
+		{name: "other errors are not retried", failures: 1, err: &pgconn.PgError{Code: "23505"}, wantAttempts: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			err := tx.Do(context.Background(), transaction.Options{Isolation: transaction.Serializable}, func(ctx context.Context) error {
+				attempts++
+				if attempts <= tt.failures {
+					return tt.err
+				}
+				return nil
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Do() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("fn ran %d times, want %d", attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
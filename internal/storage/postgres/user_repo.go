@@ -1,15 +1,19 @@
 package postgres
 
 import (
-	"authorization-service/internal/domain"
 	"context"
-	"database/sql"
 	"errors"
 	"log/slog"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 
-	userrepo "authorization-service/internal/repository/user"
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/domain"
+	userrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/user"
 )
 
 // UserRepository is a Postgres implementation of user.Repository.
@@ -29,9 +33,24 @@ func NewUserRepository(log *slog.Logger, pool *pgxpool.Pool) *UserRepository {
 // Ensure interface implementation at compile time.
 var _ userrepo.Repository = (*UserRepository)(nil)
 
+// userColumns are read by scanUser.
+const userColumns = `
+	id,
+	email,
+	COALESCE(login, ''),
+	COALESCE(password_hash, ''),
+	email_verified,
+	scopes,
+	github_id,
+	google_id,
+	created_at,
+	updated_at
+`
+
 // Create inserts a new user into the database and returns full entity with ID and timestamps.
 func (r *UserRepository) Create(ctx context.Context, u domain.User) (domain.User, error) {
 	const op = "UserRepository.Create"
+
 	query := `
 		INSERT INTO users (
 			email,
@@ -41,43 +60,22 @@ func (r *UserRepository) Create(ctx context.Context, u domain.User) (domain.User
 			github_id,
 			google_id
 		)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING 
-			id,
-			email,
-			login,
-			password_hash,
-			email_verified,
-			github_id,
-			google_id,
-			created_at,
-			updated_at
-	`
-	var (
-		dbGithub sql.NullString
-		dbGoogle sql.NullString
-		res      domain.User
-	)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6)
+		RETURNING ` + userColumns
 
-	err := r.pool.QueryRow(ctx, query,
+	created, err := scanUser(conn(ctx, r.pool).QueryRow(ctx, query,
 		u.Email,
 		u.Login,
 		u.PasswordHash,
 		u.EmailVerified,
 		u.GithubID,
 		u.GoogleID,
-	).Scan(
-		&res.ID,
-		&res.Email,
-		&res.Login,
-		&res.PasswordHash,
-		&res.EmailVerified,
-		&dbGithub,
-		&dbGoogle,
-		&res.CreatedAt,
-		&res.UpdatedAt,
-	)
+	))
 	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.User{}, userrepo.ErrAlreadyExists
+		}
+
 		r.log.Error(op+" failed",
 			slog.String("email", u.Email),
 			slog.Any("err", err),
@@ -85,75 +83,429 @@ func (r *UserRepository) Create(ctx context.Context, u domain.User) (domain.User
 		return domain.User{}, err
 	}
 
-	if dbGithub.Valid {
-		g := dbGithub.String
-		res.GithubID = &g
-	}
-	if dbGoogle.Valid {
-		g := dbGoogle.String
-		res.GoogleID = &g
+	return created, nil
+}
+
+// GetByID looks up a user by ID.
+func (r *UserRepository) GetByID(ctx context.Context, id int64) (domain.User, error) {
+	const op = "UserRepository.GetByID"
+
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1 AND deleted_at IS NULL`
+
+	u, err := scanUser(conn(ctx, r.pool).QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.User{}, userrepo.ErrNotFound
+		}
+
+		r.log.Error(op+" failed",
+			slog.Int64("user_id", id),
+			slog.Any("err", err),
+		)
+		return domain.User{}, err
 	}
 
-	return res, nil
+	return u, nil
 }
 
 // GetByEmail looks up a user by email.
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (domain.User, error) {
 	const op = "UserRepository.GetByEmail"
 
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = $1 AND deleted_at IS NULL`
+
+	u, err := scanUser(conn(ctx, r.pool).QueryRow(ctx, query, email))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.User{}, userrepo.ErrNotFound
+		}
+
+		r.log.Error(op+" failed",
+			slog.String("email", email),
+			slog.Any("err", err),
+		)
+		return domain.User{}, err
+	}
+
+	return u, nil
+}
+
+// GetByLogin looks up the earliest registered user with the login.
+func (r *UserRepository) GetByLogin(ctx context.Context, login string) (domain.User, error) {
+	const op = "UserRepository.GetByLogin"
+
 	query := `
-		SELECT
-			id,
-			email,
-			login,
-			password_hash,
-			email_verified,
-			github_id,
-			google_id,
-			created_at,
-			updated_at
+		SELECT ` + userColumns + `
 		FROM users
-		WHERE email = $1
+		WHERE login = $1 AND deleted_at IS NULL
+		ORDER BY id
 		LIMIT 1
 	`
 
-	var (
-		u        domain.User
-		dbGithub sql.NullString
-		dbGoogle sql.NullString
-	)
+	u, err := scanUser(conn(ctx, r.pool).QueryRow(ctx, query, login))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.User{}, userrepo.ErrNotFound
+		}
 
-	err := r.pool.QueryRow(ctx, query, email).Scan(
-		&u.ID,
-		&u.Email,
-		&u.Login,
-		&u.PasswordHash,
-		&u.EmailVerified,
-		&dbGithub,
-		&dbGoogle,
-		&u.CreatedAt,
-		&u.UpdatedAt,
-	)
+		r.log.Error(op+" failed",
+			slog.String("login", login),
+			slog.Any("err", err),
+		)
+		return domain.User{}, err
+	}
+
+	return u, nil
+}
+
+// GetByProviderID looks up a user by account ID at an identity provider.
+func (r *UserRepository) GetByProviderID(ctx context.Context, provider, providerID string) (domain.User, error) {
+	const op = "UserRepository.GetByProviderID"
+
+	column, err := providerColumn(provider)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
+		return domain.User{}, err
+	}
+
+	query := `SELECT ` + userColumns + ` FROM users WHERE ` + column + ` = $1 AND deleted_at IS NULL`
+
+	u, err := scanUser(conn(ctx, r.pool).QueryRow(ctx, query, providerID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
 			return domain.User{}, userrepo.ErrNotFound
 		}
 
 		r.log.Error(op+" failed",
-			slog.String("email", email),
+			slog.String("provider", provider),
 			slog.Any("err", err),
 		)
 		return domain.User{}, err
 	}
 
-	if dbGithub.Valid {
-		g := dbGithub.String
-		u.GithubID = &g
+	return u, nil
+}
+
+// SetProviderID links the user to an account at an identity provider.
+func (r *UserRepository) SetProviderID(ctx context.Context, id int64, provider, providerID string) error {
+	const op = "UserRepository.SetProviderID"
+
+	column, err := providerColumn(provider)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE users
+		SET ` + column + ` = $2,
+			updated_at = now()
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	tag, err := conn(ctx, r.pool).Exec(ctx, query, id, providerID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return userrepo.ErrIdentityTaken
+		}
+
+		r.log.Error(op+" failed",
+			slog.Int64("user_id", id),
+			slog.String("provider", provider),
+			slog.Any("err", err),
+		)
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return userrepo.ErrNotFound
 	}
-	if dbGoogle.Valid {
-		g := dbGoogle.String
-		u.GoogleID = &g
+
+	return nil
+}
+
+// UnsetProviderID unlinks the user from the identity provider.
+func (r *UserRepository) UnsetProviderID(ctx context.Context, id int64, provider string) error {
+	const op = "UserRepository.UnsetProviderID"
+
+	column, err := providerColumn(provider)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE users
+		SET ` + column + ` = NULL,
+			updated_at = now()
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	tag, err := conn(ctx, r.pool).Exec(ctx, query, id)
+	if err != nil {
+		r.log.Error(op+" failed",
+			slog.Int64("user_id", id),
+			slog.String("provider", provider),
+			slog.Any("err", err),
+		)
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return userrepo.ErrNotFound
+	}
+
+	return nil
+}
+
+// MarkEmailVerified sets email_verified flag of the user.
+func (r *UserRepository) MarkEmailVerified(ctx context.Context, id int64) error {
+	const op = "UserRepository.MarkEmailVerified"
+
+	query := `
+		UPDATE users
+		SET email_verified = TRUE,
+			updated_at = now()
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	tag, err := conn(ctx, r.pool).Exec(ctx, query, id)
+	if err != nil {
+		r.log.Error(op+" failed",
+			slog.Int64("user_id", id),
+			slog.Any("err", err),
+		)
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return userrepo.ErrNotFound
+	}
+
+	return nil
+}
+
+// UpdatePassword replaces password hash of the user.
+func (r *UserRepository) UpdatePassword(ctx context.Context, id int64, passwordHash string) error {
+	const op = "UserRepository.UpdatePassword"
+
+	query := `
+		UPDATE users
+		SET password_hash = $2,
+			updated_at = now()
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	tag, err := conn(ctx, r.pool).Exec(ctx, query, id, passwordHash)
+	if err != nil {
+		r.log.Error(op+" failed",
+			slog.Int64("user_id", id),
+			slog.Any("err", err),
+		)
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return userrepo.ErrNotFound
+	}
+
+	return nil
+}
+
+// Update changes fields of the user if its updated_at still equals
+// updatedAt, so concurrent updates can not overwrite each other.
+func (r *UserRepository) Update(ctx context.Context, id int64, updatedAt time.Time, update userrepo.Update) (domain.User, error) {
+	const op = "UserRepository.Update"
+
+	args := []any{id, updatedAt}
+	set := []string{"updated_at = now()"}
+	column := func(name string, value any) {
+		args = append(args, value)
+		set = append(set, name+" = $"+strconv.Itoa(len(args)))
+	}
+
+	if update.Email != nil {
+		column("email", *update.Email)
+	}
+	if update.Login != nil {
+		column("login", *update.Login)
+	}
+	if update.EmailVerified != nil {
+		column("email_verified", *update.EmailVerified)
+	}
+	if update.Scopes != nil {
+		column("scopes", *update.Scopes)
+	}
+
+	query := `
+		UPDATE users
+		SET ` + strings.Join(set, ", ") + `
+		WHERE id = $1 AND updated_at = $2 AND deleted_at IS NULL
+		RETURNING ` + userColumns
+
+	u, err := scanUser(conn(ctx, r.pool).QueryRow(ctx, query, args...))
+	if err == nil {
+		return u, nil
+	}
+
+	switch {
+	case isUniqueViolation(err):
+		return domain.User{}, userrepo.ErrAlreadyExists
+	case errors.Is(err, pgx.ErrNoRows):
+		// Either the user is gone or it has been updated since it was read.
+		if _, err := r.GetByID(ctx, id); err != nil {
+			return domain.User{}, err
+		}
+		return domain.User{}, userrepo.ErrConflict
+	default:
+		r.log.Error(op+" failed",
+			slog.Int64("user_id", id),
+			slog.Any("err", err),
+		)
+		return domain.User{}, err
+	}
+}
+
+// Delete marks the user as deleted.
+func (r *UserRepository) Delete(ctx context.Context, id int64) error {
+	const op = "UserRepository.Delete"
+
+	query := `
+		UPDATE users
+		SET deleted_at = now(),
+			updated_at = now()
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	tag, err := conn(ctx, r.pool).Exec(ctx, query, id)
+	if err != nil {
+		r.log.Error(op+" failed",
+			slog.Int64("user_id", id),
+			slog.Any("err", err),
+		)
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return userrepo.ErrNotFound
+	}
+
+	return nil
+}
+
+// List returns a page of users ordered by ID using keyset pagination:
+// the next page starts after the last ID of the previous one.
+func (r *UserRepository) List(ctx context.Context, filter userrepo.ListFilter, page userrepo.Page) ([]domain.User, int64, error) {
+	const op = "UserRepository.List"
+
+	limit := page.Limit
+	if limit <= 0 {
+		limit = userrepo.DefaultPageLimit
+	}
+	limit = min(limit, userrepo.MaxPageLimit)
+
+	var args []any
+	where := []string{"deleted_at IS NULL"}
+	cond := func(format string, value any) {
+		args = append(args, value)
+		where = append(where, strings.ReplaceAll(format, "?", "$"+strconv.Itoa(len(args))))
+	}
+
+	if page.AfterID > 0 {
+		cond("id > ?", page.AfterID)
+	}
+	if filter.EmailVerified != nil {
+		cond("email_verified = ?", *filter.EmailVerified)
+	}
+	if !filter.CreatedFrom.IsZero() {
+		cond("created_at >= ?", filter.CreatedFrom)
+	}
+	if !filter.CreatedTo.IsZero() {
+		cond("created_at < ?", filter.CreatedTo)
+	}
+	if filter.Provider != "" {
+		column, err := providerColumn(filter.Provider)
+		if err != nil {
+			return nil, 0, err
+		}
+		where = append(where, column+" IS NOT NULL")
+	}
+
+	// One extra row tells whether there is a next page.
+	args = append(args, limit+1)
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE ` + strings.Join(where, " AND ") + `
+		ORDER BY id
+		LIMIT $` + strconv.Itoa(len(args))
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		r.log.Error(op+" failed", slog.Any("err", err))
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	users := make([]domain.User, 0, limit)
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			r.log.Error(op+" failed", slog.Any("err", err))
+			return nil, 0, err
+		}
+		users = append(users, u)
+	}
+	if err := rows.Err(); err != nil {
+		r.log.Error(op+" failed", slog.Any("err", err))
+		return nil, 0, err
+	}
+
+	var next int64
+	if len(users) > limit {
+		users = users[:limit]
+		next = users[limit-1].ID
+	}
+
+	return users, next, nil
+}
+
+// scanUser maps a row of userColumns to a user.
+func scanUser(row pgx.Row) (domain.User, error) {
+	var u domain.User
+
+	// Provider IDs are NULL for users not linked to the provider;
+	// pgx scans NULL into a nil pointer.
+	err := row.Scan(
+		&u.ID,
+		&u.Email,
+		&u.Login,
+		&u.PasswordHash,
+		&u.EmailVerified,
+		&u.Scopes,
+		&u.GithubID,
+		&u.GoogleID,
+		&u.CreatedAt,
+		&u.UpdatedAt,
+	)
+	if err != nil {
+		return domain.User{}, err
 	}
 
 	return u, nil
 }
+
+// isUniqueViolation tells whether err is a Postgres unique_violation.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// providerColumn returns the column holding account IDs of the provider.
+func providerColumn(provider string) (string, error) {
+	switch provider {
+	case userrepo.ProviderGitHub:
+		return "github_id", nil
+	case userrepo.ProviderGoogle:
+		return "google_id", nil
+	default:
+		return "", userrepo.ErrUnknownProvider
+	}
+}
@@ -0,0 +1,121 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/domain"
+	userrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/user"
+)
+
+func createTestUser(t *testing.T, repo *UserRepository, name string) domain.User {
+	t.Helper()
+
+	u, err := repo.Create(context.Background(), domain.User{
+		Email: testEmail(name),
+		Login: name,
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	return u
+}
+
+func TestUserRepositoryUpdate(t *testing.T) {
+	ctx := context.Background()
+	repo := NewUserRepository(testLogger(), newTestPool(t))
+
+	user := createTestUser(t, repo, "update")
+	other := createTestUser(t, repo, "other")
+	deleted := createTestUser(t, repo, "deleted")
+	if err := repo.Delete(ctx, deleted.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	newLogin := "renamed"
+	verified := true
+	takenEmail := other.Email
+
+	updated, err := repo.Update(ctx, user.ID, user.UpdatedAt, userrepo.Update{
+		Login:         &newLogin,
+		EmailVerified: &verified,
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if updated.Login != newLogin || !updated.EmailVerified || updated.Email != user.Email {
+		t.Errorf("Update() = %+v, want login %q, verified and the email kept", updated, newLogin)
+	}
+	if !updated.UpdatedAt.After(user.UpdatedAt) {
+		t.Errorf("Update() UpdatedAt = %v, want after %v", updated.UpdatedAt, user.UpdatedAt)
+	}
+
+	tests := []struct {
+		name      string
+		id        int64
+		updatedAt time.Time
+		update    userrepo.Update
+		wantErr   error
+	}{
+		{
+			name:      "stale version",
+			id:        user.ID,
+			updatedAt: user.UpdatedAt,
+			update:    userrepo.Update{Login: &newLogin},
+			wantErr:   userrepo.ErrConflict,
+		},
+		{
+			name:      "email taken",
+			id:        user.ID,
+			updatedAt: updated.UpdatedAt,
+			update:    userrepo.Update{Email: &takenEmail},
+			wantErr:   userrepo.ErrAlreadyExists,
+		},
+		{
+			name:      "deleted user",
+			id:        deleted.ID,
+			updatedAt: deleted.UpdatedAt,
+			update:    userrepo.Update{Login: &newLogin},
+			wantErr:   userrepo.ErrNotFound,
+		},
+		{
+			name:      "missing user",
+			id:        -1,
+			updatedAt: user.UpdatedAt,
+			update:    userrepo.Update{Login: &newLogin},
+			wantErr:   userrepo.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := repo.Update(ctx, tt.id, tt.updatedAt, tt.update); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Update() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUserRepositoryDelete(t *testing.T) {
+	ctx := context.Background()
+	repo := NewUserRepository(testLogger(), newTestPool(t))
+
+	user := createTestUser(t, repo, "delete")
+	if err := repo.Delete(ctx, user.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	if _, err := repo.GetByID(ctx, user.ID); !errors.Is(err, userrepo.ErrNotFound) {
+		t.Errorf("GetByID() of a deleted user error = %v, want ErrNotFound", err)
+	}
+	if err := repo.Delete(ctx, user.ID); !errors.Is(err, userrepo.ErrNotFound) {
+		t.Errorf("Delete() twice error = %v, want ErrNotFound", err)
+	}
+
+	// The email of a deleted user can be registered again.
+	if _, err := repo.Create(ctx, domain.User{Email: user.Email}); err != nil {
+		t.Errorf("Create() with the email of a deleted user error = %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package redis
+
+import (
+	"context"
+	"log/slog"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	outboxrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/outbox"
+)
+
+// Stream entry layout:
+//
+//	<stream>  key: <user id>, type: <event type>, body: <JSON envelope>
+
+// EventStreamSink is a Redis Streams implementation of outbox.Sink.
+type EventStreamSink struct {
+	log    *slog.Logger
+	rdb    *goredis.Client
+	stream string
+	maxLen int64
+}
+
+// NewEventStreamSink constructs a sink appending events to stream.
+// maxLen approximately caps the stream length; 0 keeps all entries.
+func NewEventStreamSink(log *slog.Logger, rdb *goredis.Client, stream string, maxLen int64) *EventStreamSink {
+	return &EventStreamSink{
+		log:    log,
+		rdb:    rdb,
+		stream: stream,
+		maxLen: maxLen,
+	}
+}
+
+// Ensure interface implementation at compile time.
+var _ outboxrepo.Sink = (*EventStreamSink)(nil)
+
+// Publish appends the message to the stream.
+func (s *EventStreamSink) Publish(ctx context.Context, m outboxrepo.Message) error {
+	const op = "EventStreamSink.Publish"
+
+	args := &goredis.XAddArgs{
+		Stream: s.stream,
+		Values: []any{
+			"key", m.Key,
+			"type", m.Type,
+			"body", m.Body,
+		},
+	}
+	if s.maxLen > 0 {
+		args.MaxLen = s.maxLen
+		args.Approx = true
+	}
+
+	if err := s.rdb.XAdd(ctx, args).Err(); err != nil {
+		s.log.Error(op+" failed",
+			slog.String("stream", s.stream),
+			slog.String("type", m.Type),
+			slog.Any("err", err),
+		)
+		return err
+	}
+
+	return nil
+}
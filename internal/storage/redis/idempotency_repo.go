@@ -0,0 +1,137 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	idempotencyrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/idempotency"
+)
+
+// Key layout:
+//
+//	idempotency:<scope>:<sha256(key)>  JSON record
+const idempotencyKeyPrefix = "idempotency:"
+
+// reserveScript claims a key unless it is already taken.
+//
+// KEYS[1] - record key.
+// ARGV[1] - record to store, ARGV[2] - ttl in milliseconds.
+//
+// Returns nil when the key is claimed, otherwise the existing record.
+var reserveScript = goredis.NewScript(`
+local existing = redis.call('GET', KEYS[1])
+if existing then
+	return existing
+end
+
+redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+return false
+`)
+
+// IdempotencyRepository is a Redis implementation of idempotency.Repository.
+type IdempotencyRepository struct {
+	log *slog.Logger
+	rdb *goredis.Client
+}
+
+// NewIdempotencyRepository constructs a new Redis-backed idempotency repository.
+func NewIdempotencyRepository(log *slog.Logger, rdb *goredis.Client) *IdempotencyRepository {
+	return &IdempotencyRepository{
+		log: log,
+		rdb: rdb,
+	}
+}
+
+// Ensure interface implementation at compile time.
+var _ idempotencyrepo.Repository = (*IdempotencyRepository)(nil)
+
+// Reserve atomically claims key for a call with fingerprint for ttl.
+func (r *IdempotencyRepository) Reserve(
+	ctx context.Context,
+	scope idempotencyrepo.Scope,
+	key, fingerprint string,
+	ttl time.Duration,
+) (idempotencyrepo.Record, bool, error) {
+	const op = "IdempotencyRepository.Reserve"
+
+	data, err := json.Marshal(idempotencyrepo.Record{Fingerprint: fingerprint})
+	if err != nil {
+		return idempotencyrepo.Record{}, false, err
+	}
+
+	existing, err := reserveScript.Run(ctx, r.rdb,
+		[]string{idempotencyKey(scope, key)},
+		data, ttl.Milliseconds(),
+	).Text()
+	if errors.Is(err, goredis.Nil) {
+		return idempotencyrepo.Record{}, true, nil
+	}
+	if err != nil {
+		r.log.Error(op+" failed",
+			slog.String("scope", string(scope)),
+			slog.Any("err", err),
+		)
+		return idempotencyrepo.Record{}, false, err
+	}
+
+	var record idempotencyrepo.Record
+	if err := json.Unmarshal([]byte(existing), &record); err != nil {
+		r.log.Error(op+" failed to decode record",
+			slog.String("scope", string(scope)),
+			slog.Any("err", err),
+		)
+		return idempotencyrepo.Record{}, false, err
+	}
+
+	return record, false, nil
+}
+
+// Complete stores the response of the call which reserved key.
+func (r *IdempotencyRepository) Complete(
+	ctx context.Context,
+	scope idempotencyrepo.Scope,
+	key string,
+	record idempotencyrepo.Record,
+	ttl time.Duration,
+) error {
+	const op = "IdempotencyRepository.Complete"
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	if err := r.rdb.Set(ctx, idempotencyKey(scope, key), data, ttl).Err(); err != nil {
+		r.log.Error(op+" failed",
+			slog.String("scope", string(scope)),
+			slog.Any("err", err),
+		)
+		return err
+	}
+
+	return nil
+}
+
+// Release forgets key, so a failed call can be retried with it.
+func (r *IdempotencyRepository) Release(ctx context.Context, scope idempotencyrepo.Scope, key string) error {
+	const op = "IdempotencyRepository.Release"
+
+	if err := r.rdb.Del(ctx, idempotencyKey(scope, key)).Err(); err != nil {
+		r.log.Error(op+" failed",
+			slog.String("scope", string(scope)),
+			slog.Any("err", err),
+		)
+		return err
+	}
+
+	return nil
+}
+
+func idempotencyKey(scope idempotencyrepo.Scope, key string) string {
+	return idempotencyKeyPrefix + string(scope) + ":" + hashToken(key)
+}
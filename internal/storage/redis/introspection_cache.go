@@ -0,0 +1,74 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	introspectionrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/introspection"
+)
+
+// Key layout:
+//
+//	introspection:<sha256(token)>  string, JSON encoded result
+const introspectionKeyPrefix = "introspection:"
+
+// IntrospectionCache is a Redis implementation of introspection.Cache.
+type IntrospectionCache struct {
+	log *slog.Logger
+	rdb *goredis.Client
+}
+
+// NewIntrospectionCache constructs a new Redis-backed introspection cache.
+func NewIntrospectionCache(log *slog.Logger, rdb *goredis.Client) *IntrospectionCache {
+	return &IntrospectionCache{
+		log: log,
+		rdb: rdb,
+	}
+}
+
+// Ensure interface implementation at compile time.
+var _ introspectionrepo.Cache = (*IntrospectionCache)(nil)
+
+// Get returns a cached result for the token.
+func (c *IntrospectionCache) Get(ctx context.Context, token string) (introspectionrepo.Result, error) {
+	const op = "IntrospectionCache.Get"
+
+	data, err := c.rdb.Get(ctx, introspectionKeyPrefix+hashToken(token)).Bytes()
+	if err != nil {
+		if errors.Is(err, goredis.Nil) {
+			return introspectionrepo.Result{}, introspectionrepo.ErrNotFound
+		}
+		c.log.Error(op+" failed", slog.Any("err", err))
+		return introspectionrepo.Result{}, err
+	}
+
+	var result introspectionrepo.Result
+	if err := json.Unmarshal(data, &result); err != nil {
+		c.log.Error(op+" failed", slog.Any("err", err))
+		return introspectionrepo.Result{}, err
+	}
+
+	return result, nil
+}
+
+// Set caches the result for ttl.
+func (c *IntrospectionCache) Set(ctx context.Context, token string, result introspectionrepo.Result, ttl time.Duration) error {
+	const op = "IntrospectionCache.Set"
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	if err := c.rdb.Set(ctx, introspectionKeyPrefix+hashToken(token), data, ttl).Err(); err != nil {
+		c.log.Error(op+" failed", slog.Any("err", err))
+		return err
+	}
+
+	return nil
+}
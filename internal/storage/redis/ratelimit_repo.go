@@ -0,0 +1,175 @@
+package redis
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	ratelimitrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/ratelimit"
+)
+
+// Key layout:
+//
+//	ratelimit:window:<sha256(key)>    sorted set of hits scored by unix milliseconds
+//	ratelimit:failures:<sha256(key)>  string, failures since the last lock
+//	ratelimit:level:<sha256(key)>     string, locks in a row
+//	ratelimit:lock:<sha256(key)>      string, exists while the key is locked
+const (
+	rateLimitWindowKeyPrefix   = "ratelimit:window:"
+	rateLimitFailuresKeyPrefix = "ratelimit:failures:"
+	rateLimitLevelKeyPrefix    = "ratelimit:level:"
+	rateLimitLockKeyPrefix     = "ratelimit:lock:"
+)
+
+// allowScript counts a hit in a sliding window log.
+//
+// KEYS[1] - window key.
+// ARGV[1] - current unix time in milliseconds, ARGV[2] - window in milliseconds,
+// ARGV[3] - max hits, ARGV[4] - unique member of the hit.
+//
+// Returns 0 when the hit is counted, otherwise milliseconds until the
+// oldest hit leaves the window.
+var allowScript = goredis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+
+if redis.call('ZCARD', KEYS[1]) < tonumber(ARGV[3]) then
+	redis.call('ZADD', KEYS[1], now, ARGV[4])
+	redis.call('PEXPIRE', KEYS[1], window)
+	return 0
+end
+
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+return math.max(tonumber(oldest[2]) + window - now, 1)
+`)
+
+// failScript counts a failure and locks the key with exponential backoff
+// once the threshold is reached.
+//
+// KEYS[1] - failures key, KEYS[2] - level key, KEYS[3] - lock key.
+// ARGV[1] - threshold, ARGV[2] - failure window in milliseconds,
+// ARGV[3] - first lock in milliseconds, ARGV[4] - max lock in milliseconds,
+// ARGV[5] - how long the level is remembered in milliseconds.
+//
+// Returns the lock duration in milliseconds, or 0 when the key is not locked.
+var failScript = goredis.NewScript(`
+local failures = redis.call('INCR', KEYS[1])
+if failures == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+if failures < tonumber(ARGV[1]) then
+	return 0
+end
+
+redis.call('DEL', KEYS[1])
+local level = redis.call('INCR', KEYS[2])
+redis.call('PEXPIRE', KEYS[2], ARGV[5])
+
+local d = math.min(tonumber(ARGV[3]) * 2 ^ (level - 1), tonumber(ARGV[4]))
+d = math.floor(d)
+redis.call('SET', KEYS[3], '1', 'PX', d)
+return d
+`)
+
+// RateLimitRepository is a Redis implementation of ratelimit.Repository.
+type RateLimitRepository struct {
+	log *slog.Logger
+	rdb *goredis.Client
+}
+
+// NewRateLimitRepository constructs a new Redis-backed rate limit repository.
+func NewRateLimitRepository(log *slog.Logger, rdb *goredis.Client) *RateLimitRepository {
+	return &RateLimitRepository{
+		log: log,
+		rdb: rdb,
+	}
+}
+
+// Ensure interface implementation at compile time.
+var _ ratelimitrepo.Repository = (*RateLimitRepository)(nil)
+
+// Allow counts a hit in the sliding window of key.
+func (r *RateLimitRepository) Allow(ctx context.Context, key string, limit int, window time.Duration) (time.Duration, error) {
+	const op = "RateLimitRepository.Allow"
+
+	member := make([]byte, 8)
+	if _, err := rand.Read(member); err != nil {
+		return 0, err
+	}
+
+	wait, err := allowScript.Run(ctx, r.rdb,
+		[]string{rateLimitWindowKeyPrefix + hashToken(key)},
+		time.Now().UnixMilli(), window.Milliseconds(), limit, hex.EncodeToString(member),
+	).Int64()
+	if err != nil {
+		r.log.Error(op+" failed", slog.Any("err", err))
+		return 0, err
+	}
+
+	return time.Duration(wait) * time.Millisecond, nil
+}
+
+// Fail counts a failure of key and locks it once the threshold is reached.
+func (r *RateLimitRepository) Fail(ctx context.Context, key string, lockout ratelimitrepo.Lockout) (time.Duration, error) {
+	const op = "RateLimitRepository.Fail"
+
+	hashed := hashToken(key)
+
+	locked, err := failScript.Run(ctx, r.rdb,
+		[]string{
+			rateLimitFailuresKeyPrefix + hashed,
+			rateLimitLevelKeyPrefix + hashed,
+			rateLimitLockKeyPrefix + hashed,
+		},
+		lockout.Threshold,
+		lockout.Window.Milliseconds(),
+		lockout.BaseDuration.Milliseconds(),
+		lockout.MaxDuration.Milliseconds(),
+		lockout.ResetAfter.Milliseconds(),
+	).Int64()
+	if err != nil {
+		r.log.Error(op+" failed", slog.Any("err", err))
+		return 0, err
+	}
+
+	return time.Duration(locked) * time.Millisecond, nil
+}
+
+// LockedFor returns how long the key stays locked.
+func (r *RateLimitRepository) LockedFor(ctx context.Context, key string) (time.Duration, error) {
+	const op = "RateLimitRepository.LockedFor"
+
+	ttl, err := r.rdb.PTTL(ctx, rateLimitLockKeyPrefix+hashToken(key)).Result()
+	if err != nil {
+		r.log.Error(op+" failed", slog.Any("err", err))
+		return 0, err
+	}
+
+	// Negative values mean the key does not exist or has no expiry.
+	return max(ttl, 0), nil
+}
+
+// Reset forgets failures and locks of key.
+func (r *RateLimitRepository) Reset(ctx context.Context, key string) error {
+	const op = "RateLimitRepository.Reset"
+
+	hashed := hashToken(key)
+
+	err := r.rdb.Del(ctx,
+		rateLimitFailuresKeyPrefix+hashed,
+		rateLimitLevelKeyPrefix+hashed,
+		rateLimitLockKeyPrefix+hashed,
+	).Err()
+	if err != nil {
+		r.log.Error(op+" failed", slog.Any("err", err))
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	ratelimitrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/ratelimit"
+)
+
+func testRateLimitKey(t *testing.T) string {
+	return t.Name() + ":" + strconv.FormatInt(testUserID(), 10)
+}
+
+func TestRateLimitRepositoryAllow(t *testing.T) {
+	ctx := context.Background()
+	repo := NewRateLimitRepository(testLogger(), newTestClient(t))
+
+	key := testRateLimitKey(t)
+	const (
+		limit  = 3
+		window = 500 * time.Millisecond
+	)
+
+	tests := []struct {
+		name      string
+		sleep     time.Duration
+		wantLimit bool
+	}{
+		{name: "first hit"},
+		{name: "second hit"},
+		{name: "last allowed hit"},
+		{name: "over the limit", wantLimit: true},
+		{name: "still over the limit", wantLimit: true},
+		{name: "window has slid", sleep: window},
+		{name: "second hit of the new window"},
+		{name: "third hit of the new window"},
+		{name: "over the limit again", wantLimit: true},
+	}
+
+	// Hits accumulate in one window, so the cases run in order.
+	for _, tt := range tests {
+		time.Sleep(tt.sleep)
+
+		wait, err := repo.Allow(ctx, key, limit, window)
+		if err != nil {
+			t.Fatalf("%s: Allow() error = %v", tt.name, err)
+		}
+		if limited := wait > 0; limited != tt.wantLimit {
+			t.Errorf("%s: Allow() wait = %v, want limited %v", tt.name, wait, tt.wantLimit)
+		}
+		if wait > window {
+			t.Errorf("%s: Allow() wait = %v, longer than the window", tt.name, wait)
+		}
+	}
+}
+
+func TestRateLimitRepositoryLockout(t *testing.T) {
+	ctx := context.Background()
+	repo := NewRateLimitRepository(testLogger(), newTestClient(t))
+
+	key := testRateLimitKey(t)
+	lockout := ratelimitrepo.Lockout{
+		Threshold:    2,
+		Window:       time.Minute,
+		BaseDuration: time.Second,
+		MaxDuration:  3 * time.Second,
+		ResetAfter:   time.Minute,
+	}
+
+	// Every second failure locks the key, each lock twice as long up to the max.
+	want := []time.Duration{0, time.Second, 0, 2 * time.Second, 0, 3 * time.Second}
+	for i, w := range want {
+		locked, err := repo.Fail(ctx, key, lockout)
+		if err != nil {
+			t.Fatalf("Fail() #%d error = %v", i+1, err)
+		}
+		if locked != w {
+			t.Errorf("Fail() #%d = %v, want %v", i+1, locked, w)
+		}
+	}
+
+	lockedFor, err := repo.LockedFor(ctx, key)
+	if err != nil {
+		t.Fatalf("LockedFor() error = %v", err)
+	}
+	if lockedFor <= 0 || lockedFor > lockout.MaxDuration {
+		t.Errorf("LockedFor() = %v, want within (0, %v]", lockedFor, lockout.MaxDuration)
+	}
+
+	if err := repo.Reset(ctx, key); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	if lockedFor, _ := repo.LockedFor(ctx, key); lockedFor != 0 {
+		t.Errorf("LockedFor() after Reset = %v, want 0", lockedFor)
+	}
+	if locked, _ := repo.Fail(ctx, key, lockout); locked != 0 {
+		t.Errorf("Fail() after Reset = %v, want the count to start over", locked)
+	}
+}
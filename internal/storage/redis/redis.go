@@ -1,13 +1,14 @@
 package redis
 
 import (
-	"authorization-service/internal/config"
 	"context"
 	"fmt"
 	"log/slog"
 	"time"
 
 	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/config"
 )
 
 // New creates a new Redis client and verifies the connection via Ping.
@@ -0,0 +1,47 @@
+package redis
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// newTestClient connects to the Redis at TEST_REDIS_ADDR (host:port,
+// password in TEST_REDIS_PASSWORD) and skips the test when it is not
+// set. Tests use random keys and do not flush the database.
+func newTestClient(t *testing.T) *goredis.Client {
+	t.Helper()
+
+	addr := os.Getenv("TEST_REDIS_ADDR")
+	if addr == "" {
+		t.Skip("TEST_REDIS_ADDR is not set")
+	}
+
+	rdb := goredis.NewClient(&goredis.Options{
+		Addr:     addr,
+		Password: os.Getenv("TEST_REDIS_PASSWORD"),
+	})
+	t.Cleanup(func() { _ = rdb.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		t.Fatalf("failed to ping Redis: %v", err)
+	}
+
+	return rdb
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// testUserID returns a user ID no other test run uses.
+func testUserID() int64 {
+	return time.Now().UnixNano()
+}
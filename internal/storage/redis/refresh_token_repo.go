@@ -0,0 +1,442 @@
+package redis
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	refreshrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/refreshtoken"
+)
+
+// Key layout:
+//
+//	refresh:token:<sha256(token)>  hash {family, user_id, client_id, used}
+//	refresh:family:<family_id>     hash {user_id, client_id, scope, user_agent, ip,
+//	                                     revoked, created_at, last_refresh_at}
+//	refresh:user:<user_id>         set of family IDs
+//
+// Used tokens are kept until they expire so that a replay can be detected.
+const (
+	refreshTokenKeyPrefix  = "refresh:token:"
+	refreshFamilyKeyPrefix = "refresh:family:"
+	refreshUserKeyPrefix   = "refresh:user:"
+)
+
+// rotateScript atomically rotates a refresh token.
+//
+// KEYS[1] - old token key, KEYS[2] - family key, KEYS[3] - new token key,
+// KEYS[4] - user families key.
+// ARGV[1] - expected family ID, ARGV[2] - TTL in milliseconds,
+// ARGV[3] - current unix time.
+var rotateScript = goredis.NewScript(`
+local t = redis.call('HMGET', KEYS[1], 'family', 'user_id', 'client_id', 'used')
+if not t[1] or t[1] ~= ARGV[1] then
+	return {'not_found'}
+end
+
+local f = redis.call('HMGET', KEYS[2], 'revoked', 'scope')
+local revoked = f[1]
+if not revoked then
+	return {'not_found'}
+end
+if revoked == '1' then
+	return {'revoked'}
+end
+
+if t[4] == '1' then
+	redis.call('HSET', KEYS[2], 'revoked', '1')
+	return {'reused'}
+end
+
+redis.call('HSET', KEYS[1], 'used', '1')
+redis.call('HSET', KEYS[2], 'last_refresh_at', ARGV[3])
+redis.call('HSET', KEYS[3], 'family', t[1], 'user_id', t[2], 'client_id', t[3], 'used', '0')
+redis.call('PEXPIRE', KEYS[3], ARGV[2])
+redis.call('PEXPIRE', KEYS[2], ARGV[2])
+redis.call('PEXPIRE', KEYS[4], ARGV[2])
+
+return {'ok', t[2], t[3], f[2] or ''}
+`)
+
+// revokeFamilyScript marks a family as revoked if it still exists.
+// A separate EXISTS and HSET would race with expiry and could recreate
+// an expired family without TTL.
+//
+// KEYS[1] - family key.
+//
+// Returns 1 when the family is revoked, 0 when it does not exist.
+var revokeFamilyScript = goredis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+
+redis.call('HSET', KEYS[1], 'revoked', '1')
+return 1
+`)
+
+// RefreshTokenRepository is a Redis implementation of refreshtoken.Repository.
+type RefreshTokenRepository struct {
+	log *slog.Logger
+	rdb *goredis.Client
+	ttl time.Duration
+}
+
+// NewRefreshTokenRepository constructs a new Redis-backed refresh token repository.
+// ttl is a lifetime of a single token; a family lives as long as its newest token.
+func NewRefreshTokenRepository(log *slog.Logger, rdb *goredis.Client, ttl time.Duration) *RefreshTokenRepository {
+	return &RefreshTokenRepository{
+		log: log,
+		rdb: rdb,
+		ttl: ttl,
+	}
+}
+
+// Ensure interface implementation at compile time.
+var _ refreshrepo.Repository = (*RefreshTokenRepository)(nil)
+
+// Create starts a new token family and stores its first token.
+func (r *RefreshTokenRepository) Create(ctx context.Context, token string, info refreshrepo.SessionInfo) (refreshrepo.Token, error) {
+	const op = "RefreshTokenRepository.Create"
+
+	familyID, err := newFamilyID()
+	if err != nil {
+		return refreshrepo.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	familyKey := refreshFamilyKeyPrefix + familyID
+	tokenKey := refreshTokenKeyPrefix + hashToken(token)
+	userKey := refreshUserKey(info.UserID)
+	now := time.Now().Unix()
+
+	_, err = r.rdb.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.HSet(ctx, familyKey,
+			"user_id", info.UserID,
+			"client_id", info.ClientID,
+			"scope", info.Scope,
+			"user_agent", info.UserAgent,
+			"ip", info.IP,
+			"revoked", "0",
+			"created_at", now,
+			"last_refresh_at", now,
+		)
+		pipe.PExpire(ctx, familyKey, r.ttl)
+
+		pipe.HSet(ctx, tokenKey,
+			"family", familyID,
+			"user_id", info.UserID,
+			"client_id", info.ClientID,
+			"used", "0",
+		)
+		pipe.PExpire(ctx, tokenKey, r.ttl)
+
+		pipe.SAdd(ctx, userKey, familyID)
+		pipe.PExpire(ctx, userKey, r.ttl)
+		return nil
+	})
+	if err != nil {
+		r.log.Error(op+" failed",
+			slog.Int64("user_id", info.UserID),
+			slog.Any("err", err),
+		)
+		return refreshrepo.Token{}, err
+	}
+
+	return refreshrepo.Token{
+		FamilyID: familyID,
+		UserID:   info.UserID,
+		ClientID: info.ClientID,
+		Scope:    info.Scope,
+	}, nil
+}
+
+// Get looks up a token and checks that its family has not been revoked.
+func (r *RefreshTokenRepository) Get(ctx context.Context, token string) (refreshrepo.Token, error) {
+	const op = "RefreshTokenRepository.Get"
+
+	values, err := r.rdb.HMGet(ctx, refreshTokenKeyPrefix+hashToken(token),
+		"family", "user_id", "client_id",
+	).Result()
+	if err != nil {
+		r.log.Error(op+" failed", slog.Any("err", err))
+		return refreshrepo.Token{}, err
+	}
+
+	familyID, _ := values[0].(string)
+	if familyID == "" {
+		return refreshrepo.Token{}, refreshrepo.ErrNotFound
+	}
+
+	family, err := r.rdb.HMGet(ctx, refreshFamilyKeyPrefix+familyID, "revoked", "scope").Result()
+	if err != nil {
+		r.log.Error(op+" failed", slog.Any("err", err))
+		return refreshrepo.Token{}, err
+	}
+
+	revoked, _ := family[0].(string)
+	if revoked == "" {
+		return refreshrepo.Token{}, refreshrepo.ErrNotFound
+	}
+	if revoked == "1" {
+		return refreshrepo.Token{}, refreshrepo.ErrRevoked
+	}
+
+	userID, _ := values[1].(string)
+	clientID, _ := values[2].(string)
+	scope, _ := family[1].(string)
+
+	return newRefreshToken(familyID, userID, clientID, scope)
+}
+
+// GetFamily looks up a token family and checks that it has not been revoked.
+func (r *RefreshTokenRepository) GetFamily(ctx context.Context, familyID string) (refreshrepo.Token, error) {
+	const op = "RefreshTokenRepository.GetFamily"
+
+	values, err := r.rdb.HMGet(ctx, refreshFamilyKeyPrefix+familyID,
+		"user_id", "client_id", "revoked", "scope",
+	).Result()
+	if err != nil {
+		r.log.Error(op+" failed", slog.Any("err", err))
+		return refreshrepo.Token{}, err
+	}
+
+	userID, _ := values[0].(string)
+	if userID == "" {
+		return refreshrepo.Token{}, refreshrepo.ErrNotFound
+	}
+	if revoked, _ := values[2].(string); revoked == "1" {
+		return refreshrepo.Token{}, refreshrepo.ErrRevoked
+	}
+
+	clientID, _ := values[1].(string)
+	scope, _ := values[3].(string)
+
+	return newRefreshToken(familyID, userID, clientID, scope)
+}
+
+// Rotate marks oldToken as used and stores newToken in the same family.
+func (r *RefreshTokenRepository) Rotate(ctx context.Context, oldToken, newToken string) (refreshrepo.Token, error) {
+	const op = "RefreshTokenRepository.Rotate"
+
+	oldKey := refreshTokenKeyPrefix + hashToken(oldToken)
+
+	values, err := r.rdb.HMGet(ctx, oldKey, "family", "user_id").Result()
+	if err != nil {
+		r.log.Error(op+" failed", slog.Any("err", err))
+		return refreshrepo.Token{}, err
+	}
+
+	familyID, _ := values[0].(string)
+	userID, _ := values[1].(string)
+	if familyID == "" {
+		return refreshrepo.Token{}, refreshrepo.ErrNotFound
+	}
+
+	res, err := rotateScript.Run(ctx, r.rdb,
+		[]string{
+			oldKey,
+			refreshFamilyKeyPrefix + familyID,
+			refreshTokenKeyPrefix + hashToken(newToken),
+			refreshUserKeyPrefix + userID,
+		},
+		familyID, r.ttl.Milliseconds(), time.Now().Unix(),
+	).StringSlice()
+	if err != nil {
+		r.log.Error(op+" failed",
+			slog.String("family_id", familyID),
+			slog.Any("err", err),
+		)
+		return refreshrepo.Token{}, err
+	}
+
+	switch res[0] {
+	case "ok":
+		return newRefreshToken(familyID, res[1], res[2], res[3])
+	case "reused":
+		r.log.Warn("refresh token reuse detected, family revoked",
+			slog.String("family_id", familyID),
+		)
+		return refreshrepo.Token{}, refreshrepo.ErrReused
+	case "revoked":
+		return refreshrepo.Token{}, refreshrepo.ErrRevoked
+	default:
+		return refreshrepo.Token{}, refreshrepo.ErrNotFound
+	}
+}
+
+// ListSessions returns families of the user which are neither revoked
+// nor expired, most recently refreshed first.
+func (r *RefreshTokenRepository) ListSessions(ctx context.Context, userID int64) ([]refreshrepo.Session, error) {
+	const op = "RefreshTokenRepository.ListSessions"
+
+	userKey := refreshUserKey(userID)
+
+	familyIDs, err := r.rdb.SMembers(ctx, userKey).Result()
+	if err != nil {
+		r.log.Error(op+" failed",
+			slog.Int64("user_id", userID),
+			slog.Any("err", err),
+		)
+		return nil, err
+	}
+
+	cmds := make([]*goredis.MapStringStringCmd, len(familyIDs))
+	_, err = r.rdb.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+		for i, familyID := range familyIDs {
+			cmds[i] = pipe.HGetAll(ctx, refreshFamilyKeyPrefix+familyID)
+		}
+		return nil
+	})
+	if err != nil {
+		r.log.Error(op+" failed",
+			slog.Int64("user_id", userID),
+			slog.Any("err", err),
+		)
+		return nil, err
+	}
+
+	sessions := make([]refreshrepo.Session, 0, len(familyIDs))
+	for i, cmd := range cmds {
+		family := cmd.Val()
+		if len(family) == 0 {
+			// Family has expired, forget it.
+			if err := r.rdb.SRem(ctx, userKey, familyIDs[i]).Err(); err != nil {
+				r.log.Warn(op+": failed to remove expired family",
+					slog.String("family_id", familyIDs[i]),
+					slog.Any("err", err),
+				)
+			}
+			continue
+		}
+		if family["revoked"] == "1" {
+			continue
+		}
+
+		sessions = append(sessions, refreshrepo.Session{
+			ID:            familyIDs[i],
+			UserID:        userID,
+			ClientID:      family["client_id"],
+			UserAgent:     family["user_agent"],
+			IP:            family["ip"],
+			CreatedAt:     unixTime(family["created_at"]),
+			LastRefreshAt: unixTime(family["last_refresh_at"]),
+		})
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastRefreshAt.After(sessions[j].LastRefreshAt)
+	})
+
+	return sessions, nil
+}
+
+// RevokeFamily marks the family as revoked. The family key is kept
+// until it expires, so any token of the family is rejected afterwards.
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	const op = "RefreshTokenRepository.RevokeFamily"
+
+	revoked, err := revokeFamilyScript.Run(ctx, r.rdb,
+		[]string{refreshFamilyKeyPrefix + familyID},
+	).Int()
+	if err != nil {
+		r.log.Error(op+" failed",
+			slog.String("family_id", familyID),
+			slog.Any("err", err),
+		)
+		return err
+	}
+	if revoked == 0 {
+		return refreshrepo.ErrNotFound
+	}
+
+	return nil
+}
+
+// RevokeUser revokes every token family of the user except exceptFamilyID.
+func (r *RefreshTokenRepository) RevokeUser(ctx context.Context, userID int64, exceptFamilyID string) error {
+	const op = "RefreshTokenRepository.RevokeUser"
+
+	userKey := refreshUserKey(userID)
+
+	familyIDs, err := r.rdb.SMembers(ctx, userKey).Result()
+	if err != nil {
+		r.log.Error(op+" failed",
+			slog.Int64("user_id", userID),
+			slog.Any("err", err),
+		)
+		return err
+	}
+
+	for _, familyID := range familyIDs {
+		if familyID == exceptFamilyID {
+			continue
+		}
+
+		err := r.RevokeFamily(ctx, familyID)
+		if errors.Is(err, refreshrepo.ErrNotFound) {
+			// Family has expired, forget it.
+			if err := r.rdb.SRem(ctx, userKey, familyID).Err(); err != nil {
+				r.log.Warn(op+": failed to remove expired family",
+					slog.String("family_id", familyID),
+					slog.Any("err", err),
+				)
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// unixTime parses unix seconds; malformed values give zero time.
+func unixTime(value string) time.Time {
+	sec, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
+
+func refreshUserKey(userID int64) string {
+	return refreshUserKeyPrefix + strconv.FormatInt(userID, 10)
+}
+
+func newRefreshToken(familyID, userID, clientID, scope string) (refreshrepo.Token, error) {
+	id, err := strconv.ParseInt(userID, 10, 64)
+	if err != nil {
+		return refreshrepo.Token{}, fmt.Errorf("malformed user_id in refresh token: %w", err)
+	}
+
+	return refreshrepo.Token{
+		FamilyID: familyID,
+		UserID:   id,
+		ClientID: clientID,
+		Scope:    scope,
+	}, nil
+}
+
+// hashToken returns a hex-encoded SHA-256 of the token.
+// Refresh tokens carry 256 bits of entropy, so a plain hash is sufficient.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func newFamilyID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,130 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	refreshrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/refreshtoken"
+)
+
+func newTestToken(t *testing.T) string {
+	t.Helper()
+
+	token, err := newFamilyID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestRefreshTokenRepositoryRotate(t *testing.T) {
+	ctx := context.Background()
+	repo := NewRefreshTokenRepository(testLogger(), newTestClient(t), time.Minute)
+
+	first := newTestToken(t)
+	created, err := repo.Create(ctx, first, refreshrepo.SessionInfo{
+		UserID:   testUserID(),
+		ClientID: "web",
+		Scope:    "files:read",
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	second := newTestToken(t)
+	third := newTestToken(t)
+
+	steps := []struct {
+		name    string
+		run     func() (refreshrepo.Token, error)
+		wantErr error
+	}{
+		{name: "get first", run: func() (refreshrepo.Token, error) { return repo.Get(ctx, first) }},
+		{name: "rotate first", run: func() (refreshrepo.Token, error) { return repo.Rotate(ctx, first, second) }},
+		{name: "get second", run: func() (refreshrepo.Token, error) { return repo.Get(ctx, second) }},
+		{name: "unknown token", run: func() (refreshrepo.Token, error) { return repo.Rotate(ctx, newTestToken(t), third) }, wantErr: refreshrepo.ErrNotFound},
+		{name: "replay first", run: func() (refreshrepo.Token, error) { return repo.Rotate(ctx, first, third) }, wantErr: refreshrepo.ErrReused},
+		{name: "second after replay", run: func() (refreshrepo.Token, error) { return repo.Get(ctx, second) }, wantErr: refreshrepo.ErrRevoked},
+		{name: "rotate second after replay", run: func() (refreshrepo.Token, error) { return repo.Rotate(ctx, second, third) }, wantErr: refreshrepo.ErrRevoked},
+		{name: "family after replay", run: func() (refreshrepo.Token, error) { return repo.GetFamily(ctx, created.FamilyID) }, wantErr: refreshrepo.ErrRevoked},
+	}
+
+	// Steps depend on each other, so they run in order in one test.
+	for _, step := range steps {
+		token, err := step.run()
+		if !errors.Is(err, step.wantErr) {
+			t.Fatalf("%s: error = %v, want %v", step.name, err, step.wantErr)
+		}
+		if err == nil && token != created {
+			t.Errorf("%s: token = %+v, want %+v", step.name, token, created)
+		}
+	}
+}
+
+func TestRefreshTokenRepositoryRevokeFamily(t *testing.T) {
+	ctx := context.Background()
+	rdb := newTestClient(t)
+	repo := NewRefreshTokenRepository(testLogger(), rdb, time.Minute)
+
+	token := newTestToken(t)
+	created, err := repo.Create(ctx, token, refreshrepo.SessionInfo{UserID: testUserID()})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if err := repo.RevokeFamily(ctx, created.FamilyID); err != nil {
+		t.Fatalf("RevokeFamily() error = %v", err)
+	}
+	if _, err := repo.Get(ctx, token); !errors.Is(err, refreshrepo.ErrRevoked) {
+		t.Errorf("Get() after revoke error = %v, want ErrRevoked", err)
+	}
+
+	// The revoked family keeps its TTL, so it still expires.
+	if ttl := rdb.PTTL(ctx, refreshFamilyKeyPrefix+created.FamilyID).Val(); ttl <= 0 {
+		t.Errorf("revoked family TTL = %v, want positive", ttl)
+	}
+
+	// An expired or unknown family is not recreated without TTL.
+	missing := newTestToken(t)
+	if err := repo.RevokeFamily(ctx, missing); !errors.Is(err, refreshrepo.ErrNotFound) {
+		t.Errorf("RevokeFamily() of unknown family error = %v, want ErrNotFound", err)
+	}
+	if n := rdb.Exists(ctx, refreshFamilyKeyPrefix+missing).Val(); n != 0 {
+		t.Error("RevokeFamily() created the unknown family")
+	}
+}
+
+func TestRefreshTokenRepositoryRevokeUser(t *testing.T) {
+	ctx := context.Background()
+	repo := NewRefreshTokenRepository(testLogger(), newTestClient(t), time.Minute)
+
+	userID := testUserID()
+	tokens := []string{newTestToken(t), newTestToken(t), newTestToken(t)}
+	families := make([]string, len(tokens))
+	for i, token := range tokens {
+		created, err := repo.Create(ctx, token, refreshrepo.SessionInfo{UserID: userID, ClientID: "web"})
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		families[i] = created.FamilyID
+	}
+
+	if err := repo.RevokeUser(ctx, userID, families[0]); err != nil {
+		t.Fatalf("RevokeUser() error = %v", err)
+	}
+
+	sessions, err := repo.ListSessions(ctx, userID)
+	if err != nil {
+		t.Fatalf("ListSessions() error = %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].ID != families[0] {
+		t.Errorf("ListSessions() = %+v, want only family %s", sessions, families[0])
+	}
+	for _, token := range tokens[1:] {
+		if _, err := repo.Get(ctx, token); !errors.Is(err, refreshrepo.ErrRevoked) {
+			t.Errorf("Get() of a revoked session error = %v, want ErrRevoked", err)
+		}
+	}
+}
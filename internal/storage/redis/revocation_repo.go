@@ -0,0 +1,80 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	revocationrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/revocation"
+)
+
+// Key layout:
+//
+//	revocation:user:<user_id>  string, unix seconds of the marker
+//
+// A marker is only needed while tokens issued before it can be alive,
+// so it expires after the access token lifetime.
+const revocationUserKeyPrefix = "revocation:user:"
+
+// RevocationRepository is a Redis implementation of revocation.Repository.
+type RevocationRepository struct {
+	log *slog.Logger
+	rdb *goredis.Client
+	ttl time.Duration
+}
+
+// NewRevocationRepository constructs a new Redis-backed revocation repository.
+// ttl is the access token lifetime.
+func NewRevocationRepository(log *slog.Logger, rdb *goredis.Client, ttl time.Duration) *RevocationRepository {
+	return &RevocationRepository{
+		log: log,
+		rdb: rdb,
+		ttl: ttl,
+	}
+}
+
+// Ensure interface implementation at compile time.
+var _ revocationrepo.Repository = (*RevocationRepository)(nil)
+
+// RevokeUser invalidates access tokens of the user issued before at.
+func (r *RevocationRepository) RevokeUser(ctx context.Context, userID int64, at time.Time) error {
+	const op = "RevocationRepository.RevokeUser"
+
+	err := r.rdb.Set(ctx, revocationUserKey(userID), at.Unix(), r.ttl).Err()
+	if err != nil {
+		r.log.Error(op+" failed",
+			slog.Int64("user_id", userID),
+			slog.Any("err", err),
+		)
+		return err
+	}
+
+	return nil
+}
+
+// RevokedAt returns the marker of the user or zero time if there is none.
+func (r *RevocationRepository) RevokedAt(ctx context.Context, userID int64) (time.Time, error) {
+	const op = "RevocationRepository.RevokedAt"
+
+	value, err := r.rdb.Get(ctx, revocationUserKey(userID)).Int64()
+	if err != nil {
+		if errors.Is(err, goredis.Nil) {
+			return time.Time{}, nil
+		}
+		r.log.Error(op+" failed",
+			slog.Int64("user_id", userID),
+			slog.Any("err", err),
+		)
+		return time.Time{}, err
+	}
+
+	return time.Unix(value, 0), nil
+}
+
+func revocationUserKey(userID int64) string {
+	return revocationUserKeyPrefix + strconv.FormatInt(userID, 10)
+}
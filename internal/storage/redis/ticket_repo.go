@@ -0,0 +1,91 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	ticketrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/ticket"
+)
+
+// Key layout:
+//
+//	ticket:<kind>:<sha256(key)>  string value
+const ticketKeyPrefix = "ticket:"
+
+// TicketRepository is a Redis implementation of ticket.Repository.
+type TicketRepository struct {
+	log *slog.Logger
+	rdb *goredis.Client
+}
+
+// NewTicketRepository constructs a new Redis-backed ticket repository.
+func NewTicketRepository(log *slog.Logger, rdb *goredis.Client) *TicketRepository {
+	return &TicketRepository{
+		log: log,
+		rdb: rdb,
+	}
+}
+
+// Ensure interface implementation at compile time.
+var _ ticketrepo.Repository = (*TicketRepository)(nil)
+
+// Create stores value under key; the ticket expires after ttl.
+func (r *TicketRepository) Create(ctx context.Context, kind ticketrepo.Kind, key string, value []byte, ttl time.Duration) error {
+	const op = "TicketRepository.Create"
+
+	if err := r.rdb.Set(ctx, ticketKey(kind, key), value, ttl).Err(); err != nil {
+		r.log.Error(op+" failed",
+			slog.String("kind", string(kind)),
+			slog.Any("err", err),
+		)
+		return err
+	}
+
+	return nil
+}
+
+// Peek returns the ticket without consuming it.
+func (r *TicketRepository) Peek(ctx context.Context, kind ticketrepo.Kind, key string) ([]byte, error) {
+	const op = "TicketRepository.Peek"
+
+	value, err := r.rdb.Get(ctx, ticketKey(kind, key)).Bytes()
+	if err != nil {
+		if errors.Is(err, goredis.Nil) {
+			return nil, ticketrepo.ErrNotFound
+		}
+		r.log.Error(op+" failed",
+			slog.String("kind", string(kind)),
+			slog.Any("err", err),
+		)
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// Consume atomically returns and deletes the ticket.
+func (r *TicketRepository) Consume(ctx context.Context, kind ticketrepo.Kind, key string) ([]byte, error) {
+	const op = "TicketRepository.Consume"
+
+	value, err := r.rdb.GetDel(ctx, ticketKey(kind, key)).Bytes()
+	if err != nil {
+		if errors.Is(err, goredis.Nil) {
+			return nil, ticketrepo.ErrNotFound
+		}
+		r.log.Error(op+" failed",
+			slog.String("kind", string(kind)),
+			slog.Any("err", err),
+		)
+		return nil, err
+	}
+
+	return value, nil
+}
+
+func ticketKey(kind ticketrepo.Kind, key string) string {
+	return ticketKeyPrefix + string(kind) + ":" + hashToken(key)
+}
@@ -0,0 +1,154 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	verificationrepo "github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/repository/verification"
+)
+
+// Key layout:
+//
+//	verification:flow:<flow_id>  hash {purpose, user_id, email, client_id, code_hash, attempts}
+const verificationFlowKeyPrefix = "verification:flow:"
+
+// attemptScript increments the attempt counter of an existing flow
+// and returns all its fields. A missing flow yields an empty result,
+// so that HINCRBY never resurrects an expired flow without TTL.
+//
+// KEYS[1] - flow key.
+var attemptScript = goredis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return {}
+end
+redis.call('HINCRBY', KEYS[1], 'attempts', 1)
+return redis.call('HMGET', KEYS[1], 'purpose', 'user_id', 'email', 'client_id', 'code_hash', 'attempts')
+`)
+
+// VerificationRepository is a Redis implementation of verification.Repository.
+type VerificationRepository struct {
+	log *slog.Logger
+	rdb *goredis.Client
+}
+
+// NewVerificationRepository constructs a new Redis-backed verification flow repository.
+func NewVerificationRepository(log *slog.Logger, rdb *goredis.Client) *VerificationRepository {
+	return &VerificationRepository{
+		log: log,
+		rdb: rdb,
+	}
+}
+
+// Ensure interface implementation at compile time.
+var _ verificationrepo.Repository = (*VerificationRepository)(nil)
+
+// Create stores a new flow which expires after ttl.
+func (r *VerificationRepository) Create(ctx context.Context, flow verificationrepo.Flow, ttl time.Duration) error {
+	const op = "VerificationRepository.Create"
+
+	key := verificationFlowKeyPrefix + flow.ID
+
+	_, err := r.rdb.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.HSet(ctx, key,
+			"purpose", flow.Purpose,
+			"user_id", flow.UserID,
+			"email", flow.Email,
+			"client_id", flow.ClientID,
+			"code_hash", flow.CodeHash,
+			"attempts", flow.Attempts,
+		)
+		pipe.PExpire(ctx, key, ttl)
+		return nil
+	})
+	if err != nil {
+		r.log.Error(op+" failed",
+			slog.String("purpose", flow.Purpose),
+			slog.Int64("user_id", flow.UserID),
+			slog.Any("err", err),
+		)
+		return err
+	}
+
+	return nil
+}
+
+// Attempt registers one more verification attempt and returns the flow.
+func (r *VerificationRepository) Attempt(ctx context.Context, id string) (verificationrepo.Flow, error) {
+	const op = "VerificationRepository.Attempt"
+
+	values, err := attemptScript.Run(ctx, r.rdb, []string{verificationFlowKeyPrefix + id}).StringSlice()
+	if err != nil {
+		r.log.Error(op+" failed", slog.Any("err", err))
+		return verificationrepo.Flow{}, err
+	}
+	if len(values) == 0 {
+		return verificationrepo.Flow{}, verificationrepo.ErrNotFound
+	}
+
+	return parseFlow(op, id, values)
+}
+
+// Get returns the flow without counting an attempt.
+func (r *VerificationRepository) Get(ctx context.Context, id string) (verificationrepo.Flow, error) {
+	const op = "VerificationRepository.Get"
+
+	res, err := r.rdb.HMGet(ctx, verificationFlowKeyPrefix+id,
+		"purpose", "user_id", "email", "client_id", "code_hash", "attempts",
+	).Result()
+	if err != nil {
+		r.log.Error(op+" failed", slog.Any("err", err))
+		return verificationrepo.Flow{}, err
+	}
+
+	values := make([]string, len(res))
+	for i, v := range res {
+		values[i], _ = v.(string)
+	}
+	if values[1] == "" {
+		return verificationrepo.Flow{}, verificationrepo.ErrNotFound
+	}
+
+	return parseFlow(op, id, values)
+}
+
+// parseFlow builds a flow from field values in the order
+// purpose, user_id, email, client_id, code_hash, attempts.
+func parseFlow(op, id string, values []string) (verificationrepo.Flow, error) {
+	userID, err := strconv.ParseInt(values[1], 10, 64)
+	if err != nil {
+		return verificationrepo.Flow{}, fmt.Errorf("%s: malformed user_id: %w", op, err)
+	}
+
+	attempts, err := strconv.Atoi(values[5])
+	if err != nil {
+		return verificationrepo.Flow{}, fmt.Errorf("%s: malformed attempts: %w", op, err)
+	}
+
+	return verificationrepo.Flow{
+		ID:       id,
+		Purpose:  values[0],
+		UserID:   userID,
+		Email:    values[2],
+		ClientID: values[3],
+		CodeHash: values[4],
+		Attempts: attempts,
+	}, nil
+}
+
+// Delete removes the flow.
+func (r *VerificationRepository) Delete(ctx context.Context, id string) error {
+	const op = "VerificationRepository.Delete"
+
+	if err := r.rdb.Del(ctx, verificationFlowKeyPrefix+id).Err(); err != nil && !errors.Is(err, goredis.Nil) {
+		r.log.Error(op+" failed", slog.Any("err", err))
+		return err
+	}
+
+	return nil
+}
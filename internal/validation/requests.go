@@ -0,0 +1,202 @@
+package validation
+
+import (
+	authorizationservicev1 "github.com/GrishanyaaShustov/CloudStorage-Protos-Service/gen/go/authorization-service"
+)
+
+// Register validates a registration request and normalizes its email and login.
+func Register(request *authorizationservicev1.RegisterRequest) error {
+	var v validator
+	v.login("login", &request.Login)
+	v.email("email", &request.Email)
+	v.password("password", request.GetPassword())
+	return v.err()
+}
+
+// VerifyEmail validates an email verification request.
+func VerifyEmail(request *authorizationservicev1.VerifyEmailRequest) error {
+	var v validator
+	v.token("flow_id", request.GetFlowId(), maxIDLength, true)
+	v.token("verification_code", request.GetVerificationCode(), maxCodeLength, true)
+	return v.err()
+}
+
+// Login validates a password login request and normalizes its email.
+func Login(request *authorizationservicev1.LoginRequest) error {
+	var v validator
+	v.email("email", &request.Email)
+	v.password("password", request.GetPassword())
+	// Required like in the MFA and passkey steps, which have to match it.
+	v.token("client_id", request.GetClientId(), maxIDLength, true)
+	return v.err()
+}
+
+// RefreshToken validates a token refresh request.
+func RefreshToken(request *authorizationservicev1.RefreshTokenRequest) error {
+	var v validator
+	v.token("refresh_token", request.GetRefreshToken(), maxTokenLength, true)
+	v.token("client_id", request.GetClientId(), maxIDLength, false)
+	return v.err()
+}
+
+// Logout validates a logout request.
+func Logout(request *authorizationservicev1.LogoutRequest) error {
+	var v validator
+	v.token("refresh_token", request.GetRefreshToken(), maxTokenLength, true)
+	v.token("client_id", request.GetClientId(), maxIDLength, false)
+	return v.err()
+}
+
+// RequestPasswordReset validates a password reset request and normalizes its email.
+func RequestPasswordReset(request *authorizationservicev1.RequestPasswordResetRequest) error {
+	var v validator
+	v.email("email", &request.Email)
+	return v.err()
+}
+
+// ResetPassword validates a request setting a new password by reset token.
+func ResetPassword(request *authorizationservicev1.ResetPasswordRequest) error {
+	var v validator
+	v.token("token", request.GetToken(), maxTokenLength, true)
+	v.password("new_password", request.GetNewPassword())
+	return v.err()
+}
+
+// ChangePassword validates a password change request.
+func ChangePassword(request *authorizationservicev1.ChangePasswordRequest) error {
+	var v validator
+	v.password("old_password", request.GetOldPassword())
+	v.password("new_password", request.GetNewPassword())
+	return v.err()
+}
+
+// IntrospectToken validates a token introspection request. Unknown
+// token type hints are ignored, as RFC 7662 requires.
+func IntrospectToken(request *authorizationservicev1.IntrospectTokenRequest) error {
+	var v validator
+	v.token("token", request.GetToken(), maxTokenLength, true)
+	return v.err()
+}
+
+// ListSessions validates a request listing sessions of a user.
+func ListSessions(request *authorizationservicev1.ListSessionsRequest) error {
+	var v validator
+	v.userID("user_id", request.GetUserId())
+	return v.err()
+}
+
+// RevokeSession validates a request revoking one session.
+func RevokeSession(request *authorizationservicev1.RevokeSessionRequest) error {
+	var v validator
+	v.token("session_id", request.GetSessionId(), maxIDLength, true)
+	v.userID("user_id", request.GetUserId())
+	return v.err()
+}
+
+// RevokeAllSessions validates a request revoking all sessions of a user.
+func RevokeAllSessions(request *authorizationservicev1.RevokeAllSessionsRequest) error {
+	var v validator
+	v.userID("user_id", request.GetUserId())
+	return v.err()
+}
+
+// ConfirmTOTP validates a TOTP enrollment confirmation.
+func ConfirmTOTP(request *authorizationservicev1.ConfirmTOTPRequest) error {
+	var v validator
+	v.token("code", request.GetCode(), maxCodeLength, true)
+	return v.err()
+}
+
+// CompleteMFAChallenge validates a second factor answer.
+func CompleteMFAChallenge(request *authorizationservicev1.CompleteMFAChallengeRequest) error {
+	var v validator
+	v.token("challenge_id", request.GetChallengeId(), maxIDLength, true)
+	v.token("code", request.GetCode(), maxCodeLength, true)
+	v.token("client_id", request.GetClientId(), maxIDLength, true)
+	return v.err()
+}
+
+// FinishPasskeyRegistration validates a passkey attestation.
+func FinishPasskeyRegistration(request *authorizationservicev1.FinishPasskeyRegistrationRequest) error {
+	var v validator
+	v.token("session_id", request.GetSessionId(), maxIDLength, true)
+	if v.required("credential_json", request.GetCredentialJson()) {
+		v.maxLength("credential_json", request.GetCredentialJson(), maxCredentialSize)
+	}
+	v.maxLength("name", request.GetName(), maxNameLength)
+	return v.err()
+}
+
+// BeginPasskeyLogin validates a request starting a passkey login.
+func BeginPasskeyLogin(request *authorizationservicev1.BeginPasskeyLoginRequest) error {
+	var v validator
+	v.token("mfa_challenge_id", request.GetMfaChallengeId(), maxIDLength, false)
+	return v.err()
+}
+
+// FinishPasskeyLogin validates a passkey assertion.
+func FinishPasskeyLogin(request *authorizationservicev1.FinishPasskeyLoginRequest) error {
+	var v validator
+	v.token("session_id", request.GetSessionId(), maxIDLength, true)
+	if v.required("credential_json", request.GetCredentialJson()) {
+		v.maxLength("credential_json", request.GetCredentialJson(), maxCredentialSize)
+	}
+	v.token("client_id", request.GetClientId(), maxIDLength, true)
+	return v.err()
+}
+
+// StartSocialLogin validates a request starting a social login.
+// Whether the provider is configured is checked by the service.
+func StartSocialLogin(request *authorizationservicev1.StartSocialLoginRequest) error {
+	var v validator
+	v.token("provider", request.GetProvider(), maxIDLength, true)
+	v.token("client_id", request.GetClientId(), maxIDLength, true)
+	return v.err()
+}
+
+// CompleteSocialLogin validates a provider callback.
+func CompleteSocialLogin(request *authorizationservicev1.CompleteSocialLoginRequest) error {
+	var v validator
+	v.token("provider", request.GetProvider(), maxIDLength, true)
+	v.token("state", request.GetState(), maxTokenLength, true)
+	v.token("code", request.GetCode(), maxTokenLength, true)
+	v.token("client_id", request.GetClientId(), maxIDLength, true)
+	return v.err()
+}
+
+// LinkIdentity validates a provider callback linking an identity.
+func LinkIdentity(request *authorizationservicev1.LinkIdentityRequest) error {
+	var v validator
+	v.token("provider", request.GetProvider(), maxIDLength, true)
+	v.token("state", request.GetState(), maxTokenLength, true)
+	v.token("code", request.GetCode(), maxTokenLength, true)
+	v.token("client_id", request.GetClientId(), maxIDLength, true)
+	return v.err()
+}
+
+// UnlinkIdentity validates a request unlinking an identity.
+func UnlinkIdentity(request *authorizationservicev1.UnlinkIdentityRequest) error {
+	var v validator
+	v.token("provider", request.GetProvider(), maxIDLength, true)
+	return v.err()
+}
+
+// RequestLoginCode validates a passwordless login request and normalizes its email.
+func RequestLoginCode(request *authorizationservicev1.RequestLoginCodeRequest) error {
+	var v validator
+	v.email("email", &request.Email)
+	v.token("client_id", request.GetClientId(), maxIDLength, true)
+	if request.GetDelivery() != "" {
+		v.oneOf("delivery", request.GetDelivery(), "code", "link")
+	}
+	return v.err()
+}
+
+// CompleteLoginCode validates a passwordless login code.
+func CompleteLoginCode(request *authorizationservicev1.CompleteLoginCodeRequest) error {
+	var v validator
+	v.token("flow_id", request.GetFlowId(), maxIDLength, true)
+	v.token("code", request.GetCode(), maxCodeLength, true)
+	v.token("client_id", request.GetClientId(), maxIDLength, true)
+	return v.err()
+}
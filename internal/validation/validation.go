@@ -0,0 +1,214 @@
+// Package validation checks AuthenticationService requests before they
+// reach the service. Every invalid field is reported at once as a field
+// violation of a single InvalidArgument domain error. Validators also
+// normalize fields in place, so handlers pass the normalized request on.
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/domain"
+)
+
+// Reasons of field violations.
+const (
+	ReasonRequired      = "REQUIRED"
+	ReasonTooShort      = "TOO_SHORT"
+	ReasonTooLong       = "TOO_LONG"
+	ReasonInvalidFormat = "INVALID_FORMAT"
+	ReasonInvalidValue  = "INVALID_VALUE"
+)
+
+// Field limits.
+const (
+	maxEmailLength      = 254
+	maxEmailLocalLength = 64
+	minLoginLength      = 3
+	maxLoginLength      = 32
+	// Passwords are checked by the password policy; this only bounds
+	// the work of hashing.
+	maxPasswordLength = 1024
+	maxTokenLength    = 4096
+	maxIDLength       = 128
+	maxCodeLength     = 128
+	maxNameLength     = 64
+	maxCredentialSize = 64 << 10
+)
+
+// ErrInvalidEmail is returned by NormalizeEmail for malformed addresses.
+var ErrInvalidEmail = errors.New("invalid email address")
+
+// NormalizeEmail trims the address and converts its domain to the
+// lower-case ASCII form, so "User@Пример.РФ" and "User@xn--e1afmkfd.xn--p1ai"
+// are the same account. The local part is kept as typed apart from
+// NFC normalization: mail servers may treat it as case-sensitive.
+func NormalizeEmail(email string) (string, error) {
+	email = norm.NFC.String(strings.TrimSpace(email))
+
+	at := strings.LastIndexByte(email, '@')
+	if at <= 0 || at == len(email)-1 {
+		return "", ErrInvalidEmail
+	}
+	local, host := email[:at], email[at+1:]
+
+	host, err := idna.Lookup.ToASCII(strings.TrimSuffix(host, "."))
+	if err != nil || !strings.Contains(host, ".") {
+		return "", ErrInvalidEmail
+	}
+	email = local + "@" + strings.ToLower(host)
+
+	if len(local) > maxEmailLocalLength || len(email) > maxEmailLength {
+		return "", ErrInvalidEmail
+	}
+
+	// ParseAddress also accepts "Name <addr>"; only a bare address is valid.
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Name != "" || addr.Address != email {
+		return "", ErrInvalidEmail
+	}
+
+	return email, nil
+}
+
+// validator collects field violations of one request.
+type validator struct {
+	fields []domain.FieldViolation
+}
+
+func (v *validator) add(field, reason, description string) {
+	v.fields = append(v.fields, domain.FieldViolation{
+		Field:       field,
+		Reason:      reason,
+		Description: description,
+	})
+}
+
+// required reports an empty value and tells whether the value is set.
+func (v *validator) required(field, value string) bool {
+	if value == "" {
+		v.add(field, ReasonRequired, field+" is required")
+		return false
+	}
+	return true
+}
+
+func (v *validator) maxLength(field, value string, limit int) bool {
+	if len(value) > limit {
+		v.add(field, ReasonTooLong, fmt.Sprintf("%s must be at most %d bytes", field, limit))
+		return false
+	}
+	return true
+}
+
+// token checks an opaque value: tokens, IDs and codes. They never
+// contain spaces or control characters.
+func (v *validator) token(field, value string, limit int, required bool) {
+	if value == "" {
+		if required {
+			v.required(field, value)
+		}
+		return
+	}
+	if !v.maxLength(field, value, limit) {
+		return
+	}
+	if strings.IndexFunc(value, func(r rune) bool { return unicode.IsSpace(r) || unicode.IsControl(r) }) >= 0 {
+		v.add(field, ReasonInvalidFormat, field+" must not contain spaces or control characters")
+	}
+}
+
+// email validates and normalizes an email address in place.
+func (v *validator) email(field string, value *string) {
+	if !v.required(field, strings.TrimSpace(*value)) {
+		return
+	}
+
+	email, err := NormalizeEmail(*value)
+	if err != nil {
+		v.add(field, ReasonInvalidFormat, field+" must be a valid email address")
+		return
+	}
+	*value = email
+}
+
+// login validates and NFC-normalizes a login in place. Logins are made
+// of letters, digits, '.', '_' and '-' and start with a letter or digit.
+func (v *validator) login(field string, value *string) {
+	login := norm.NFC.String(strings.TrimSpace(*value))
+	if !v.required(field, login) {
+		return
+	}
+
+	switch n := utf8.RuneCountInString(login); {
+	case n < minLoginLength:
+		v.add(field, ReasonTooShort, fmt.Sprintf("%s must be at least %d characters", field, minLoginLength))
+		return
+	case n > maxLoginLength:
+		v.add(field, ReasonTooLong, fmt.Sprintf("%s must be at most %d characters", field, maxLoginLength))
+		return
+	}
+
+	for i, r := range login {
+		valid := unicode.IsLetter(r) || unicode.IsDigit(r) || (i > 0 && strings.ContainsRune("._-", r))
+		if !valid {
+			v.add(field, ReasonInvalidFormat,
+				field+" may contain only letters, digits, '.', '_' and '-' and must start with a letter or digit")
+			return
+		}
+	}
+	*value = login
+}
+
+// password checks presence and size only; the password is kept as typed
+// because normalizing it would break existing hashes.
+func (v *validator) password(field, value string) {
+	if v.required(field, value) {
+		v.maxLength(field, value, maxPasswordLength)
+	}
+}
+
+func (v *validator) oneOf(field, value string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	v.add(field, ReasonInvalidValue, fmt.Sprintf("%s must be one of %s", field, strings.Join(allowed, ", ")))
+}
+
+// userID checks an optional decimal user ID.
+func (v *validator) userID(field, value string) {
+	if value == "" {
+		return
+	}
+	if id, err := strconv.ParseInt(value, 10, 64); err != nil || id <= 0 {
+		v.add(field, ReasonInvalidFormat, field+" must be a positive integer")
+	}
+}
+
+// err returns the collected violations as a domain error, or nil.
+func (v *validator) err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+
+	descriptions := make([]string, 0, len(v.fields))
+	for _, f := range v.fields {
+		descriptions = append(descriptions, f.Description)
+	}
+
+	return &domain.Error{
+		Code:    domain.CodeInvalidArgument,
+		Message: strings.Join(descriptions, "; "),
+		Fields:  v.fields,
+	}
+}
@@ -0,0 +1,144 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	authorizationservicev1 "github.com/GrishanyaaShustov/CloudStorage-Protos-Service/gen/go/authorization-service"
+
+	"github.com/GrishanyaaShustov/CloudStorage-Authorization-Service/internal/domain"
+)
+
+func TestNormalizeEmail(t *testing.T) {
+	tests := []struct {
+		name    string
+		email   string
+		want    string
+		wantErr bool
+	}{
+		{name: "already normalized", email: "user@example.com", want: "user@example.com"},
+		{name: "domain is lower-cased", email: "User@Example.COM", want: "User@example.com"},
+		{name: "spaces are trimmed", email: "  user@example.com\t", want: "user@example.com"},
+		{name: "trailing dot in domain", email: "user@example.com.", want: "user@example.com"},
+		{name: "idn domain", email: "user@Пример.РФ", want: "user@xn--e1afmkfd.xn--p1ai"},
+		{name: "punycode domain", email: "user@XN--E1AFMKFD.xn--p1ai", want: "user@xn--e1afmkfd.xn--p1ai"},
+		{name: "no at sign", email: "user.example.com", wantErr: true},
+		{name: "empty local part", email: "@example.com", wantErr: true},
+		{name: "empty domain", email: "user@", wantErr: true},
+		{name: "domain without dot", email: "user@localhost", wantErr: true},
+		{name: "display name", email: "User <user@example.com>", wantErr: true},
+		{name: "local part too long", email: strings.Repeat("a", maxEmailLocalLength+1) + "@example.com", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeEmail(tt.email)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidEmail) {
+					t.Fatalf("NormalizeEmail(%q) error = %v, want ErrInvalidEmail", tt.email, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizeEmail(%q) error = %v", tt.email, err)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeEmail(%q) = %q, want %q", tt.email, got, tt.want)
+			}
+		})
+	}
+}
+
+// violations returns the field violations of a validation error as
+// "field:reason" pairs.
+func violations(t *testing.T, err error) []string {
+	t.Helper()
+
+	if err == nil {
+		return nil
+	}
+	var derr *domain.Error
+	if !errors.As(err, &derr) || derr.Code != domain.CodeInvalidArgument {
+		t.Fatalf("error = %v, want an InvalidArgument domain error", err)
+	}
+
+	out := make([]string, 0, len(derr.Fields))
+	for _, f := range derr.Fields {
+		out = append(out, f.Field+":"+f.Reason)
+	}
+	return out
+}
+
+func TestLogin(t *testing.T) {
+	tests := []struct {
+		name      string
+		request   *authorizationservicev1.LoginRequest
+		want      []string
+		wantEmail string
+	}{
+		{
+			name:      "valid request is normalized",
+			request:   &authorizationservicev1.LoginRequest{Email: " User@Example.com ", Password: "secret", ClientId: "web"},
+			wantEmail: "User@example.com",
+		},
+		{
+			name:    "client id is required",
+			request: &authorizationservicev1.LoginRequest{Email: "user@example.com", Password: "secret"},
+			want:    []string{"client_id:" + ReasonRequired},
+		},
+		{
+			name:    "all fields are reported",
+			request: &authorizationservicev1.LoginRequest{Email: "not-an-email", ClientId: "a b"},
+			want: []string{
+				"email:" + ReasonInvalidFormat,
+				"password:" + ReasonRequired,
+				"client_id:" + ReasonInvalidFormat,
+			},
+		},
+		{
+			name: "password too long",
+			request: &authorizationservicev1.LoginRequest{
+				Email: "user@example.com", Password: strings.Repeat("p", maxPasswordLength+1), ClientId: "web",
+			},
+			want: []string{"password:" + ReasonTooLong},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := violations(t, Login(tt.request))
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("Login() violations = %v, want %v", got, tt.want)
+			}
+			if tt.wantEmail != "" && tt.request.GetEmail() != tt.wantEmail {
+				t.Errorf("Login() email = %q, want %q", tt.request.GetEmail(), tt.wantEmail)
+			}
+		})
+	}
+}
+
+func TestRegisterLogin(t *testing.T) {
+	tests := []struct {
+		name  string
+		login string
+		want  []string
+	}{
+		{name: "valid", login: "user_1"},
+		{name: "unicode letters", login: "пользователь"},
+		{name: "too short", login: "ab", want: []string{"login:" + ReasonTooShort}},
+		{name: "too long", login: strings.Repeat("a", maxLoginLength+1), want: []string{"login:" + ReasonTooLong}},
+		{name: "starts with a dot", login: ".user", want: []string{"login:" + ReasonInvalidFormat}},
+		{name: "contains a space", login: "us er", want: []string{"login:" + ReasonInvalidFormat}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := &authorizationservicev1.RegisterRequest{Login: tt.login, Email: "user@example.com", Password: "secret"}
+			got := violations(t, Register(request))
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Register() violations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
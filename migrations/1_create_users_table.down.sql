@@ -1,4 +0,0 @@
--- +goose Down
--- +goose StatementBegin
-DROP TABLE IF EXISTS users;
--- +goose StatementEnd
\ No newline at end of file
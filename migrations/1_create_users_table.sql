@@ -14,6 +14,10 @@ CREATE TABLE IF NOT EXISTS users
 
     created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
     updated_at      TIMESTAMPTZ NOT NULL DEFAULT now()
-    )
+);
+-- +goose StatementEnd
 
--- +goose StatementEnd
\ No newline at end of file
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS users;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS signing_keys
+(
+    kid             TEXT PRIMARY KEY,
+    algorithm       TEXT NOT NULL,
+    private_key     TEXT NOT NULL,       -- PKCS#8 PEM
+
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    activates_at    TIMESTAMPTZ NOT NULL, -- ключ публикуется заранее, подписывает с этого момента
+    retired_at      TIMESTAMPTZ           -- NULL, пока ключ подписывает токены
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS signing_keys;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS mfa_totp
+(
+    user_id         BIGINT PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    secret          BYTEA NOT NULL,       -- AES-256-GCM, nonce || ciphertext
+    confirmed_at    TIMESTAMPTZ,          -- NULL, пока пользователь не подтвердил первый код
+    last_used_step  BIGINT NOT NULL DEFAULT 0, -- защита от повторного использования кода
+
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes
+(
+    id              BIGSERIAL PRIMARY KEY,
+    user_id         BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    code_hash       TEXT NOT NULL,        -- sha256, сами коды не храним
+    used_at         TIMESTAMPTZ,
+
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    UNIQUE (user_id, code_hash)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS mfa_recovery_codes;
+DROP TABLE IF EXISTS mfa_totp;
+-- +goose StatementEnd
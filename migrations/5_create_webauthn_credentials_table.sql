@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS webauthn_credentials
+(
+    id                  BIGSERIAL PRIMARY KEY,
+    user_id             BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    credential_id       BYTEA NOT NULL UNIQUE,
+    public_key          BYTEA NOT NULL,      -- COSE key
+    attestation_type    TEXT NOT NULL DEFAULT '',
+    aaguid              BYTEA,
+    sign_count          BIGINT NOT NULL DEFAULT 0,
+    transports          TEXT[] NOT NULL DEFAULT '{}',
+    backup_eligible     BOOLEAN NOT NULL DEFAULT FALSE,
+    backup_state        BOOLEAN NOT NULL DEFAULT FALSE,
+    name                TEXT NOT NULL DEFAULT '', -- подпись, которую видит пользователь
+
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_used_at        TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS webauthn_credentials_user_id_idx ON webauthn_credentials (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS webauthn_credentials;
+-- +goose StatementEnd
@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ; -- мягкое удаление: строка остаётся, пользователь считается удалённым
+
+-- email и аккаунты провайдеров уникальны только среди неудалённых пользователей
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_github_id_key;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_google_id_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_key ON users (email) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS users_github_id_key ON users (github_id) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS users_google_id_key ON users (google_id) WHERE deleted_at IS NULL;
+
+-- поиск по логину и фильтр по дате регистрации в списке пользователей
+CREATE INDEX IF NOT EXISTS users_login_idx ON users (login) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS users_created_at_idx ON users (created_at) WHERE deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- откат не удастся, если email удалённого пользователя занят заново
+DROP INDEX IF EXISTS users_created_at_idx;
+DROP INDEX IF EXISTS users_login_idx;
+
+DROP INDEX IF EXISTS users_email_key;
+DROP INDEX IF EXISTS users_github_id_key;
+DROP INDEX IF EXISTS users_google_id_key;
+
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
+ALTER TABLE users ADD CONSTRAINT users_github_id_key UNIQUE (github_id);
+ALTER TABLE users ADD CONSTRAINT users_google_id_key UNIQUE (google_id);
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS deleted_at;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS outbox
+(
+    id              BIGSERIAL PRIMARY KEY,
+    user_id         BIGINT NOT NULL,      -- события одного пользователя публикуются по порядку id
+    event_type      TEXT NOT NULL,
+    schema_version  INT NOT NULL,
+    payload         JSONB NOT NULL,
+
+    attempts        INT NOT NULL DEFAULT 0,
+    last_error      TEXT,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(), -- отложенный повтор после ошибки публикации
+
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    published_at    TIMESTAMPTZ           -- NULL, пока событие не опубликовано
+);
+
+-- выборка неопубликованных событий и проверка более старых событий пользователя
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox (user_id, id) WHERE published_at IS NULL;
+-- очистка опубликованных событий
+CREATE INDEX IF NOT EXISTS outbox_published_at_idx ON outbox (published_at) WHERE published_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS outbox;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- приватные ключи хранятся зашифрованными (secretbox, AES-256-GCM);
+-- ключи, сохранённые открытым текстом, шифруются сервисом при первой загрузке
+ALTER TABLE signing_keys
+    ADD COLUMN IF NOT EXISTS private_key_sealed BYTEA,
+    ALTER COLUMN private_key DROP NOT NULL;
+
+ALTER TABLE signing_keys
+    ADD CONSTRAINT signing_keys_private_key_check
+        CHECK (private_key IS NOT NULL OR private_key_sealed IS NOT NULL);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- зашифрованные ключи нельзя расшифровать в SQL: они удаляются,
+-- и сервис сгенерирует новый ключ при старте
+DELETE FROM signing_keys WHERE private_key IS NULL;
+
+ALTER TABLE signing_keys
+    DROP CONSTRAINT IF EXISTS signing_keys_private_key_check,
+    DROP COLUMN IF EXISTS private_key_sealed,
+    ALTER COLUMN private_key SET NOT NULL;
+-- +goose StatementEnd
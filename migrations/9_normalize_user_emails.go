@@ -0,0 +1,142 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/mail"
+	"strings"
+
+	"github.com/pressly/goose/v3"
+	"golang.org/x/net/idna"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Go holds the migrations written in Go, which are applied along with
+// the SQL migrations of FS.
+var Go = []*goose.Migration{
+	goose.NewGoMigration(9,
+		&goose.GoFunc{RunTx: normalizeUserEmailsUp},
+		// The emails as they were typed are lost, there is nothing to restore.
+		nil,
+	),
+}
+
+// emailRow is an active user's stored email.
+type emailRow struct {
+	id    int64
+	email string
+}
+
+// normalizeUserEmailsUp rewrites emails of active users to the form
+// the service has looked users up by since it started to normalize
+// request emails. Rows whose normalized
+// email would collide with another active user are left as they are
+// and logged, they need to be merged by hand.
+func normalizeUserEmailsUp(ctx context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx, `SELECT id, email FROM users WHERE deleted_at IS NULL`)
+	if err != nil {
+		return err
+	}
+
+	var users []emailRow
+	for rows.Next() {
+		var u emailRow
+		if err := rows.Scan(&u.id, &u.email); err != nil {
+			rows.Close()
+			return err
+		}
+		users = append(users, u)
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	updates, conflicts := normalizeEmails(users)
+
+	for _, u := range updates {
+		if _, err := tx.ExecContext(ctx, `UPDATE users SET email = $2 WHERE id = $1`, u.id, u.email); err != nil {
+			return err
+		}
+	}
+	for _, u := range conflicts {
+		slog.Default().Warn("email not normalized: another user has the same normalized email",
+			slog.Int64("user_id", u.id),
+		)
+	}
+
+	return nil
+}
+
+// normalizeEmails returns the rows to update with their normalized
+// email, and the rows left as they are because the normalized email
+// is shared with another row. Malformed emails are left as they are.
+func normalizeEmails(users []emailRow) (updates, conflicts []emailRow) {
+	owners := make(map[string]int, len(users))
+	normalized := make([]string, len(users))
+
+	for i, u := range users {
+		email, err := normalizeEmail(u.email)
+		if err != nil {
+			email = u.email
+		}
+		normalized[i] = email
+		owners[email]++
+	}
+
+	for i, u := range users {
+		if normalized[i] == u.email {
+			continue
+		}
+		if owners[normalized[i]] > 1 {
+			conflicts = append(conflicts, u)
+			continue
+		}
+		updates = append(updates, emailRow{id: u.id, email: normalized[i]})
+	}
+
+	return updates, conflicts
+}
+
+// Email limits of RFC 5321.
+const (
+	maxEmailLength      = 254
+	maxEmailLocalLength = 64
+)
+
+var errInvalidEmail = errors.New("invalid email address")
+
+// normalizeEmail is validation.NormalizeEmail as it was when this
+// migration was written. It is copied, so later changes of the
+// service do not change what the migration does: the address is
+// trimmed and NFC-normalized, the domain converted to lower-case ASCII.
+func normalizeEmail(email string) (string, error) {
+	email = norm.NFC.String(strings.TrimSpace(email))
+
+	at := strings.LastIndexByte(email, '@')
+	if at <= 0 || at == len(email)-1 {
+		return "", errInvalidEmail
+	}
+	local, host := email[:at], email[at+1:]
+
+	host, err := idna.Lookup.ToASCII(strings.TrimSuffix(host, "."))
+	if err != nil || !strings.Contains(host, ".") {
+		return "", errInvalidEmail
+	}
+	email = local + "@" + strings.ToLower(host)
+
+	if len(local) > maxEmailLocalLength || len(email) > maxEmailLength {
+		return "", errInvalidEmail
+	}
+
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Name != "" || addr.Address != email {
+		return "", errInvalidEmail
+	}
+
+	return email, nil
+}
@@ -0,0 +1,57 @@
+package migrations
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeEmails(t *testing.T) {
+	tests := []struct {
+		name          string
+		users         []emailRow
+		wantUpdates   []emailRow
+		wantConflicts []emailRow
+	}{
+		{
+			name:  "already normalized",
+			users: []emailRow{{1, "user@example.com"}},
+		},
+		{
+			name:        "domain case",
+			users:       []emailRow{{1, "User@EXAMPLE.com"}},
+			wantUpdates: []emailRow{{1, "User@example.com"}},
+		},
+		{
+			name:        "idn domain",
+			users:       []emailRow{{1, "user@пример.рф"}},
+			wantUpdates: []emailRow{{1, "user@xn--e1afmkfd.xn--p1ai"}},
+		},
+		{
+			name:          "conflict with a normalized row",
+			users:         []emailRow{{1, "user@example.com"}, {2, "user@Example.com"}},
+			wantConflicts: []emailRow{{2, "user@Example.com"}},
+		},
+		{
+			name:          "conflict between two rows",
+			users:         []emailRow{{1, "user@Example.com"}, {2, "user@EXAMPLE.com"}, {3, "other@Example.com"}},
+			wantUpdates:   []emailRow{{3, "other@example.com"}},
+			wantConflicts: []emailRow{{1, "user@Example.com"}, {2, "user@EXAMPLE.com"}},
+		},
+		{
+			name:  "malformed email is left alone",
+			users: []emailRow{{1, "not an email"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updates, conflicts := normalizeEmails(tt.users)
+			if !reflect.DeepEqual(updates, tt.wantUpdates) {
+				t.Errorf("updates = %v, want %v", updates, tt.wantUpdates)
+			}
+			if !reflect.DeepEqual(conflicts, tt.wantConflicts) {
+				t.Errorf("conflicts = %v, want %v", conflicts, tt.wantConflicts)
+			}
+		})
+	}
+}
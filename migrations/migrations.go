@@ -0,0 +1,11 @@
+// Package migrations embeds the goose SQL migrations of the service,
+// so the binary can migrate its database without the source tree.
+// Data migrations which need Go code are listed in Go.
+package migrations
+
+import "embed"
+
+// FS holds the SQL migrations, one file per version with Up and Down sections.
+//
+//go:embed *.sql
+var FS embed.FS
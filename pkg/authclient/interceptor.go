@@ -0,0 +1,97 @@
+package authclient
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor authenticates unary calls and stores the
+// Principal in the handler context. Methods listed in public
+// (full names like "/pkg.Service/Method") are served without a token.
+func (v *Verifier) UnaryServerInterceptor(public ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if slices.Contains(public, info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		ctx, err := v.authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor authenticates streaming calls and stores the
+// Principal in the stream context. See UnaryServerInterceptor for public.
+func (v *Verifier) StreamServerInterceptor(public ...string) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if slices.Contains(public, info.FullMethod) {
+			return handler(srv, ss)
+		}
+
+		ctx, err := v.authenticate(ss.Context())
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticate verifies the bearer token from incoming metadata.
+func (v *Verifier) authenticate(ctx context.Context) (context.Context, error) {
+	token, ok := bearerToken(ctx)
+	if !ok {
+		return ctx, status.Error(codes.Unauthenticated, "access token is required")
+	}
+
+	principal, err := v.Verify(ctx, token)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrRevokedToken):
+			return ctx, status.Error(codes.Unauthenticated, "access token revoked")
+		case errors.Is(err, ErrInvalidToken):
+			return ctx, status.Error(codes.Unauthenticated, "invalid access token")
+		default:
+			return ctx, status.Error(codes.Unavailable, "failed to verify access token")
+		}
+	}
+
+	return NewContext(ctx, principal), nil
+}
+
+// bearerToken extracts the token from the authorization metadata.
+func bearerToken(ctx context.Context) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+
+	for _, value := range md.Get("authorization") {
+		scheme, token, found := strings.Cut(value, " ")
+		if found && strings.EqualFold(scheme, "bearer") && token != "" {
+			return token, true
+		}
+	}
+
+	return "", false
+}
+
+// serverStream overrides the context of a wrapped stream.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,113 @@
+package authclient
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	testMethod   = "/cloudstorage.files.v1.FileService/GetFile"
+	publicMethod = "/cloudstorage.files.v1.FileService/Health"
+)
+
+// fakeStream is a server stream carrying only a context.
+type fakeStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeStream) Context() context.Context {
+	return s.ctx
+}
+
+func withAuthorization(values ...string) context.Context {
+	md := metadata.MD{}
+	for _, v := range values {
+		md.Append("authorization", v)
+	}
+	return metadata.NewIncomingContext(context.Background(), md)
+}
+
+func TestInterceptors(t *testing.T) {
+	key := newEd25519Key(t, "ed-1")
+	unknownKey := newEd25519Key(t, "unknown")
+	srv := newJWKSServer(t, key.jwk)
+	v := newTestVerifier(t, srv, nil)
+	token := sign(t, key, validClaims())
+
+	failing := newJWKSServer(t)
+	failing.setFail(true)
+	unavailable := NewVerifier(Config{Issuer: testIssuer, Audience: testAudience, Keys: failing.keySet()})
+
+	tests := []struct {
+		name     string
+		verifier *Verifier
+		ctx      context.Context
+		method   string
+		wantCode codes.Code
+		wantUser int64
+	}{
+		{name: "valid token", verifier: v, ctx: withAuthorization("Bearer " + token), method: testMethod, wantUser: 42},
+		{name: "scheme is case-insensitive", verifier: v, ctx: withAuthorization("bearer " + token), method: testMethod, wantUser: 42},
+		{name: "no metadata", verifier: v, ctx: context.Background(), method: testMethod, wantCode: codes.Unauthenticated},
+		{name: "basic scheme", verifier: v, ctx: withAuthorization("Basic dXNlcjpwYXNz"), method: testMethod, wantCode: codes.Unauthenticated},
+		{name: "invalid token", verifier: v, ctx: withAuthorization("Bearer garbage"), method: testMethod, wantCode: codes.Unauthenticated},
+		{name: "public method", verifier: v, ctx: context.Background(), method: publicMethod},
+		{
+			name:     "keys unavailable",
+			verifier: unavailable,
+			ctx:      withAuthorization("Bearer " + sign(t, unknownKey, validClaims())),
+			method:   testMethod,
+			wantCode: codes.Unavailable,
+		},
+	}
+
+	// check asserts the handler outcome of both interceptors
+	check := func(t *testing.T, err error, principal *Principal, wantCode codes.Code, wantUser int64) {
+		t.Helper()
+
+		if got := status.Code(err); got != wantCode {
+			t.Fatalf("status code = %v, want %v (err = %v)", got, wantCode, err)
+		}
+		if wantUser == 0 {
+			if principal != nil {
+				t.Errorf("handler got principal %+v, want none", principal)
+			}
+			return
+		}
+		if principal == nil || principal.UserID != wantUser {
+			t.Errorf("handler got principal %+v, want user %d", principal, wantUser)
+		}
+	}
+
+	for _, tt := range tests {
+		t.Run("unary/"+tt.name, func(t *testing.T) {
+			var principal *Principal
+			handler := func(ctx context.Context, _ any) (any, error) {
+				principal, _ = FromContext(ctx)
+				return nil, nil
+			}
+
+			interceptor := tt.verifier.UnaryServerInterceptor(publicMethod)
+			_, err := interceptor(tt.ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			check(t, err, principal, tt.wantCode, tt.wantUser)
+		})
+
+		t.Run("stream/"+tt.name, func(t *testing.T) {
+			var principal *Principal
+			handler := func(_ any, ss grpc.ServerStream) error {
+				principal, _ = FromContext(ss.Context())
+				return nil
+			}
+
+			interceptor := tt.verifier.StreamServerInterceptor(publicMethod)
+			err := interceptor(nil, &fakeStream{ctx: tt.ctx}, &grpc.StreamServerInfo{FullMethod: tt.method}, handler)
+			check(t, err, principal, tt.wantCode, tt.wantUser)
+		})
+	}
+}
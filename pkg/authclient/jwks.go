@@ -0,0 +1,289 @@
+package authclient
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	authorizationservicev1 "github.com/GrishanyaaShustov/CloudStorage-Protos-Service/gen/go/authorization-service"
+)
+
+var (
+	// ErrKeyNotFound is returned when the key set has no key with the requested ID.
+	ErrKeyNotFound = errors.New("authclient: signing key not found")
+
+	// ErrKeysUnavailable is returned when the key set could not be
+	// reloaded to look up an unknown key. The token may still be valid.
+	ErrKeysUnavailable = errors.New("authclient: signing keys unavailable")
+)
+
+// JWK is a public key in JSON Web Key format (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+// FetchFunc loads the current key set.
+type FetchFunc func(ctx context.Context) ([]JWK, error)
+
+// HTTPFetcher loads the key set from a /.well-known/jwks.json URL.
+// A nil client means http.DefaultClient.
+func HTTPFetcher(client *http.Client, url string) FetchFunc {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return func(ctx context.Context) ([]JWK, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("authclient: fetch JWKS: unexpected status %s", resp.Status)
+		}
+
+		var set struct {
+			Keys []JWK `json:"keys"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+			return nil, fmt.Errorf("authclient: decode JWKS: %w", err)
+		}
+
+		return set.Keys, nil
+	}
+}
+
+// GRPCFetcher loads the key set with the GetJWKS RPC.
+func GRPCFetcher(client authorizationservicev1.AuthenticationServiceClient) FetchFunc {
+	return func(ctx context.Context) ([]JWK, error) {
+		resp, err := client.GetJWKS(ctx, &authorizationservicev1.GetJWKSRequest{})
+		if err != nil {
+			return nil, err
+		}
+
+		keys := make([]JWK, 0, len(resp.GetKeys()))
+		for _, k := range resp.GetKeys() {
+			keys = append(keys, JWK{
+				Kty: k.GetKty(),
+				Kid: k.GetKid(),
+				Use: k.GetUse(),
+				Alg: k.GetAlg(),
+				Crv: k.GetCrv(),
+				X:   k.GetX(),
+				N:   k.GetN(),
+				E:   k.GetE(),
+			})
+		}
+
+		return keys, nil
+	}
+}
+
+// publicKey is a parsed verification key.
+type publicKey struct {
+	alg string
+	key crypto.PublicKey
+}
+
+// KeySet caches the published key set. Keys are refreshed in the
+// background by Run and on demand when a token carries an unknown kid,
+// at most once per MinRefreshInterval.
+type KeySet struct {
+	log   *slog.Logger
+	fetch FetchFunc
+
+	refreshInterval    time.Duration
+	minRefreshInterval time.Duration
+
+	mu          sync.RWMutex
+	keys        map[string]publicKey
+	lastRefresh time.Time
+
+	// refreshMu serializes fetches.
+	refreshMu sync.Mutex
+}
+
+// KeySetConfig configures a KeySet.
+type KeySetConfig struct {
+	// Fetch loads the key set, see HTTPFetcher and GRPCFetcher.
+	Fetch FetchFunc
+	// RefreshInterval is how often Run reloads keys. Defaults to 1 minute.
+	RefreshInterval time.Duration
+	// MinRefreshInterval limits on-demand reloads. Defaults to 10 seconds.
+	MinRefreshInterval time.Duration
+	// Log receives refresh failures. Defaults to slog.Default().
+	Log *slog.Logger
+}
+
+// NewKeySet creates an empty key set. Call Refresh to load keys
+// before serving and Run to keep them up to date.
+func NewKeySet(cfg KeySetConfig) *KeySet {
+	ks := &KeySet{
+		log:                cfg.Log,
+		fetch:              cfg.Fetch,
+		refreshInterval:    cfg.RefreshInterval,
+		minRefreshInterval: cfg.MinRefreshInterval,
+		keys:               make(map[string]publicKey),
+	}
+	if ks.log == nil {
+		ks.log = slog.Default()
+	}
+	if ks.refreshInterval <= 0 {
+		ks.refreshInterval = time.Minute
+	}
+	if ks.minRefreshInterval <= 0 {
+		ks.minRefreshInterval = 10 * time.Second
+	}
+	return ks
+}
+
+// Run reloads keys every RefreshInterval until ctx is canceled.
+func (ks *KeySet) Run(ctx context.Context) {
+	ticker := time.NewTicker(ks.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := ks.Refresh(ctx); err != nil {
+				ks.log.Error("authclient: failed to refresh JWKS", slog.Any("err", err))
+			}
+		}
+	}
+}
+
+// Refresh reloads keys. On failure previously loaded keys are kept.
+func (ks *KeySet) Refresh(ctx context.Context) error {
+	ks.refreshMu.Lock()
+	defer ks.refreshMu.Unlock()
+
+	return ks.refresh(ctx)
+}
+
+func (ks *KeySet) refresh(ctx context.Context) error {
+	jwks, err := ks.fetch(ctx)
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]publicKey, len(jwks))
+	for _, jwk := range jwks {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		key, err := parseJWK(jwk)
+		if err != nil {
+			ks.log.Warn("authclient: skipping unsupported JWK",
+				slog.String("kid", jwk.Kid),
+				slog.Any("err", err),
+			)
+			continue
+		}
+		keys[jwk.Kid] = publicKey{alg: jwk.Alg, key: key}
+	}
+
+	ks.mu.Lock()
+	ks.keys = keys
+	ks.lastRefresh = time.Now()
+	ks.mu.Unlock()
+
+	return nil
+}
+
+// key returns a key by its ID, reloading the set once if it is unknown.
+func (ks *KeySet) key(ctx context.Context, kid string) (publicKey, error) {
+	if key, ok := ks.lookup(kid); ok {
+		return key, nil
+	}
+
+	ks.refreshMu.Lock()
+	defer ks.refreshMu.Unlock()
+
+	// Another request may have reloaded keys while we waited.
+	if key, ok := ks.lookup(kid); ok {
+		return key, nil
+	}
+
+	ks.mu.RLock()
+	recent := time.Since(ks.lastRefresh) < ks.minRefreshInterval
+	ks.mu.RUnlock()
+	if recent {
+		return publicKey{}, ErrKeyNotFound
+	}
+
+	if err := ks.refresh(ctx); err != nil {
+		return publicKey{}, fmt.Errorf("%w: %w", ErrKeysUnavailable, err)
+	}
+
+	if key, ok := ks.lookup(kid); ok {
+		return key, nil
+	}
+	return publicKey{}, ErrKeyNotFound
+}
+
+func (ks *KeySet) lookup(kid string) (publicKey, bool) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	key, ok := ks.keys[kid]
+	return key, ok
+}
+
+func parseJWK(jwk JWK) (crypto.PublicKey, error) {
+	switch jwk.Kty {
+	case "OKP":
+		if jwk.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key size")
+		}
+		return ed25519.PublicKey(x), nil
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		exp := new(big.Int).SetBytes(e)
+		if !exp.IsInt64() || exp.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", jwk.Kty)
+	}
+}
@@ -0,0 +1,201 @@
+package authclient
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testKey is a signing key with its published JWK.
+type testKey struct {
+	private crypto.Signer
+	jwk     JWK
+}
+
+func newEd25519Key(t *testing.T, kid string) testKey {
+	t.Helper()
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testKey{
+		private: priv,
+		jwk: JWK{
+			Kty: "OKP", Kid: kid, Use: "sig", Alg: "EdDSA", Crv: "Ed25519",
+			X: base64.RawURLEncoding.EncodeToString(pub),
+		},
+	}
+}
+
+func newRSAKey(t *testing.T, kid string) testKey {
+	t.Helper()
+
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testKey{
+		private: priv,
+		jwk: JWK{
+			Kty: "RSA", Kid: kid, Use: "sig", Alg: "RS256",
+			N: base64.RawURLEncoding.EncodeToString(priv.N.Bytes()),
+			E: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(priv.E)).Bytes()),
+		},
+	}
+}
+
+// jwksServer publishes a key set over HTTP and counts fetches.
+type jwksServer struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	keys    []JWK
+	fetches int
+	fail    bool
+}
+
+func newJWKSServer(t *testing.T, keys ...JWK) *jwksServer {
+	t.Helper()
+
+	s := &jwksServer{keys: keys}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.fetches++
+		if s.fail {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string][]JWK{"keys": s.keys})
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *jwksServer) setKeys(keys ...JWK) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = keys
+}
+
+func (s *jwksServer) setFail(fail bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fail = fail
+}
+
+func (s *jwksServer) fetchCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fetches
+}
+
+func (s *jwksServer) keySet() *KeySet {
+	return NewKeySet(KeySetConfig{
+		Fetch: HTTPFetcher(s.Client(), s.URL),
+		Log:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+}
+
+func TestKeySetRefresh(t *testing.T) {
+	edKey := newEd25519Key(t, "ed-1")
+	rsaKey := newRSAKey(t, "rsa-1")
+	encKey := newRSAKey(t, "enc-1")
+	encKey.jwk.Use = "enc"
+	srv := newJWKSServer(t, edKey.jwk, rsaKey.jwk, encKey.jwk, JWK{Kty: "EC", Kid: "ec-1"})
+
+	ks := srv.keySet()
+	if err := ks.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	tests := []struct {
+		kid    string
+		wantOK bool
+	}{
+		{kid: "ed-1", wantOK: true},
+		{kid: "rsa-1", wantOK: true},
+		{kid: "enc-1"},
+		{kid: "ec-1"},
+	}
+	for _, tt := range tests {
+		if _, ok := ks.lookup(tt.kid); ok != tt.wantOK {
+			t.Errorf("lookup(%q) = %v, want %v", tt.kid, ok, tt.wantOK)
+		}
+	}
+
+	// Failed reloads keep the loaded keys
+	srv.setFail(true)
+	if err := ks.Refresh(context.Background()); err == nil {
+		t.Fatal("Refresh() error = nil on a failing endpoint")
+	}
+	if _, ok := ks.lookup("ed-1"); !ok {
+		t.Error("Refresh() failure dropped loaded keys")
+	}
+}
+
+func TestKeySetUnknownKid(t *testing.T) {
+	oldKey := newEd25519Key(t, "old")
+	newKey := newEd25519Key(t, "new")
+	srv := newJWKSServer(t, oldKey.jwk)
+
+	ks := srv.keySet()
+	if err := ks.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	srv.setKeys(oldKey.jwk, newKey.jwk)
+
+	// Keys were just loaded, so an unknown kid does not trigger a reload
+	if _, err := ks.key(context.Background(), "new"); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("key() within MinRefreshInterval error = %v, want ErrKeyNotFound", err)
+	}
+	if got := srv.fetchCount(); got != 1 {
+		t.Fatalf("fetches within MinRefreshInterval = %d, want 1", got)
+	}
+
+	// Once the interval has passed the set is reloaded on demand
+	ks.mu.Lock()
+	ks.lastRefresh = time.Now().Add(-ks.minRefreshInterval)
+	ks.mu.Unlock()
+
+	if _, err := ks.key(context.Background(), "new"); err != nil {
+		t.Fatalf("key() after rotation error = %v", err)
+	}
+	if got := srv.fetchCount(); got != 2 {
+		t.Fatalf("fetches after rotation = %d, want 2", got)
+	}
+
+	// Known keys are served from the cache
+	if _, err := ks.key(context.Background(), "old"); err != nil {
+		t.Fatalf("key() for a cached kid error = %v", err)
+	}
+	if got := srv.fetchCount(); got != 2 {
+		t.Errorf("fetches for a cached kid = %d, want 2", got)
+	}
+}
+
+func TestKeySetUnavailable(t *testing.T) {
+	srv := newJWKSServer(t)
+	srv.setFail(true)
+
+	_, err := srv.keySet().key(context.Background(), "any")
+	if !errors.Is(err, ErrKeysUnavailable) {
+		t.Errorf("key() on a failing endpoint error = %v, want ErrKeysUnavailable", err)
+	}
+}
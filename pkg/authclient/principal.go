@@ -0,0 +1,40 @@
+// Package authclient verifies access tokens issued by the
+// authorization service. It is meant to be imported by other
+// CloudStorage services: gRPC interceptors check the bearer token
+// against the published key set and put the caller's Principal
+// into the request context.
+package authclient
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// Principal is an authenticated caller.
+type Principal struct {
+	UserID    int64
+	ClientID  string
+	SessionID string
+	TokenID   string
+	Scopes    []string
+	ExpiresAt time.Time
+}
+
+// HasScope reports whether the scope has been granted to the caller.
+func (p *Principal) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, scope)
+}
+
+type principalKey struct{}
+
+// NewContext returns a copy of ctx carrying the principal.
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal stored in ctx by the interceptors.
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
@@ -0,0 +1,143 @@
+package authclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	authorizationservicev1 "github.com/GrishanyaaShustov/CloudStorage-Protos-Service/gen/go/authorization-service"
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc/metadata"
+)
+
+var (
+	// ErrInvalidToken is returned when a token fails signature or claims validation.
+	ErrInvalidToken = errors.New("authclient: invalid token")
+
+	// ErrRevokedToken is returned when introspection reports a valid token as inactive.
+	ErrRevokedToken = errors.New("authclient: token revoked")
+)
+
+// IntrospectFunc asks the authorization service whether a token is active.
+type IntrospectFunc func(ctx context.Context, token string) (bool, error)
+
+// CredentialFunc returns the access token the calling service
+// authorizes itself with. It must carry the tokens:introspect scope.
+type CredentialFunc func(ctx context.Context) (string, error)
+
+// GRPCIntrospector checks tokens with the IntrospectToken RPC,
+// authorized with the token returned by credential.
+func GRPCIntrospector(client authorizationservicev1.AuthenticationServiceClient, credential CredentialFunc) IntrospectFunc {
+	return func(ctx context.Context, token string) (bool, error) {
+		serviceToken, err := credential(ctx)
+		if err != nil {
+			return false, fmt.Errorf("authclient: get introspection credential: %w", err)
+		}
+
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+serviceToken)
+		resp, err := client.IntrospectToken(ctx, &authorizationservicev1.IntrospectTokenRequest{
+			Token:         token,
+			TokenTypeHint: "access_token",
+		})
+		if err != nil {
+			return false, err
+		}
+		return resp.GetActive(), nil
+	}
+}
+
+// Config configures a Verifier.
+type Config struct {
+	// Issuer and Audience must match the authorization service token config.
+	Issuer   string
+	Audience string
+	// Keys is the published key set of the authorization service.
+	Keys *KeySet
+	// Introspect, when set, is called for every token with a valid
+	// signature so that revoked sessions are rejected. Without it
+	// a revoked token stays valid until it expires.
+	Introspect IntrospectFunc
+	// Leeway tolerates clock skew when checking time claims.
+	Leeway time.Duration
+}
+
+// Verifier checks access tokens.
+type Verifier struct {
+	cfg    Config
+	parser *jwt.Parser
+}
+
+// NewVerifier creates a token verifier.
+func NewVerifier(cfg Config) *Verifier {
+	return &Verifier{
+		cfg: cfg,
+		parser: jwt.NewParser(
+			jwt.WithValidMethods([]string{"EdDSA", "RS256"}),
+			jwt.WithIssuer(cfg.Issuer),
+			jwt.WithAudience(cfg.Audience),
+			jwt.WithExpirationRequired(),
+			jwt.WithLeeway(cfg.Leeway),
+		),
+	}
+}
+
+// claims mirrors claims of access tokens issued by the authorization service.
+type claims struct {
+	jwt.RegisteredClaims
+	ClientID  string `json:"client_id,omitempty"`
+	SessionID string `json:"sid,omitempty"`
+	Scope     string `json:"scope,omitempty"`
+}
+
+// Verify checks the token and returns the principal it was issued to.
+func (v *Verifier) Verify(ctx context.Context, token string) (*Principal, error) {
+	var c claims
+
+	_, err := v.parser.ParseWithClaims(token, &c, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+
+		key, err := v.cfg.Keys.key(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+		if key.alg != "" && key.alg != t.Method.Alg() {
+			return nil, fmt.Errorf("key %s does not match algorithm %s", kid, t.Method.Alg())
+		}
+
+		return key.key, nil
+	})
+	if err != nil {
+		// A failed key reload says nothing about the token itself
+		if errors.Is(err, ErrKeysUnavailable) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	userID, err := strconv.ParseInt(c.Subject, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed subject", ErrInvalidToken)
+	}
+
+	if v.cfg.Introspect != nil {
+		active, err := v.cfg.Introspect(ctx, token)
+		if err != nil {
+			return nil, fmt.Errorf("authclient: introspect token: %w", err)
+		}
+		if !active {
+			return nil, ErrRevokedToken
+		}
+	}
+
+	return &Principal{
+		UserID:    userID,
+		ClientID:  c.ClientID,
+		SessionID: c.SessionID,
+		TokenID:   c.ID,
+		Scopes:    strings.Fields(c.Scope),
+		ExpiresAt: c.ExpiresAt.Time,
+	}, nil
+}
@@ -0,0 +1,189 @@
+package authclient
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testIssuer   = "https://auth.example.com"
+	testAudience = "cloudstorage"
+)
+
+func validClaims() claims {
+	now := time.Now()
+	return claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    testIssuer,
+			Subject:   "42",
+			Audience:  jwt.ClaimStrings{testAudience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute).Truncate(time.Second)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ID:        "token-1",
+		},
+		ClientID:  "web",
+		SessionID: "session-1",
+		Scope:     "files:read files:write",
+	}
+}
+
+// sign issues a token the way the authorization service does.
+func sign(t *testing.T, key testKey, c claims) string {
+	t.Helper()
+
+	method := jwt.SigningMethod(jwt.SigningMethodEdDSA)
+	if key.jwk.Kty == "RSA" {
+		method = jwt.SigningMethodRS256
+	}
+	token := jwt.NewWithClaims(method, c)
+	token.Header["kid"] = key.jwk.Kid
+
+	signed, err := token.SignedString(key.private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func newTestVerifier(t *testing.T, srv *jwksServer, introspect IntrospectFunc) *Verifier {
+	t.Helper()
+
+	ks := srv.keySet()
+	if err := ks.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	return NewVerifier(Config{
+		Issuer:     testIssuer,
+		Audience:   testAudience,
+		Keys:       ks,
+		Introspect: introspect,
+	})
+}
+
+func TestVerify(t *testing.T) {
+	edKey := newEd25519Key(t, "ed-1")
+	rsaKey := newRSAKey(t, "rsa-1")
+	unknownKey := newEd25519Key(t, "unknown")
+	// Same kid as edKey but another key pair
+	forgedKey := newEd25519Key(t, "ed-1")
+	v := newTestVerifier(t, newJWKSServer(t, edKey.jwk, rsaKey.jwk), nil)
+
+	expired := validClaims()
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+	otherAudience := validClaims()
+	otherAudience.Audience = jwt.ClaimStrings{"other"}
+	otherIssuer := validClaims()
+	otherIssuer.Issuer = "https://evil.example.com"
+	badSubject := validClaims()
+	badSubject.Subject = "user-42"
+
+	hs256 := jwt.NewWithClaims(jwt.SigningMethodHS256, validClaims())
+	hs256.Header["kid"] = "ed-1"
+	hmacToken, err := hs256.SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{name: "Ed25519", token: sign(t, edKey, validClaims())},
+		{name: "RS256", token: sign(t, rsaKey, validClaims())},
+		{name: "expired", token: sign(t, edKey, expired), wantErr: ErrInvalidToken},
+		{name: "other audience", token: sign(t, edKey, otherAudience), wantErr: ErrInvalidToken},
+		{name: "other issuer", token: sign(t, edKey, otherIssuer), wantErr: ErrInvalidToken},
+		{name: "malformed subject", token: sign(t, edKey, badSubject), wantErr: ErrInvalidToken},
+		{name: "unknown kid", token: sign(t, unknownKey, validClaims()), wantErr: ErrInvalidToken},
+		{name: "wrong signature", token: sign(t, forgedKey, validClaims()), wantErr: ErrInvalidToken},
+		{name: "HS256 is rejected", token: hmacToken, wantErr: ErrInvalidToken},
+		{name: "garbage", token: "not.a.token", wantErr: ErrInvalidToken},
+	}
+
+	want := &Principal{
+		UserID:    42,
+		ClientID:  "web",
+		SessionID: "session-1",
+		TokenID:   "token-1",
+		Scopes:    []string{"files:read", "files:write"},
+		ExpiresAt: validClaims().ExpiresAt.Time,
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := v.Verify(context.Background(), tt.token)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Verify() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestVerifyIntrospect(t *testing.T) {
+	key := newEd25519Key(t, "ed-1")
+	srv := newJWKSServer(t, key.jwk)
+	token := sign(t, key, validClaims())
+	errIntrospect := errors.New("introspection unavailable")
+
+	tests := []struct {
+		name       string
+		introspect IntrospectFunc
+		wantErr    error
+	}{
+		{
+			name:       "active",
+			introspect: func(context.Context, string) (bool, error) { return true, nil },
+		},
+		{
+			name:       "revoked",
+			introspect: func(context.Context, string) (bool, error) { return false, nil },
+			wantErr:    ErrRevokedToken,
+		},
+		{
+			name:       "introspection fails",
+			introspect: func(context.Context, string) (bool, error) { return false, errIntrospect },
+			wantErr:    errIntrospect,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newTestVerifier(t, srv, tt.introspect).Verify(context.Background(), token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil && tt.wantErr != ErrRevokedToken && errors.Is(err, ErrInvalidToken) {
+				t.Errorf("Verify() error = %v, must not be ErrInvalidToken", err)
+			}
+		})
+	}
+}
+
+// TestVerifyKeysUnavailable checks that a failed key reload is not
+// reported as an invalid token.
+func TestVerifyKeysUnavailable(t *testing.T) {
+	key := newEd25519Key(t, "ed-1")
+	srv := newJWKSServer(t)
+	srv.setFail(true)
+	v := NewVerifier(Config{Issuer: testIssuer, Audience: testAudience, Keys: srv.keySet()})
+
+	_, err := v.Verify(context.Background(), sign(t, key, validClaims()))
+	if !errors.Is(err, ErrKeysUnavailable) || errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Verify() error = %v, want ErrKeysUnavailable only", err)
+	}
+}